@@ -32,6 +32,9 @@ func main() {
 	}
 
 	fmt.Println("Successfully read GuideLLM HTML data")
+	for _, warning := range dataReader.(reader.Warner).Warnings() {
+		fmt.Println("Warning:", warning)
+	}
 	fmt.Println("===============================" + "==============================")
 	// dataReader.Print()
 	// fmt.Println("===============================" + "==============================")
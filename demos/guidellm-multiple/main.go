@@ -18,43 +18,26 @@ const (
 )
 
 func main() {
-	fileNames := []string{DefaultFileName}
+	// paths of files or directories, or glob patterns, possibly joined by the separator
+	paths := []string{DefaultFileName}
 	if len(os.Args) > 1 {
-		fileNames = strings.Split(os.Args[1], FileNameSeparator)
-	}
-
-	var dataReader reader.Reader
-	dataSet := core.NewDataSet(DefaultDataSetName)
-
-	for _, fileName := range fileNames {
-		fn := fileName
-		fmt.Printf("Processing file: %s\n", fn)
-		dataBytes, err_acc := os.ReadFile(fn)
-		if err_acc != nil {
-			fmt.Println(err_acc)
-			continue
-		}
-
-		dataReader = reader.NewGuideLLMData()
-		if err := dataReader.ReadFrom(dataBytes); err != nil {
-			fmt.Println("GuideLLM data not in json output format, Trying CSV ...")
-			dataReader = reader.NewGuideLLMCSVData()
-			if err := dataReader.ReadFrom(dataBytes); err != nil {
-				fmt.Println("GuideLLM data not in CSV format, Trying HTML ...")
-				dataReader = reader.NewGuideLLMHTMLData()
-				if err := dataReader.ReadFrom(dataBytes); err != nil {
-					fmt.Println(err)
-					continue
-				}
-			}
+		paths = nil
+		for _, arg := range os.Args[1:] {
+			paths = append(paths, strings.Split(arg, FileNameSeparator)...)
 		}
-		dataSet.Merge(dataReader.CreateDataSet())
 	}
 
-	if len(dataSet.Data) == 0 {
+	dataSet, report, err := reader.Ingest(paths, nil)
+	if report != nil {
+		fmt.Print(report)
+	}
+	if err != nil {
+		fmt.Println(err)
 		fmt.Println("No data to process.")
 		return
 	}
+	dataSet.Name = DefaultDataSetName
+
 	// Print the data set
 	dataSet.Fix()
 	// fmt.Println(dataSet.DataSetPrettyPrint())
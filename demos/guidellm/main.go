@@ -17,6 +17,11 @@ func main() {
 	if len(os.Args) > 1 {
 		filePath = os.Args[1]
 	}
+	// optional statistic of the latency distributions to fit (average, p50, p90, p95, p99)
+	stat := config.StatisticAverage
+	if len(os.Args) > 2 {
+		stat = config.Statistic(os.Args[2])
+	}
 
 	dataBytes, err_acc := os.ReadFile(filePath)
 	if err_acc != nil {
@@ -24,15 +29,10 @@ func main() {
 		return
 	}
 
-	var dataReader reader.Reader
-	dataReader = reader.NewGuideLLMData()
-	if err := dataReader.ReadFrom(dataBytes); err != nil {
-		fmt.Println("GuideLLM data not in json output format, Trying CSV ...")
-		dataReader = reader.NewGuideLLMCSVData()
-		if err := dataReader.ReadFrom(dataBytes); err != nil {
-			fmt.Println(err)
-			return
-		}
+	dataReader, err := reader.Detect(dataBytes, filePath)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 	dataSet := dataReader.CreateDataSet()
 	// fmt.Println(dataSet.DataSetPrettyPrint())
@@ -44,6 +44,7 @@ func main() {
 	}
 
 	optimizer := core.NewOptimizer(initParms)
+	optimizer.Statistic = stat
 	optimizerResult, err := optimizer.Optimize(dataSet, core.Model)
 	if err != nil {
 		fmt.Println("Optimization failed:", err)
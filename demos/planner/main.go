@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+	"github.com/llm-inferno/model-trainer/pkg/utils"
+)
+
+func main() {
+	// fitted model parameters (e.g. from demos/qm)
+	parms := &config.ModelParams{
+		Alpha: 6.037069773706014,
+		Beta:  0.012351457184339894,
+		Gamma: 0.00003754977789772998,
+	}
+
+	spec := &core.PlanSpec{
+		TotalRequestRate: 20.0,
+		InputTokens:      2048,
+		OutputTokens:     512,
+		SLO: &config.SLO{
+			TargetTTFT: 200,
+			TargetITL:  30,
+		},
+		MaxBatchSizes: []int{64, 128, 256, 512},
+		MaxNumTokens:  []int{4096, 8192, 16384},
+	}
+
+	// optionally read plan specification from a json file
+	if len(os.Args) > 1 {
+		bytes_acc, err_acc := os.ReadFile(os.Args[1])
+		if err_acc != nil {
+			fmt.Println(err_acc)
+			return
+		}
+		s, err := utils.FromDataToSpec(bytes_acc, core.PlanSpec{})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		spec = s
+	}
+
+	planner := core.NewPlanner(parms)
+	results, err := planner.Plan(spec, core.Model)
+	if err != nil {
+		fmt.Println("Planning failed:", err)
+		return
+	}
+
+	fmt.Println("Planning completed successfully!")
+	fmt.Println("-------------------------------")
+	if jsonStr, err := json.Marshal(parms); err == nil {
+		fmt.Printf("Parameters used: %v\n", string(jsonStr))
+	}
+	if jsonStr, err := json.Marshal(spec); err == nil {
+		fmt.Printf("Plan specification: %v\n", string(jsonStr))
+	}
+	fmt.Printf("Number of feasible configurations: %d\n", len(results))
+	fmt.Println(core.PlanPrettyPrint(results))
+}
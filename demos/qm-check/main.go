@@ -27,7 +27,6 @@ func main() {
 		return
 	}
 	dataSet.Fix()
-	dataSet.ToMSecs()
 	// fmt.Println(dataSet.DataSetPrettyPrint())
 
 	parms := &config.ModelParams{
@@ -3,104 +3,20 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"math"
-	"math/rand"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/llm-inferno/model-trainer/pkg/config"
 	"github.com/llm-inferno/model-trainer/pkg/core"
-	"github.com/llm-inferno/queue-analysis/pkg/analyzer"
+	"github.com/llm-inferno/model-trainer/pkg/synth"
 )
 
 const (
-	DefaultNumPoints   = 20
-	DefaultNoisePercent = 2.0
-
-	// known "true" parameters used to generate synthetic data
-	TrueAlpha = 6.0
-	TrueBeta  = 0.02
-	TrueGamma = 0.00005
-
-	// fixed queue configuration
-	MaxBatchSize = 256
-	MaxNumTokens = 8192
-	MaxQueueSize = 256
-
-	// random ranges for input variables
-	MinInputTokens  = 200.0
-	MaxInputTokens  = 1000.0
-	MinOutputTokens = 200.0
-	MaxOutputTokens = 1000.0
-	MinRPS          = 1.0
-	MaxRPS          = 6.0
+	DefaultNumPoints    = synth.DefaultNumPoints
+	DefaultNoisePercent = 100 * synth.DefaultNoiseFraction
 )
 
-// perturb adds Gaussian noise with std = noiseFraction * value, clamped to a small positive floor.
-func perturb(value float64, noiseFraction float64, rng *rand.Rand) float64 {
-	noisy := value * (1.0 + noiseFraction*rng.NormFloat64())
-	return math.Max(noisy, value*0.01)
-}
-
-func generateDataSet(numPoints int, noisePercent float64, rng *rand.Rand) *core.DataSet {
-	trueParms := &analyzer.ServiceParms{
-		Alpha: TrueAlpha,
-		Beta:  TrueBeta,
-		Gamma: TrueGamma,
-	}
-	noiseFraction := noisePercent / 100.0
-
-	randInRange := func(min, max float64) float64 {
-		return min + rng.Float64()*(max-min)
-	}
-
-	dataSet := core.NewDataSet("random synthetic data")
-	for dataSet.Size() < numPoints {
-		inputTokens := randInRange(MinInputTokens, MaxInputTokens)
-		outputTokens := randInRange(MinOutputTokens, MaxOutputTokens)
-		requestRate := randInRange(MinRPS, MaxRPS)
-
-		queueConfig := &analyzer.Configuration{
-			MaxBatchSize: MaxBatchSize,
-			MaxNumTokens: MaxNumTokens,
-			MaxQueueSize: MaxQueueSize,
-			ServiceParms: trueParms,
-		}
-		requestSize := &analyzer.RequestSize{
-			AvgInputTokens:  float32(inputTokens),
-			AvgOutputTokens: float32(outputTokens),
-		}
-
-		queueAnalyzer, err := analyzer.NewLLMQueueAnalyzer(queueConfig, requestSize)
-		if err != nil {
-			continue
-		}
-		metrics, err := queueAnalyzer.Analyze(float32(requestRate))
-		if err != nil {
-			continue
-		}
-
-		ttft := float64(metrics.AvgTTFT)
-		itl := float64(metrics.AvgTokenTime)
-		if noiseFraction > 0 {
-			ttft = perturb(ttft, noiseFraction, rng)
-			itl = perturb(itl, noiseFraction, rng)
-		}
-
-		dataSet.AppendDataPoint(&core.DataPoint{
-			RequestRate:  requestRate,
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
-			AvgTTFTTime:  ttft,
-			AvgITLTime:   itl,
-			MaxBatchSize: MaxBatchSize,
-			MaxNumTokens: MaxNumTokens,
-		})
-	}
-	return dataSet
-}
-
 func main() {
 	numPoints := DefaultNumPoints
 	if len(os.Args) > 1 {
@@ -133,12 +49,21 @@ func main() {
 	} else {
 		seed = time.Now().UnixNano()
 	}
-	rng := rand.New(rand.NewSource(seed))
+
+	synthConfig := synth.DefaultConfig()
+	synthConfig.NumPoints = numPoints
+	synthConfig.Seed = seed
+	synthConfig.TTFTNoise.Level = noisePercent / 100.0
+	synthConfig.ITLNoise.Level = noisePercent / 100.0
 
 	fmt.Printf("Generating %d synthetic data points (noise=%.1f%%, seed=%d)...\n", numPoints, noisePercent, seed)
-	dataSet := generateDataSet(numPoints, noisePercent, rng)
+	dataSet, truth, err := synth.NewGenerator(synthConfig).Generate()
+	if err != nil {
+		fmt.Println("Generation failed:", err)
+		os.Exit(1)
+	}
 
-	trueParms := &config.ModelParams{Alpha: TrueAlpha, Beta: TrueBeta, Gamma: TrueGamma}
+	trueParms := truth.Parms
 	initParms := &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}
 
 	optimizer := core.NewOptimizer(initParms)
@@ -162,4 +87,7 @@ func main() {
 	if jsonStr, err := json.Marshal(optimizerResult); err == nil {
 		fmt.Println(string(jsonStr))
 	}
+	if jsonStr, err := json.Marshal(truth.RelativeErrors(optimizerResult.OptimizedParms)); err == nil {
+		fmt.Printf("Relative errors:    %v\n", string(jsonStr))
+	}
 }
@@ -1,9 +1,17 @@
 package main
 
-import "github.com/llm-inferno/model-trainer/pkg/service"
+import (
+	"os"
 
-// create and run a model trainer service
+	"github.com/llm-inferno/model-trainer/pkg/cli"
+	"github.com/llm-inferno/model-trainer/pkg/service"
+)
+
+// run a command of the model trainer, or create and run a model trainer service if none is given
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	trainer := service.NewTrainer()
 	trainer.Run()
 }
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// exit codes of the command line interface
+const (
+	ExitOK      = 0 // success
+	ExitError   = 1 // command failed
+	ExitUsage   = 2 // invalid command line
+	ExitInvalid = 3 // input data refused for validation errors (-strict)
+)
+
+// a subcommand of the command line interface
+type Command struct {
+	Name        string
+	Description string
+	Run         func(args []string, stdout, stderr io.Writer) int
+}
+
+// registered subcommands, by name
+var commands = map[string]*Command{}
+
+func register(cmd *Command) {
+	commands[cmd.Name] = cmd
+}
+
+// Run runs the subcommand given by the first argument and returns the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		Usage(stderr)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		Usage(stderr)
+		return ExitUsage
+	}
+	return cmd.Run(args[1:], stdout, stderr)
+}
+
+// print the list of subcommands
+func Usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: model-trainer [command] [options]\n\n")
+	fmt.Fprintf(w, "Without a command, the model trainer service is started (same as serve).\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].Description)
+	}
+	fmt.Fprintf(w, "\nRun 'model-trainer [command] -h' for the options of a command.\n")
+	fmt.Fprintf(w, "Exit codes: 0 success, 1 error, 2 invalid usage, 3 invalid input data (-strict).\n")
+}
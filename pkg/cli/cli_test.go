@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	const testdata = "../reader/testdata/"
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string // substrings of the output
+		wantStderr []string // substrings of the messages
+	}{
+		{
+			name:       "train",
+			args:       []string{"train", "-in", "../../samples/qm_train_s4.json"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"data set: scenario4_train (14 points)", "model: mean", "parms: alpha="},
+		},
+		{
+			name:       "evaluate a directory with a file not recognized",
+			args:       []string{"evaluate", "-in", testdata + "vllm-bench", "-parms", "5,0.01,0.00005"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"data set of 2 files (2 points)", "parms: alpha=5, beta=0.01, gamma=5e-05", "errors (average)"},
+			wantStderr: []string{"notes.json", "unrecognized input format"},
+		},
+		{
+			name:       "inspect",
+			args:       []string{"inspect", "-in", testdata + "llmperf"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"data set of 2 files (2 points", "llmperf", "Validation: 0 error(s)"},
+		},
+		{
+			name:       "convert",
+			args:       []string{"convert", "-in", testdata + "guidellm.csv", "-format", "json"},
+			wantCode:   ExitOK,
+			wantStdout: []string{`"name": "GuideLLM CSV benchmark data"`, `"requestRate"`},
+			wantStderr: []string{"1 of 4 benchmarks left out by the selection options"},
+		},
+		{
+			name:       "trace in a single window",
+			args:       []string{"inspect", "-in", testdata + "trace.jsonl", "-trace-group", "window", "-trace-window", "0"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"request trace data (1 points"},
+		},
+		{
+			name:       "generate with dispersed token counts",
+			args:       []string{"generate", "-points", "3", "-tokens-cv", "0.5", "-model", "dispersion", "-format", "csv"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"inputTokensStdDev,outputTokensStdDev"},
+		},
+		{
+			name:       "help",
+			args:       []string{"help"},
+			wantCode:   ExitOK,
+			wantStderr: []string{"Commands:", "train", "Exit codes:"},
+		},
+		{
+			name:       "missing file",
+			args:       []string{"inspect", "-in", testdata + "missing.json"},
+			wantCode:   ExitError,
+			wantStderr: []string{"no such file"},
+		},
+		{
+			name:       "missing -in",
+			args:       []string{"train"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{"missing input data set file (-in)", "Usage of train:"},
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"inspect", "-bogus"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{"flag provided but not defined: -bogus", "Usage of inspect:"},
+		},
+		{
+			name:       "bad -parms",
+			args:       []string{"evaluate", "-in", testdata + "vllm-bench", "-parms", "1,2"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{"invalid -parms: expected alpha,beta,gamma"},
+		},
+		{
+			name:       "bad trace options",
+			args:       []string{"inspect", "-in", testdata + "trace.jsonl", "-trace-group", "model"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{`invalid reader options: unknown trace grouping "model"`},
+		},
+		{
+			name:       "unknown model",
+			args:       []string{"recovery", "-model", "median"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{`unknown model "median", expected mean or dispersion`},
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"fit"},
+			wantCode:   ExitUsage,
+			wantStderr: []string{`unknown command "fit"`, "Commands:"},
+		},
+		{
+			name:       "no command",
+			args:       []string{},
+			wantCode:   ExitUsage,
+			wantStderr: []string{"Usage: model-trainer"},
+		},
+		{
+			name:       "strict refusal",
+			args:       []string{"inspect", "-in", testdata + "llmperf", "-itl-stat", "p90", "-strict"},
+			wantCode:   ExitInvalid,
+			wantStderr: []string{"1 of 1 benchmarks without itl p90 in input, read as zero"},
+		},
+		{
+			name:       "strict refusal to train",
+			args:       []string{"train", "-in", testdata + "llmperf", "-itl-stat", "p90", "-strict"},
+			wantCode:   ExitInvalid,
+			wantStderr: []string{"without itl p90"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d\nstderr: %s", code, tt.wantCode, stderr.String())
+			}
+			for _, s := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("output does not contain %q:\n%s", s, stdout.String())
+				}
+			}
+			for _, s := range tt.wantStderr {
+				if !strings.Contains(stderr.String(), s) {
+					t.Errorf("messages do not contain %q:\n%s", s, stderr.String())
+				}
+			}
+			if tt.wantCode == ExitUsage && stdout.Len() != 0 {
+				t.Errorf("unexpected output on invalid usage: %s", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func init() {
+	register(&Command{
+		Name:        "convert",
+		Description: "convert benchmark files to a data set in json, CSV or JSON Lines",
+		Run:         runConvert,
+	})
+}
+
+// convert input files in any supported format, merged, to a data set in the native json format, or
+// exported as CSV or JSON Lines
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	name := flags.String("name", "", "name of the data set (default from the input files)")
+	labels := flags.Bool("labels", false, "add columns of data point labels (csv and jsonl)")
+	metadata := flags.Bool("metadata", false, "add columns of data point labels, with the data set metadata (csv and jsonl)")
+	out := addOutputFlags(flags, FormatJSON, FormatCSV, FormatJSONL)
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	dataSet, code := in.read(flags, readerOptions, stderr)
+	if code != ExitOK {
+		return code
+	}
+	if *name != "" {
+		dataSet.Name = *name
+	}
+	exportOpts := &core.ExportOptions{Labels: *labels, Metadata: *metadata}
+	if err := writeDataSet(dataSet, out.path, format, exportOpts, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
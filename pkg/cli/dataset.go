@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// methods of splitting a data set into train and test data sets
+const (
+	SplitNone       = ""
+	SplitRandom     = "random"
+	SplitStratified = "stratified"
+	SplitByLabel    = "label"
+)
+
+func init() {
+	register(&Command{
+		Name:        "dataset",
+		Description: "filter, deduplicate, sort, split and export a data set",
+		Run:         runDataSet,
+	})
+}
+
+// query and transform a data set file: filter, dedup, sort, then split, in that order, and write
+// the resulting data sets in the native json format, or exported as CSV or JSON Lines
+func runDataSet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dataset", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	out := addOutputFlags(flags, FormatJSON, FormatCSV, FormatJSONL)
+	filter := flags.String("filter", "", "filter expression, e.g. 'strategy != throughput && requestRate < 50'")
+	dedup := flags.Bool("dedup", false, "remove duplicates of identical data points")
+	sortBy := flags.String("sort", "", "field to sort data points by")
+	descending := flags.Bool("desc", false, "sort in descending order")
+	split := flags.String("split", SplitNone, "split method: random, stratified, or label")
+	testFraction := flags.Float64("test-fraction", 0.2, "fraction of test points (random and stratified splits)")
+	seed := flags.Int64("seed", 42, "seed of random splits")
+	by := flags.String("by", "", "field to stratify or split by (stratified and label splits)")
+	testValues := flags.String("test-values", "", "comma-separated field values of test points (label split)")
+	testOut := flags.String("test-out", "", "output test data set file (required with -split)")
+	labels := flags.Bool("labels", false, "add columns of data point labels (csv and jsonl)")
+	metadata := flags.Bool("metadata", false, "add columns of data point labels, with the data set metadata (csv and jsonl)")
+	parms := addParmsFlag(flags, "parms", "fitted parameters, adding columns of predictions and residuals (csv and jsonl)")
+	statistic := flags.String("statistic", "", "statistic of predictions and residuals: average, p50, p90, p95 or p99 (default average)")
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if len(in.paths) == 0 {
+		fmt.Fprintln(stderr, "missing input data set file (-in)")
+		flags.Usage()
+		return ExitUsage
+	}
+	if *split != SplitNone && *testOut == "" {
+		fmt.Fprintln(stderr, "missing output test data set file (-test-out)")
+		return ExitUsage
+	}
+
+	outFormat, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	exportOpts := &core.ExportOptions{Labels: *labels, Metadata: *metadata, Statistic: config.Statistic(*statistic)}
+	if exportOpts.Params, err = parms(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if outFormat == FormatJSON && (*labels || *metadata || exportOpts.Params != nil) {
+		fmt.Fprintln(stderr, "-labels, -metadata and -parms apply to csv and jsonl output only")
+		return ExitUsage
+	}
+	if !exportOpts.Statistic.IsValid() {
+		fmt.Fprintf(stderr, "unknown statistic %q\n", *statistic)
+		return ExitUsage
+	}
+
+	opts, err := readerOptions()
+	if err != nil {
+		fmt.Fprintln(stderr, "invalid reader options:", err)
+		return ExitUsage
+	}
+	dataSet, err := readDataSet(in, opts, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if *filter != "" {
+		if dataSet, err = dataSet.FilterExpr(*filter); err != nil {
+			fmt.Fprintln(stderr, "invalid filter:", err)
+			return ExitUsage
+		}
+	}
+	if *dedup {
+		dataSet = dataSet.Dedup()
+	}
+	if *sortBy != "" {
+		if err := dataSet.SortBy(*sortBy, *descending); err != nil {
+			fmt.Fprintln(stderr, "invalid sort:", err)
+			return ExitUsage
+		}
+	}
+
+	var test *core.DataSet
+	switch *split {
+	case SplitNone:
+	case SplitRandom:
+		dataSet, test = dataSet.SplitRandom(*testFraction, *seed)
+	case SplitStratified:
+		dataSet, test, err = dataSet.SplitStratified(*testFraction, *seed, *by)
+	case SplitByLabel:
+		dataSet, test, err = dataSet.SplitByLabel(*by, strings.Split(*testValues, ",")...)
+	default:
+		err = fmt.Errorf("unknown split method %q", *split)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "invalid split:", err)
+		return ExitUsage
+	}
+
+	if err := writeDataSet(dataSet, out.path, outFormat, exportOpts, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if test != nil {
+		if err := writeDataSet(test, *testOut, outFormat, exportOpts, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		fmt.Fprintf(stderr, "train: %d points, test: %d points\n", dataSet.Size(), test.Size())
+	}
+	return ExitOK
+}
+
+// write a data set in a format to a file, or to stdout if no file is given; export options apply
+// to CSV and JSON Lines
+func writeDataSet(dataSet *core.DataSet, path, format string, opts *core.ExportOptions, stdout io.Writer) error {
+	return writeOutput(path, stdout, func(w io.Writer) error {
+		switch format {
+		case FormatCSV:
+			return dataSet.WriteCSV(w, opts)
+		case FormatJSONL:
+			return dataSet.WriteJSONLines(w, opts)
+		}
+		return writeJSON(w, dataSet)
+	})
+}
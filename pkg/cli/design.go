@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func init() {
+	register(&Command{
+		Name:        "design",
+		Description: "recommend the next benchmark points to reduce parameter uncertainty",
+		Run:         runDesign,
+	})
+}
+
+// recommend benchmark points given a data set and a fit of it (fitted here if no parameters are given),
+// printed with the GuideLLM commands running them
+func runDesign(args []string, stdout, stderr io.Writer) int {
+	spec := &core.DesignSpec{}
+	flags := flag.NewFlagSet("design", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	parmsFlag := addParmsFlag(flags, "parms", "fitted parameters (default fit the data set)")
+	optimizerFlags := addOptimizerFlags(flags)
+	flags.IntVar(&spec.NumPoints, "points", config.DefaultNumDesignPoints, "number of points to recommend")
+	flags.Float64Var(&spec.MinRequestRate, "min-rate", 0, "minimum request rate (default minimum in data set)")
+	flags.Float64Var(&spec.MaxRequestRate, "max-rate", 0, "maximum request rate (default maximum in data set)")
+	flags.IntVar(&spec.NumRateLevels, "rate-levels", config.DefaultNumDesignRateLevels, "number of candidate request rates")
+	inputTokens := flags.String("input-tokens", "", "comma-separated candidate input tokens (default values in data set)")
+	outputTokens := flags.String("output-tokens", "", "comma-separated candidate output tokens (default values in data set)")
+	flags.IntVar(&spec.MaxBatchSize, "max-batch-size", 0, "maximum batch size of the server (default from data set)")
+	flags.IntVar(&spec.MaxNumTokens, "max-num-tokens", 0, "maximum number of tokens in a batch (default from data set)")
+	flags.Float64Var(&spec.MaxUtilization, "max-util", config.DefaultMaxDesignUtilization, "maximum server utilization of a point")
+	flags.Float64Var(&spec.NoiseLevel, "noise", 0, "relative noise of measured latencies (default estimated from the fit)")
+	target := flags.String("target", "http://localhost:8000", "target server of the GuideLLM commands")
+	out := addOutputFlags(flags, FormatText, FormatJSON)
+	asJSON := flags.Bool("json", false, "print the result as json (same as -format json)")
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if *asJSON {
+		out.format = FormatJSON
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if *inputTokens != "" {
+		if spec.InputTokens, err = parseFloats(*inputTokens); err != nil {
+			fmt.Fprintln(stderr, "invalid input tokens:", err)
+			return ExitUsage
+		}
+	}
+	if *outputTokens != "" {
+		if spec.OutputTokens, err = parseFloats(*outputTokens); err != nil {
+			fmt.Fprintln(stderr, "invalid output tokens:", err)
+			return ExitUsage
+		}
+	}
+	fitted, err := parmsFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	optimizer, err := optimizerFlags()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	dataSet, code := in.read(flags, readerOptions, stderr)
+	if code != ExitOK {
+		return code
+	}
+	if fitted == nil {
+		result, err := optimizer.Optimize(dataSet, core.Model)
+		var validationErr *core.ValidationError
+		if errors.As(err, &validationErr) {
+			return reportInvalid(validationErr, stderr)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "fit failed:", err)
+			return ExitError
+		}
+		fitted = result.OptimizedParms
+	}
+
+	result, err := core.NewDesigner(fitted).Recommend(dataSet, spec, core.ModelOfKind(core.Model, optimizer.Model))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	runs := result.GuideLLMRuns()
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		if format == FormatJSON {
+			return writeJSON(w, struct {
+				Parms  *config.ModelParams `json:"parms"`
+				Design *core.DesignResult  `json:"design"`
+				Runs   []*core.GuideLLMRun `json:"guidellm"`
+			}{fitted, result, runs})
+		}
+		fmt.Fprintf(w, "parms: %s\n", formatParms(fitted))
+		fmt.Fprint(w, core.DesignPrettyPrint(result))
+		fmt.Fprintln(w)
+		for _, run := range runs {
+			fmt.Fprintln(w, run.Command(*target))
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
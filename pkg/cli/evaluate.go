@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func init() {
+	register(&Command{
+		Name:        "evaluate",
+		Description: "compute the errors of model parameters on a data set",
+		Run:         runEvaluate,
+	})
+}
+
+// evaluate fitted model parameters on a (test) data set, writing the average errors of the predicted latencies
+func runEvaluate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	parmsFlag := addParmsFlag(flags, "parms", "fitted parameters (required)")
+	statistic := flags.String("statistic", "", "statistic of the latency distributions to compare: average, p50, p90, p95 or p99 (default average)")
+	modelFlag := addModelFlag(flags)
+	strict := flags.Bool("strict", false, "refuse data sets with validation errors")
+	out := addOutputFlags(flags, FormatText, FormatJSON)
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	parms, err := parmsFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if parms == nil {
+		fmt.Fprintln(stderr, "missing fitted parameters (-parms)")
+		return ExitUsage
+	}
+	stat := config.Statistic(*statistic)
+	if !stat.IsValid() {
+		fmt.Fprintf(stderr, "unknown statistic %q\n", *statistic)
+		return ExitUsage
+	}
+	kind, err := modelFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	dataSet, code := in.read(flags, readerOptions, stderr)
+	if code != ExitOK {
+		return code
+	}
+	if *strict {
+		if report := dataSet.Validate(); report.HasErrors() {
+			return reportInvalid(&core.ValidationError{Report: report}, stderr)
+		}
+	}
+
+	analyzer := core.NewAnalyzer(parms)
+	analyzer.Statistic = stat
+	analyzer.Model = kind
+	analyzer.Quiet = true
+	results := analyzer.Analyze(dataSet, core.Model)
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		if format == FormatJSON {
+			return writeJSON(w, struct {
+				Parms   *config.ModelParams     `json:"parms"`
+				Results *config.AnalysisResults `json:"results"`
+			}{parms, results})
+		}
+		fmt.Fprintf(w, "data set: %s (%d points)\n", dataSet.Name, dataSet.Size())
+		fmt.Fprintf(w, "parms: %s\n", formatParms(parms))
+		fmt.Fprintln(w, formatErrors(results))
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+	"github.com/llm-inferno/model-trainer/pkg/utils"
+)
+
+// formats of command outputs
+const (
+	FormatText  = "text"  // human-readable text
+	FormatJSON  = "json"  // json, the native json format for data sets
+	FormatCSV   = "csv"   // a row per data point, or per result
+	FormatJSONL = "jsonl" // json lines, an object per data point
+)
+
+// initial values of fitted parameters, unless given
+var defaultInitParms = config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}
+
+// flags of the output of a command
+type outputFlags struct {
+	path    string   // output file (stdout if empty)
+	format  string   // output format, empty for the default
+	formats []string // supported formats, the first one being the default
+}
+
+// add flags of the output file and format to a flag set, given the supported formats, the default first
+func addOutputFlags(flags *flag.FlagSet, formats ...string) *outputFlags {
+	out := &outputFlags{formats: formats}
+	flags.StringVar(&out.path, "out", "", "output file (default stdout)")
+	flags.StringVar(&out.format, "format", "", fmt.Sprintf("output format: %s (default from the -out extension, else %s)",
+		strings.Join(formats, ", "), formats[0]))
+	return out
+}
+
+// get the output format: given, from the extension of the output file, or the default
+func (o *outputFlags) Format() (string, error) {
+	return outputFormat(o.format, o.path, o.formats)
+}
+
+// get an output format, given or from the extension of an output file, among supported formats
+func outputFormat(format, path string, formats []string) (string, error) {
+	if format == "" {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if ext == "txt" {
+			ext = FormatText
+		}
+		if slices.Contains(formats, ext) {
+			return ext, nil
+		}
+		return formats[0], nil
+	}
+	if !slices.Contains(formats, format) {
+		return "", fmt.Errorf("unknown output format %q, expected %s", format, strings.Join(formats, ", "))
+	}
+	return format, nil
+}
+
+// write the output to a file, or to stdout if no file is given; nothing is written if writing fails
+func writeOutput(path string, stdout io.Writer, write func(w io.Writer) error) error {
+	var b bytes.Buffer
+	if err := write(&b); err != nil {
+		return err
+	}
+	if path == "" {
+		_, err := stdout.Write(b.Bytes())
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
+
+// write a value as indented json
+func writeJSON(w io.Writer, v any) error {
+	dataBytes, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(dataBytes))
+	return err
+}
+
+// add a flag of model parameters to a flag set, returning a function getting the parameters once parsed
+// (nil if not given)
+func addParmsFlag(flags *flag.FlagSet, name, usage string) func() (*config.ModelParams, error) {
+	value := flags.String(name, "", usage+": alpha,beta,gamma, or a json file of parameters or of a fit")
+	return func() (*config.ModelParams, error) {
+		if *value == "" {
+			return nil, nil
+		}
+		parms, err := parseParms(*value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -%s: %w", name, err)
+		}
+		return parms, nil
+	}
+}
+
+// parse model parameters, given as alpha,beta,gamma or as a json file holding either parameters,
+// the result of a fit (e.g. written by the train command), or parameters under a parms key
+// (e.g. written by the evaluate and design commands)
+func parseParms(s string) (*config.ModelParams, error) {
+	if values, err := parseFloats(s); err == nil {
+		if len(values) != 3 {
+			return nil, fmt.Errorf("expected alpha,beta,gamma")
+		}
+		return utils.CreateModelParamsFromParmsSlice(values), nil
+	}
+	dataBytes, err := os.ReadFile(s)
+	if err != nil {
+		return nil, fmt.Errorf("neither alpha,beta,gamma nor a readable file: %w", err)
+	}
+	var fit struct {
+		*config.ModelParams
+		OptimizedParms *config.ModelParams `json:"OptimizedParms"`
+		Parms          *config.ModelParams `json:"parms"`
+	}
+	if err := json.Unmarshal(dataBytes, &fit); err != nil {
+		return nil, fmt.Errorf("%s: %w", s, err)
+	}
+	parms := fit.OptimizedParms
+	if parms == nil {
+		parms = fit.Parms
+	}
+	if parms == nil {
+		parms = fit.ModelParams
+	}
+	if parms == nil || !utils.CheckParmsValid(parms) {
+		return nil, fmt.Errorf("%s: no valid parameters", s)
+	}
+	return parms, nil
+}
+
+// add a flag of the kind of model to a flag set, returning a function getting the kind once parsed
+func addModelFlag(flags *flag.FlagSet) func() (config.ModelKind, error) {
+	value := flags.String("model", string(config.ModelMean),
+		"model: mean, or dispersion accounting for the dispersion of token counts (inputTokensStdDev, outputTokensStdDev)")
+	return func() (config.ModelKind, error) {
+		kind := config.ModelKind(*value)
+		if !kind.IsValid() {
+			return "", fmt.Errorf("unknown model %q, expected mean or dispersion", *value)
+		}
+		return kind, nil
+	}
+}
+
+// add flags of optimizer options to a flag set, returning a function getting the optimizer once parsed
+func addOptimizerFlags(flags *flag.FlagSet) func() (*core.Optimizer, error) {
+	initParmsFlag := addParmsFlag(flags, "init", fmt.Sprintf("initial parameters (default %v,%v,%v)",
+		defaultInitParms.Alpha, defaultInitParms.Beta, defaultInitParms.Gamma))
+	modelFlag := addModelFlag(flags)
+	statistic := flags.String("statistic", "", "statistic of the latency distributions to fit: average, p50, p90, p95 or p99 (default average)")
+	strict := flags.Bool("strict", false, "refuse data sets with validation errors")
+	noScaling := flags.Bool("no-scaling", false, "do not scale parameters by their initial values")
+	maxIterations := flags.Int("max-iterations", 0, "maximum number of optimizer iterations (default if zero)")
+
+	return func() (*core.Optimizer, error) {
+		initParms, err := initParmsFlag()
+		if err != nil {
+			return nil, err
+		}
+		if initParms == nil {
+			initParms = &config.ModelParams{}
+			*initParms = defaultInitParms
+		}
+		stat := config.Statistic(*statistic)
+		if !stat.IsValid() {
+			return nil, fmt.Errorf("unknown statistic %q", *statistic)
+		}
+		kind, err := modelFlag()
+		if err != nil {
+			return nil, err
+		}
+		optimizer := core.NewOptimizer(initParms)
+		optimizer.Statistic = stat
+		optimizer.Model = kind
+		optimizer.Strict = *strict
+		optimizer.DisableScaling = *noScaling
+		optimizer.MaxIterations = *maxIterations
+		optimizer.Quiet = true
+		return optimizer, nil
+	}
+}
+
+// print the issues of a data set refused for validation errors, returning the exit code
+func reportInvalid(err *core.ValidationError, stderr io.Writer) int {
+	fmt.Fprint(stderr, err.Report)
+	return ExitInvalid
+}
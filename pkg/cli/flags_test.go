@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func TestParseParms(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"fit.json":     `{"OptimizedParms": {"alpha": 6.8, "beta": 0.02, "gamma": 0.00005}, "AnalysisResults": {}}`,
+		"design.json":  `{"parms": {"alpha": 5, "beta": 0.01, "gamma": 0.0001}, "design": {}}`,
+		"parms.json":   `{"alpha": 1, "beta": 0.5, "gamma": 0}`,
+		"empty.json":   `{"design": {}}`,
+		"invalid.json": `{"alpha": -1, "beta": 0.5, "gamma": 0}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		want        config.ModelParams
+		expectError string
+	}{
+		{name: "values", value: "6.8, 0.02,5e-5", want: config.ModelParams{Alpha: 6.8, Beta: 0.02, Gamma: 0.00005}},
+		{name: "result of a fit", value: filepath.Join(dir, "fit.json"), want: config.ModelParams{Alpha: 6.8, Beta: 0.02, Gamma: 0.00005}},
+		{name: "parms key", value: filepath.Join(dir, "design.json"), want: config.ModelParams{Alpha: 5, Beta: 0.01, Gamma: 0.0001}},
+		{name: "parameters", value: filepath.Join(dir, "parms.json"), want: config.ModelParams{Alpha: 1, Beta: 0.5}},
+		{name: "two values", value: "1,2", expectError: "expected alpha,beta,gamma"},
+		{name: "no parameters", value: filepath.Join(dir, "empty.json"), expectError: "no valid parameters"},
+		{name: "negative parameter", value: filepath.Join(dir, "invalid.json"), expectError: "no valid parameters"},
+		{name: "missing file", value: filepath.Join(dir, "missing.json"), expectError: "neither alpha,beta,gamma nor a readable file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parms, err := parseParms(tt.value)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *parms != tt.want {
+				t.Errorf("got %+v, want %+v", *parms, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	formats := []string{FormatText, FormatJSON, FormatCSV}
+	tests := []struct {
+		name        string
+		format      string
+		path        string
+		want        string
+		expectError string
+	}{
+		{name: "default", want: FormatText},
+		{name: "extension of the output file", path: "out/fit.CSV", want: FormatCSV},
+		{name: "text file", path: "fit.txt", want: FormatText},
+		{name: "unsupported extension", path: "fit.jsonl", want: FormatText},
+		{name: "given format", format: FormatJSON, path: "fit.csv", want: FormatJSON},
+		{name: "unsupported format", format: FormatJSONL, expectError: "unknown output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := outputFormat(tt.format, tt.path, formats)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != tt.want {
+				t.Errorf("got %q, want %q", format, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddOptimizerFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectError string
+		validateFn  func(t *testing.T, optimizer *core.Optimizer)
+	}{
+		{
+			name: "defaults",
+			validateFn: func(t *testing.T, optimizer *core.Optimizer) {
+				if *optimizer.InitParms != defaultInitParms || optimizer.Model != config.ModelMean || !optimizer.Quiet {
+					t.Errorf("unexpected optimizer %+v", optimizer)
+				}
+			},
+		},
+		{
+			name: "dispersion model",
+			args: []string{"-model", "dispersion", "-statistic", "p90", "-init", "2,0.02,0.0001"},
+			validateFn: func(t *testing.T, optimizer *core.Optimizer) {
+				if optimizer.Model != config.ModelDispersion || optimizer.Statistic != config.StatisticP90 || optimizer.InitParms.Alpha != 2 {
+					t.Errorf("unexpected optimizer %+v", optimizer)
+				}
+			},
+		},
+		{name: "unknown model", args: []string{"-model", "median"}, expectError: "unknown model"},
+		{name: "unknown statistic", args: []string{"-statistic", "p42"}, expectError: "unknown statistic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			optimizerFlags := addOptimizerFlags(flags)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			optimizer, err := optimizerFlags()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, optimizer)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/llm-inferno/model-trainer/pkg/synth"
+)
+
+func init() {
+	register(&Command{
+		Name:        "generate",
+		Description: "generate a synthetic data set from known model parameters",
+		Run:         runGenerate,
+	})
+}
+
+// generate a synthetic data set from a configuration of the generator, possibly read from a file,
+// with flags overriding it, and write the data set and optionally its ground truth
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	defaults := synth.DefaultConfig()
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", "", "json file of the generator configuration (default configuration if not given)")
+	parmsFlag := addParmsFlag(flags, "parms", fmt.Sprintf("true parameters (default %v,%v,%v)",
+		defaults.Parms.Alpha, defaults.Parms.Beta, defaults.Parms.Gamma))
+	design := flags.String("design", string(defaults.Design), "experiment design: random or grid")
+	numPoints := flags.Int("points", defaults.NumPoints, "number of data points (random design)")
+	numLevels := flags.Int("levels", defaults.NumLevels, "number of levels per variable (grid design)")
+	noise := flags.Float64("noise", defaults.TTFTNoise.Level, "multiplicative noise of TTFT and ITL (fraction)")
+	tokensCV := flags.Float64("tokens-cv", defaults.TokensCV, "coefficient of variation of the token counts of requests (none if zero)")
+	modelFlag := addModelFlag(flags)
+	seed := flags.Int64("seed", 42, "seed of the random number generator")
+	name := flags.String("name", defaults.Name, "name of the data set")
+	truthOut := flags.String("truth", "", "write the ground truth (true parameters, noise-free latencies) as json to a file")
+	out := addOutputFlags(flags, FormatJSON, FormatCSV, FormatJSONL)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	parms, err := parmsFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	model, err := modelFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	cfg := defaults
+	if *configFile != "" {
+		dataBytes, err := os.ReadFile(*configFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		if err := json.Unmarshal(dataBytes, cfg); err != nil {
+			fmt.Fprintf(stderr, "invalid configuration %s: %v\n", *configFile, err)
+			return ExitUsage
+		}
+	}
+	// flags given override the configuration
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "design":
+			cfg.Design = synth.Design(*design)
+		case "points":
+			cfg.NumPoints = *numPoints
+		case "levels":
+			cfg.NumLevels = *numLevels
+		case "noise":
+			cfg.TTFTNoise = synth.NoiseModel{Kind: synth.NoiseMultiplicative, Level: *noise}
+			cfg.ITLNoise = cfg.TTFTNoise
+		case "name":
+			cfg.Name = *name
+		case "tokens-cv":
+			cfg.TokensCV = *tokensCV
+		case "model":
+			cfg.Model = model
+		}
+	})
+	if *configFile == "" || isFlagSet(flags, "seed") {
+		cfg.Seed = *seed
+	}
+	if parms != nil {
+		cfg.Parms = parms
+	}
+	if err := cfg.Check(); err != nil {
+		fmt.Fprintln(stderr, "invalid configuration:", err)
+		return ExitUsage
+	}
+
+	dataSet, truth, err := synth.NewGenerator(cfg).Generate()
+	if err != nil {
+		fmt.Fprintln(stderr, "generation failed:", err)
+		return ExitError
+	}
+	if err := writeDataSet(dataSet, out.path, format, nil, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if *truthOut != "" {
+		if err := writeOutput(*truthOut, nil, func(w io.Writer) error { return writeJSON(w, truth) }); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+	}
+	return ExitOK
+}
+
+// check whether a flag was given on the command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
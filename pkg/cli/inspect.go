@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+	"github.com/llm-inferno/model-trainer/pkg/reader"
+)
+
+func init() {
+	register(&Command{
+		Name:        "inspect",
+		Description: "summarize and validate input files and their data set",
+		Run:         runInspect,
+	})
+}
+
+// fields of data points summarized by their range
+var inspectFields = []string{"requestRate", "inputTokens", "outputTokens", "avgTTFTTime", "avgITLTime", "maxBatchSize", "maxNumTokens"}
+
+// labels of data points summarized by their values
+var inspectLabels = []string{"model", "accelerator", "tensorParallelism", "engineVersion", "source"}
+
+// range of the values of a field in a data set
+type fieldSummary struct {
+	Field string  `json:"field"`
+	Min   float64 `json:"min"`
+	Mean  float64 `json:"mean"`
+	Max   float64 `json:"max"`
+}
+
+// summary of input files and of their data set
+type inspection struct {
+	Name            string                    `json:"name"`
+	Points          int                       `json:"points"`
+	WithPercentiles int                       `json:"withPercentiles"` // number of points with TTFT and ITL percentiles
+	Fields          []fieldSummary            `json:"fields"`
+	Labels          map[string]map[string]int `json:"labels"` // number of points per label value
+	Files           *reader.IngestReport      `json:"files"`
+	Validation      *core.ValidationReport    `json:"validation"`
+}
+
+// read input files, and summarize the files, the ranges of fields and the labels of the data set,
+// with its validation issues
+func runInspect(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	strict := flags.Bool("strict", false, "exit with an error code if the data set has validation errors")
+	out := addOutputFlags(flags, FormatText, FormatJSON)
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if len(in.paths) == 0 {
+		fmt.Fprintln(stderr, "missing input data set file (-in)")
+		flags.Usage()
+		return ExitUsage
+	}
+	opts, err := readerOptions()
+	if err != nil {
+		fmt.Fprintln(stderr, "invalid reader options:", err)
+		return ExitUsage
+	}
+	dataSet, report, err := reader.Ingest(in.paths, opts)
+	if report != nil {
+		reportFiles(report, stderr)
+	}
+	if err != nil {
+		if report != nil && len(report.Files) > 1 {
+			fmt.Fprint(stderr, report)
+		}
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	result := inspect(dataSet)
+	result.Files = report
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		if format == FormatJSON {
+			return writeJSON(w, result)
+		}
+		writeInspection(w, result)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if *strict && result.Validation.HasErrors() {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// summarize a data set
+func inspect(dataSet *core.DataSet) *inspection {
+	result := &inspection{
+		Name:       dataSet.Name,
+		Points:     dataSet.Size(),
+		Fields:     []fieldSummary{},
+		Labels:     map[string]map[string]int{},
+		Validation: dataSet.Validate(),
+	}
+	for _, dp := range dataSet.Data {
+		if dp.TTFTPercentiles != nil && dp.ITLPercentiles != nil {
+			result.WithPercentiles++
+		}
+	}
+	for _, field := range inspectFields {
+		summary := fieldSummary{Field: field, Min: math.Inf(1), Max: math.Inf(-1)}
+		for i := range dataSet.Data {
+			v, _ := dataSet.FieldValue(i, field)
+			summary.Min = math.Min(summary.Min, v.Num)
+			summary.Max = math.Max(summary.Max, v.Num)
+			summary.Mean += v.Num / float64(dataSet.Size())
+		}
+		result.Fields = append(result.Fields, summary)
+	}
+	for _, label := range inspectLabels {
+		for i := range dataSet.Data {
+			v, _ := dataSet.FieldValue(i, label)
+			if s := v.String(); s != "" && s != "0" {
+				if result.Labels[label] == nil {
+					result.Labels[label] = map[string]int{}
+				}
+				result.Labels[label][s]++
+			}
+		}
+	}
+	return result
+}
+
+// write a summary of a data set as text
+func writeInspection(w io.Writer, result *inspection) {
+	fmt.Fprintf(w, "data set: %s (%d points, %d with percentiles)\n", result.Name, result.Points, result.WithPercentiles)
+	if len(result.Files.Files) > 1 || result.Files.Errors > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, result.Files)
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tMIN\tMEAN\tMAX")
+	for _, f := range result.Fields {
+		fmt.Fprintf(tw, "%s\t%.4g\t%.4g\t%.4g\n", f.Field, f.Min, f.Mean, f.Max)
+	}
+	tw.Flush()
+	if len(result.Labels) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "LABEL\tVALUES")
+		for _, label := range inspectLabels {
+			counts := result.Labels[label]
+			if counts == nil {
+				continue
+			}
+			values := make([]string, 0, len(counts))
+			for v := range counts {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			for i, v := range values {
+				values[i] = fmt.Sprintf("%s (%d)", v, counts[v])
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", label, strings.Join(values, ", "))
+		}
+		tw.Flush()
+	}
+	fmt.Fprintln(w)
+	fmt.Fprint(w, result.Validation)
+}
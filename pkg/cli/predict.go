@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func init() {
+	register(&Command{
+		Name:        "predict",
+		Description: "predict latencies of model parameters for given workloads",
+		Run:         runPredict,
+	})
+}
+
+// prediction of the model for input variables
+type prediction struct {
+	Input  *config.InputVars  `json:"input"`
+	Output *config.OutputVars `json:"output,omitempty"` // nil if the model fails (e.g. unstable queue)
+	Error  string             `json:"error,omitempty"`
+}
+
+// predict the latencies of fitted model parameters, for the data points of a data set, or for all
+// combinations of given request rates and token counts
+func runPredict(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("predict", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	parmsFlag := addParmsFlag(flags, "parms", "fitted parameters (required)")
+	statistic := flags.String("statistic", "", "statistic of the latency distributions to predict: average, p50, p90, p95 or p99 (default average)")
+	modelFlag := addModelFlag(flags)
+	rates := flags.String("rate", "", "comma-separated request rates (requests/sec), without -in")
+	inputTokens := flags.String("input-tokens", "", "comma-separated average input tokens, without -in")
+	outputTokens := flags.String("output-tokens", "", "comma-separated average output tokens, without -in")
+	maxBatchSize := flags.Int("max-batch-size", config.DefaultMaxBatchSize, "maximum batch size of the server, without -in")
+	maxNumTokens := flags.Int("max-num-tokens", config.DefaultMaxNumTokens, "maximum number of tokens in a batch, without -in")
+	out := addOutputFlags(flags, FormatText, FormatJSON, FormatCSV)
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	parms, err := parmsFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if parms == nil {
+		fmt.Fprintln(stderr, "missing fitted parameters (-parms)")
+		return ExitUsage
+	}
+	stat := config.Statistic(*statistic)
+	if !stat.IsValid() {
+		fmt.Fprintf(stderr, "unknown statistic %q\n", *statistic)
+		return ExitUsage
+	}
+	kind, err := modelFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	var inputs []*config.InputVars
+	if len(in.paths) > 0 {
+		dataSet, code := in.read(flags, readerOptions, stderr)
+		if code != ExitOK {
+			return code
+		}
+		inputs, _ = dataSet.GetInOutVars()
+	} else {
+		if *rates == "" || *inputTokens == "" || *outputTokens == "" {
+			fmt.Fprintln(stderr, "missing input data set file (-in), or workloads (-rate, -input-tokens and -output-tokens)")
+			flags.Usage()
+			return ExitUsage
+		}
+		lists := [][]float64{}
+		for _, s := range []string{*rates, *inputTokens, *outputTokens} {
+			values, err := parseFloats(s)
+			if err != nil {
+				fmt.Fprintln(stderr, "invalid workloads:", err)
+				return ExitUsage
+			}
+			lists = append(lists, values)
+		}
+		for _, rate := range lists[0] {
+			for _, inTokens := range lists[1] {
+				for _, outTokens := range lists[2] {
+					inputs = append(inputs, &config.InputVars{RequestRate: rate, InputTokens: inTokens, OutputTokens: outTokens,
+						MaxBatchSize: *maxBatchSize, MaxNumTokens: *maxNumTokens})
+				}
+			}
+		}
+	}
+
+	model := core.PercentileModel(core.ModelOfKind(core.Model, kind), stat)
+	predictions := make([]prediction, len(inputs))
+	for i, x := range inputs {
+		predictions[i].Input = x
+		if y, err := model(x, parms); err != nil {
+			predictions[i].Error = err.Error()
+		} else {
+			predictions[i].Output = y
+		}
+	}
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		switch format {
+		case FormatJSON:
+			return writeJSON(w, predictions)
+		case FormatCSV:
+			return writePredictionsCSV(w, predictions)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RATE\tIN\tOUT\tBATCH\tTOKENS\tTTFT\tITL\tWAIT\tPREFILL\tRHO")
+		for _, p := range predictions {
+			x := p.Input
+			fmt.Fprintf(tw, "%g\t%g\t%g\t%d\t%d\t", x.RequestRate, x.InputTokens, x.OutputTokens, x.MaxBatchSize, x.MaxNumTokens)
+			if y := p.Output; y != nil {
+				fmt.Fprintf(tw, "%.3f\t%.3f\t%.3f\t%.3f\t%.3f\n", y.AvgTTFTTime, y.AvgITLTime, y.AvgWaitTime, y.AvgPrefillTime, y.Rho)
+			} else {
+				fmt.Fprintf(tw, "%s\n", p.Error)
+			}
+		}
+		return tw.Flush()
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+// write predictions as CSV, with empty predicted values where the model fails
+func writePredictionsCSV(w io.Writer, predictions []prediction) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write([]string{"requestRate", "inputTokens", "outputTokens", "maxBatchSize", "maxNumTokens",
+		"avgTTFTTime", "avgITLTime", "avgWaitTime", "avgPrefillTime", "rho", "error"})
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, p := range predictions {
+		x := p.Input
+		row := []string{format(x.RequestRate), format(x.InputTokens), format(x.OutputTokens),
+			strconv.Itoa(x.MaxBatchSize), strconv.Itoa(x.MaxNumTokens)}
+		if y := p.Output; y != nil {
+			row = append(row, format(y.AvgTTFTTime), format(y.AvgITLTime), format(y.AvgWaitTime), format(y.AvgPrefillTime), format(y.Rho), "")
+		} else {
+			row = append(row, "", "", "", "", "", p.Error)
+		}
+		csvWriter.Write(row)
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+	"github.com/llm-inferno/model-trainer/pkg/reader"
+)
+
+// add flags of reader options to a flag set, returning a function getting the options once parsed
+func addReaderFlags(flags *flag.FlagSet) func() (*reader.Options, error) {
+	maxPoints := flags.Int("max-points", 0, fmt.Sprintf("maximum number of benchmarks read from a file, e.g. %d (no limit if zero)",
+		reader.DefaultLimitNumDataPoints))
+	selection := flags.String("select", string(reader.SelectFirst), "selection of benchmarks: first, even, or saturation")
+	saturation := flags.Float64("saturation", reader.DefaultSaturationThreshold, "fraction of the saturation rate below which benchmarks are selected")
+	include := flags.String("include", "", "comma-separated strategies to include (default all)")
+	exclude := flags.String("exclude", reader.StrategyThroughput, "comma-separated strategies to exclude")
+	ttftStat := flags.String("ttft-stat", string(reader.DefaultTTFTStat), "statistic of TTFT: mean, median, p90, p95, or p99")
+	itlStat := flags.String("itl-stat", string(reader.DefaultITLStat), "statistic of ITL: mean, median, p90, p95, or p99")
+	tpotStat := flags.String("tpot-stat", string(reader.DefaultTPOTStat), "statistic of TPOT: mean, median, p90, p95, or p99")
+	tokensStat := flags.String("tokens-stat", string(reader.DefaultTokensStat), "statistic of token counts: mean or median")
+	itlMetric := flags.String("itl-metric", reader.MetricITL, "metric giving the inter-token latency: itl or tpot")
+	traceGroup := flags.String("trace-group", "auto", "grouping of traced requests into data points: auto, window, or run")
+	traceWindow := flags.Float64("trace-window", reader.DefaultTraceWindow, "length of time windows of traced requests (sec), a single window if zero")
+	traceWarmUp := flags.Float64("trace-warm-up", reader.DefaultTraceWarmUp, "period of traced requests discarded at the start of the trace, or of each run (sec)")
+	traceCoolDown := flags.Float64("trace-cool-down", reader.DefaultTraceCoolDown, "period of traced requests discarded at the end of the trace, or of each run (sec)")
+	traceMinRequests := flags.Int("trace-min-requests", reader.DefaultTraceMinRequests, "minimum number of successful traced requests of a data point")
+
+	return func() (*reader.Options, error) {
+		grouping := reader.TraceGrouping(*traceGroup)
+		if grouping == "auto" {
+			grouping = reader.GroupAuto
+		}
+		opts := &reader.Options{
+			MaxPoints:           *maxPoints,
+			Selection:           reader.Selection(*selection),
+			SaturationThreshold: *saturation,
+			Include:             splitList(*include),
+			Exclude:             splitList(*exclude),
+			TTFTStat:            reader.Stat(*ttftStat),
+			ITLStat:             reader.Stat(*itlStat),
+			TPOTStat:            reader.Stat(*tpotStat),
+			TokensStat:          reader.Stat(*tokensStat),
+			ITLMetric:           *itlMetric,
+			Trace: &reader.TraceOptions{
+				GroupBy:     grouping,
+				Window:      *traceWindow,
+				WarmUp:      *traceWarmUp,
+				CoolDown:    *traceCoolDown,
+				MinRequests: *traceMinRequests,
+			},
+		}
+		return opts, opts.Check()
+	}
+}
+
+// read a data set from the input files, directories or glob patterns, each file in any registered format
+// (e.g. native json, GuideLLM json, CSV or HTML), possibly gzipped or an archive, merged; benchmarks are
+// selected by the reader options (all if nil); reader warnings and benchmarks left out are reported, with
+// a table of files if several, and the ingestion report is written if asked for
+func readDataSet(in *inputFlags, opts *reader.Options, stderr io.Writer) (*core.DataSet, error) {
+	dataSet, report, err := reader.Ingest(in.paths, opts)
+	if report != nil {
+		reportFiles(report, stderr)
+		if len(report.Files) > 1 {
+			fmt.Fprint(stderr, report)
+		}
+		if in.report != "" {
+			writeErr := writeOutput(in.report, nil, func(w io.Writer) error { return writeJSON(w, report) })
+			if writeErr != nil {
+				return nil, writeErr
+			}
+		}
+	}
+	return dataSet, err
+}
+
+// print the warnings of readers and the number of benchmarks left out by the selection options, per file
+func reportFiles(report *reader.IngestReport, stderr io.Writer) {
+	for _, f := range report.Files {
+		path := f.Path
+		if f.Archive != "" {
+			path = f.Archive + "/" + f.Path
+		}
+		for _, warning := range f.Warnings {
+			fmt.Fprintf(stderr, "warning: %s: %s\n", path, warning)
+		}
+		if f.Skipped > 0 {
+			fmt.Fprintf(stderr, "%s: %d of %d benchmarks left out by the selection options (-max-points, -select, -include, -exclude)\n",
+				path, f.Skipped, f.Points+f.Skipped)
+		}
+	}
+}
+
+// read the data set of the input files, once flags are parsed, checking that inputs are given and that reader
+// options are valid; an exit code is returned on failure (ExitOK otherwise)
+func (in *inputFlags) read(flags *flag.FlagSet, readerOptions func() (*reader.Options, error), stderr io.Writer) (*core.DataSet, int) {
+	if len(in.paths) == 0 {
+		fmt.Fprintln(stderr, "missing input data set file (-in)")
+		flags.Usage()
+		return nil, ExitUsage
+	}
+	opts, err := readerOptions()
+	if err != nil {
+		fmt.Fprintln(stderr, "invalid reader options:", err)
+		return nil, ExitUsage
+	}
+	dataSet, err := readDataSet(in, opts, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, ExitError
+	}
+	return dataSet, ExitOK
+}
+
+// flags of the input files of a command
+type inputFlags struct {
+	paths  stringList // files, directories or glob patterns
+	report string     // file of the ingestion report
+}
+
+// add flags of input files to a flag set
+func addInputFlags(flags *flag.FlagSet) *inputFlags {
+	in := &inputFlags{}
+	flags.Var(&in.paths, "in", "input data set file, directory or glob pattern, in any supported format, repeatable (required)")
+	flags.StringVar(&in.report, "report", "", "write the ingestion report of the input files as json to a file")
+	return in
+}
+
+// value of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// split a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
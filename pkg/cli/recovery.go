@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/synth"
+)
+
+func init() {
+	register(&Command{
+		Name:        "recovery",
+		Description: "benchmark parameter recovery of the optimizer on synthetic data",
+		Run:         runRecovery,
+	})
+}
+
+// run fits of synthetic data sets across noise levels, data set sizes and optimizer
+// configurations (with and without parameter scaling), and report recovery errors
+func runRecovery(args []string, stdout, stderr io.Writer) int {
+	spec := synth.DefaultRecoverySpec()
+	flags := flag.NewFlagSet("recovery", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	noise := flags.String("noise", formatList(spec.NoiseLevels), "comma-separated multiplicative noise levels (fractions)")
+	points := flags.String("points", formatList([]float64{synth.DefaultNumPoints / 2, synth.DefaultNumPoints}), "comma-separated data set sizes")
+	numSeeds := flags.Int("seeds", spec.NumSeeds, "number of seeds per combination")
+	firstSeed := flags.Int64("first-seed", spec.FirstSeed, "first seed")
+	maxIterations := flags.Int("max-iterations", 0, "maximum number of optimizer iterations (default if zero)")
+	maxRelError := flags.Float64("max-rel-error", spec.MaxRelError, "maximum relative error of a successful fit")
+	tokensCV := flags.Float64("tokens-cv", spec.Base.TokensCV,
+		"coefficient of variation of the token counts of requests, with latencies generated by the dispersion model (none if zero)")
+	modelFlag := addModelFlag(flags)
+	statistic := flags.String("statistic", "", "statistic of the latency distributions to fit: average only, as synthetic data sets have no percentiles (default average)")
+	out := addOutputFlags(flags, FormatText, FormatJSON)
+	asJSON := flags.Bool("json", false, "print results as json (same as -format json)")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if *asJSON {
+		out.format = FormatJSON
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	if spec.NoiseLevels, err = parseFloats(*noise); err != nil {
+		fmt.Fprintln(stderr, "invalid noise levels:", err)
+		return ExitUsage
+	}
+	sizes, err := parseFloats(*points)
+	if err != nil {
+		fmt.Fprintln(stderr, "invalid data set sizes:", err)
+		return ExitUsage
+	}
+	spec.NumPoints = make([]int, len(sizes))
+	for i, s := range sizes {
+		spec.NumPoints[i] = int(s)
+	}
+	stat := config.Statistic(*statistic)
+	if !stat.IsValid() {
+		fmt.Fprintf(stderr, "unknown statistic %q\n", *statistic)
+		return ExitUsage
+	}
+	if _, isPercentile := stat.Quantile(); isPercentile {
+		fmt.Fprintf(stderr, "statistic %q not available, synthetic data sets have averages only\n", *statistic)
+		return ExitUsage
+	}
+	model, err := modelFlag()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if *tokensCV > 0 {
+		spec.Base.TokensCV, spec.Base.Model = *tokensCV, config.ModelDispersion
+	}
+	spec.NumSeeds = *numSeeds
+	spec.FirstSeed = *firstSeed
+	spec.MaxRelError = *maxRelError
+	for i := range spec.Optimizers {
+		spec.Optimizers[i].MaxIterations = *maxIterations
+		spec.Optimizers[i].Statistic = stat
+		spec.Optimizers[i].Model = model
+	}
+
+	results, err := synth.NewRecoveryHarness(spec).Run()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		if format == FormatJSON {
+			return writeJSON(w, results)
+		}
+		_, err := fmt.Fprint(w, synth.RecoveryPrettyPrint(results))
+		return err
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+// parse a comma-separated list of numbers
+func parseFloats(s string) ([]float64, error) {
+	values := []float64{}
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func formatList(values []float64) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(s, ",")
+}
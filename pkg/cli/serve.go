@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/service"
+)
+
+func init() {
+	register(&Command{
+		Name:        "serve",
+		Description: "run the model trainer REST service",
+		Run:         runServe,
+	})
+}
+
+// run the model trainer service until it fails
+func runServe(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", service.DefaultAddress, "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if err := service.NewTrainer().RunOn(*addr); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func init() {
+	register(&Command{
+		Name:        "train",
+		Description: "fit the model parameters to a data set",
+		Run:         runTrain,
+	})
+}
+
+// fit the model parameters to a data set, and write the fitted parameters with the errors of the fit
+// (the json output is the result of the service, and is accepted by -parms of other commands)
+func runTrain(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := addInputFlags(flags)
+	optimizerFlags := addOptimizerFlags(flags)
+	out := addOutputFlags(flags, FormatText, FormatJSON)
+	readerOptions := addReaderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	optimizer, err := optimizerFlags()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	format, err := out.Format()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	dataSet, code := in.read(flags, readerOptions, stderr)
+	if code != ExitOK {
+		return code
+	}
+
+	result, err := optimizer.Optimize(dataSet, core.Model)
+	var validationErr *core.ValidationError
+	if errors.As(err, &validationErr) {
+		return reportInvalid(validationErr, stderr)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "fit failed:", err)
+		return ExitError
+	}
+	err = writeOutput(out.path, stdout, func(w io.Writer) error {
+		if format == FormatJSON {
+			return writeJSON(w, result)
+		}
+		fmt.Fprintf(w, "data set: %s (%d points)\n", dataSet.Name, dataSet.Size())
+		fmt.Fprintf(w, "model: %s\n", optimizer.Model)
+		fmt.Fprintf(w, "init:  %s\n", formatParms(optimizer.InitParms))
+		fmt.Fprintf(w, "parms: %s\n", formatParms(result.OptimizedParms))
+		fmt.Fprintln(w, formatErrors(result.AnalysisResults))
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+// format model parameters as text
+func formatParms(parms *config.ModelParams) string {
+	return fmt.Sprintf("alpha=%v, beta=%v, gamma=%v", parms.Alpha, parms.Beta, parms.Gamma)
+}
+
+// format the errors of a fit or an evaluation as text
+func formatErrors(results *config.AnalysisResults) string {
+	stat := results.Statistic
+	if stat == "" {
+		stat = config.StatisticAverage
+	}
+	return fmt.Sprintf("errors (%s): TTFT=%.4f ms, ITL=%.4f ms, weighted=%.6f",
+		stat, results.AvgErrTTFT, results.AvgErrITL, results.AvgErrWeighted)
+}
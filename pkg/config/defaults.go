@@ -13,6 +13,33 @@ const (
 
 	// default maximum number of iterations allowed in the optimizer
 	DefaultNumberOptimizationIterations = 1000
+
+	// default maximum number of replicas searched by the capacity planner
+	DefaultMaxNumReplicas = 64
+
+	// default cost of a single replica used by the capacity planner
+	DefaultCostPerReplica = 1.0
+
+	// default number of points used to discretize a token count distribution
+	DefaultNumDispersionPoints = 5
+
+	// default coefficient of variation of the inter-token latency of a request,
+	// used to approximate ITL percentiles from the predicted average
+	DefaultITLCoefficientOfVariation = 0.1
+
+	// maximum ratio of ITL to TTFT of a valid data point
+	// (a larger ratio most likely results from mixing time units)
+	DefaultMaxITLToTTFTRatio = 100
+
+	// default number of benchmark points recommended by the experiment designer
+	DefaultNumDesignPoints = 5
+
+	// default number of candidate request rates considered by the experiment designer
+	DefaultNumDesignRateLevels = 10
+
+	// default maximum server utilization of a recommended benchmark point
+	// (latencies close to saturation are too noisy to benchmark reliably)
+	DefaultMaxDesignUtilization = 0.9
 )
 
 // indexes of parameters in the parameters array
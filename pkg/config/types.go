@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 // model parameters, unknown quantities to be estimated
 type ModelParams struct {
 	Alpha float64 `json:"alpha"` // base
@@ -14,12 +16,118 @@ type InputVars struct {
 	OutputTokens float64 `json:"outputTokens"` // average number of output tokens per request
 	MaxBatchSize int     `json:"maxBatchSize"` // maximum batch size
 	MaxNumTokens int     `json:"maxNumTokens"` // maximum number of tokens in a batch
+
+	// optional dispersion of token counts across requests (zero if unknown)
+	InputTokensStdDev  float64 `json:"inputTokensStdDev,omitempty"`  // standard deviation of input tokens per request
+	OutputTokensStdDev float64 `json:"outputTokensStdDev,omitempty"` // standard deviation of output tokens per request
+}
+
+// a class of requests sharing a server, with its own arrival rate and request size
+type RequestClass struct {
+	Name         string  `json:"name"`         // name of the request class (e.g. chat, summarization)
+	RequestRate  float64 `json:"requestRate"`  // request arrival rate of the class (requests/sec)
+	InputTokens  float64 `json:"inputTokens"`  // average number of input tokens per request
+	OutputTokens float64 `json:"outputTokens"` // average number of output tokens per request
 }
 
 // output variables representing an experiment output (performance metrics)
 type OutputVars struct {
 	AvgTTFTTime float64 `json:"avgTTFTTime"` // average time to first token (msec)
 	AvgITLTime  float64 `json:"avgITLTime"`  // average inter-token latency (msec)
+
+	// optional breakdown of TTFT provided by the queueing model (zero if unknown)
+	AvgWaitTime    float64 `json:"avgWaitTime,omitempty"`    // average queueing time (msec)
+	AvgPrefillTime float64 `json:"avgPrefillTime,omitempty"` // average prefill time (msec)
+	Rho            float64 `json:"rho,omitempty"`            // server utilization
+}
+
+// percentiles of a measured latency distribution (zero if unknown)
+type Percentiles struct {
+	P50 float64 `json:"p50,omitempty"`
+	P90 float64 `json:"p90,omitempty"`
+	P95 float64 `json:"p95,omitempty"`
+	P99 float64 `json:"p99,omitempty"`
+}
+
+// get a copy of the percentiles multiplied by a factor (nil if unknown)
+func (p *Percentiles) Scale(factor float64) *Percentiles {
+	if p == nil {
+		return nil
+	}
+	return &Percentiles{
+		P50: p.P50 * factor,
+		P90: p.P90 * factor,
+		P95: p.P95 * factor,
+		P99: p.P99 * factor,
+	}
+}
+
+// statistic of the latency distributions used for fitting and evaluation
+type Statistic string
+
+const (
+	// the avgTTFTTime and avgITLTime fields of the data, as provided
+	StatisticAverage Statistic = "average"
+
+	StatisticP50 Statistic = "p50"
+	StatisticP90 Statistic = "p90"
+	StatisticP95 Statistic = "p95"
+	StatisticP99 Statistic = "p99"
+)
+
+// get the quantile level of a percentile statistic, false if not a percentile
+func (s Statistic) Quantile() (float64, bool) {
+	switch s {
+	case StatisticP50:
+		return 0.50, true
+	case StatisticP90:
+		return 0.90, true
+	case StatisticP95:
+		return 0.95, true
+	case StatisticP99:
+		return 0.99, true
+	}
+	return 0, false
+}
+
+// check if the statistic is known (an empty statistic stands for the average)
+func (s Statistic) IsValid() bool {
+	_, isPercentile := s.Quantile()
+	return isPercentile || s == "" || s == StatisticAverage
+}
+
+// kind of model function used for fitting and evaluation
+type ModelKind string
+
+const (
+	// requests of every data point at the average token counts
+	ModelMean ModelKind = "mean"
+	// token counts of requests dispersed around their averages, by their standard deviations
+	ModelDispersion ModelKind = "dispersion"
+)
+
+// check if the model kind is known (an empty kind stands for the mean model)
+func (k ModelKind) IsValid() bool {
+	return k == "" || k == ModelMean || k == ModelDispersion
+}
+
+// get the value of a percentile statistic, false if not a percentile or unknown
+func (p *Percentiles) Get(s Statistic) (float64, bool) {
+	if p == nil {
+		return 0, false
+	}
+	var v float64
+	switch s {
+	case StatisticP50:
+		v = p.P50
+	case StatisticP90:
+		v = p.P90
+	case StatisticP95:
+		v = p.P95
+	case StatisticP99:
+		v = p.P99
+	}
+	return v, v > 0
 }
 
 // error variables representing the (absolute) difference between predicted and observed output
@@ -35,4 +143,34 @@ type AnalysisResults struct {
 	AvgErrTTFT     float64 `json:"avgErrTTFT"`     // Average error for TTFT time (msec)
 	AvgErrITL      float64 `json:"avgErrITL"`      // Average error for ITL time (msec)
 	AvgErrWeighted float64 `json:"avgErrWeighted"` // Weighted average average error (msec)
+
+	Statistic Statistic `json:"statistic,omitempty"` // statistic of the latency distributions compared
+}
+
+// service level objectives on performance metrics (a zero target means unconstrained)
+type SLO struct {
+	TargetTTFT float64 `json:"targetTTFT"` // maximum average time to first token (msec)
+	TargetITL  float64 `json:"targetITL"`  // maximum average inter-token latency (msec)
+}
+
+// unit of time fields in a data set
+type TimeUnit string
+
+const (
+	UnitSecs  TimeUnit = "s"
+	UnitMSecs TimeUnit = "ms" // default
+	UnitUSecs TimeUnit = "us"
+)
+
+// get the number of milliseconds in one unit of time (an empty unit stands for milliseconds)
+func (u TimeUnit) MSecs() (float64, error) {
+	switch u {
+	case UnitSecs:
+		return 1000, nil
+	case UnitMSecs, "":
+		return 1, nil
+	case UnitUSecs:
+		return 0.001, nil
+	}
+	return 0, fmt.Errorf("unknown time unit %q", u)
 }
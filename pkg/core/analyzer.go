@@ -1,6 +1,8 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/llm-inferno/model-trainer/pkg/config"
 	"github.com/llm-inferno/model-trainer/pkg/utils"
 )
@@ -8,6 +10,12 @@ import (
 // Analyzer performs data analysis using a parametrized model
 type Analyzer struct {
 	Parms *config.ModelParams
+	// statistic of the latency distributions to compare (average if empty)
+	Statistic config.Statistic
+	// kind of model evaluated, from the given model function (mean if empty)
+	Model config.ModelKind
+	// do not print the analyzed data
+	Quiet bool
 }
 
 func NewAnalyzer(parms *config.ModelParams) *Analyzer {
@@ -16,10 +24,20 @@ func NewAnalyzer(parms *config.ModelParams) *Analyzer {
 	}
 }
 
-// Analyze computes the error metrics for the given dataset and model function
+// Analyze computes the error metrics for the given dataset and model function;
+// data points not carrying the chosen statistic are skipped
 func (a *Analyzer) Analyze(dataSet *DataSet, model ModelFunction) *config.AnalysisResults {
-	xData, yData := dataSet.GetInOutVars()
+	stat := a.Statistic
+	if stat == "" {
+		stat = config.StatisticAverage
+	}
+	xData, yData, err := dataSet.GetInOutVarsFor(stat)
+	if err != nil {
+		fmt.Println(err)
+	}
 	errVars := &config.ErrorVars{}
-	LossFunction(a.Parms, xData, yData, model, errVars, true)
-	return utils.CreateAnalysisResultsFromErrorVars(errVars)
+	LossFunction(a.Parms, xData, yData, PercentileModel(ModelOfKind(model, a.Model), stat), errVars, !a.Quiet)
+	analysisResults := utils.CreateAnalysisResultsFromErrorVars(errVars)
+	analysisResults.Statistic = stat
+	return analysisResults
 }
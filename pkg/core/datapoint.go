@@ -1,6 +1,10 @@
 package core
 
-import "github.com/llm-inferno/model-trainer/pkg/config"
+import (
+	"fmt"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
 
 // data point representing a single experiment (benchmark) of input and output variables
 type DataPoint struct {
@@ -16,6 +20,17 @@ type DataPoint struct {
 
 	MaxBatchSize int `json:"maxBatchSize"` // maximum batch size
 	MaxNumTokens int `json:"maxNumTokens"` // maximum number of tokens in a batch
+
+	// optional dispersion of token counts across requests (zero if unknown)
+	InputTokensStdDev  float64 `json:"inputTokensStdDev,omitempty"`  // standard deviation of input tokens per request
+	OutputTokensStdDev float64 `json:"outputTokensStdDev,omitempty"` // standard deviation of output tokens per request
+
+	// optional percentiles of the measured latency distributions (nil if unknown)
+	TTFTPercentiles *config.Percentiles `json:"ttftPercentiles,omitempty"` // percentiles of time to first token (msec)
+	ITLPercentiles  *config.Percentiles `json:"itlPercentiles,omitempty"`  // percentiles of inter-token latency (msec)
+
+	// optional provenance labels, overriding the metadata of the data set
+	Labels *Labels `json:"labels,omitempty"`
 }
 
 // converting from a data point struct to input and output variables
@@ -27,6 +42,9 @@ func (dataPoint *DataPoint) GetInOutVars() (x *config.InputVars, y *config.Outpu
 		OutputTokens: dataPoint.OutputTokens,
 		MaxBatchSize: dataPoint.MaxBatchSize,
 		MaxNumTokens: dataPoint.MaxNumTokens,
+
+		InputTokensStdDev:  dataPoint.InputTokensStdDev,
+		OutputTokensStdDev: dataPoint.OutputTokensStdDev,
 	}
 	y = &config.OutputVars{
 		AvgTTFTTime: dataPoint.AvgTTFTTime,
@@ -35,6 +53,27 @@ func (dataPoint *DataPoint) GetInOutVars() (x *config.InputVars, y *config.Outpu
 	return x, y
 }
 
+// get the output variables of the data point for a given statistic of the latency distributions;
+// an error is returned if the data point does not carry the statistic
+func (dataPoint *DataPoint) GetOutputVars(stat config.Statistic) (*config.OutputVars, error) {
+	_, y := dataPoint.GetInOutVars()
+	if _, isPercentile := stat.Quantile(); !isPercentile {
+		if !stat.IsValid() {
+			return nil, fmt.Errorf("unknown statistic %q", stat)
+		}
+		return y, nil
+	}
+	ttft, okTTFT := dataPoint.TTFTPercentiles.Get(stat)
+	itl, okITL := dataPoint.ITLPercentiles.Get(stat)
+	if !okTTFT || !okITL {
+		return nil, fmt.Errorf("missing %s of TTFT or ITL", stat)
+	}
+	return &config.OutputVars{
+		AvgTTFTTime: ttft,
+		AvgITLTime:  itl,
+	}, nil
+}
+
 // fix any missing or invalid fields in the data point
 func (dataPoint *DataPoint) Fix() {
 	if dataPoint.MaxBatchSize <= 0 {
@@ -49,9 +88,17 @@ func (dataPoint *DataPoint) Fix() {
 }
 
 // convert time fields from seconds to milliseconds
+// (not idempotent, data sets keep track of their units, see DataSet.ConvertTo)
 func (dataPoint *DataPoint) ToMSecs() {
-	dataPoint.AvgTTFTTime *= 1000
-	dataPoint.AvgITLTime *= 1000
-	dataPoint.AvgWaitTime *= 1000
-	dataPoint.AvgPrefillTime *= 1000
+	dataPoint.scaleTimes(1000)
+}
+
+// multiply time fields by a factor
+func (dataPoint *DataPoint) scaleTimes(factor float64) {
+	dataPoint.AvgTTFTTime *= factor
+	dataPoint.AvgITLTime *= factor
+	dataPoint.AvgWaitTime *= factor
+	dataPoint.AvgPrefillTime *= factor
+	dataPoint.TTFTPercentiles = dataPoint.TTFTPercentiles.Scale(factor)
+	dataPoint.ITLPercentiles = dataPoint.ITLPercentiles.Scale(factor)
 }
@@ -155,6 +155,33 @@ func TestDataPoint_GetInOutVars(t *testing.T) {
 				AvgITLTime:  0.0,
 			},
 		},
+		{
+			name: "data point with token dispersion",
+			dataPoint: DataPoint{
+				RequestRate:        10.0,
+				InputTokens:        1000.0,
+				OutputTokens:       200.0,
+				AvgITLTime:         9.0,
+				AvgTTFTTime:        40.0,
+				MaxBatchSize:       256,
+				MaxNumTokens:       8192,
+				InputTokensStdDev:  300.0,
+				OutputTokensStdDev: 50.0,
+			},
+			wantX: &config.InputVars{
+				RequestRate:        10.0,
+				InputTokens:        1000.0,
+				OutputTokens:       200.0,
+				MaxBatchSize:       256,
+				MaxNumTokens:       8192,
+				InputTokensStdDev:  300.0,
+				OutputTokensStdDev: 50.0,
+			},
+			wantY: &config.OutputVars{
+				AvgTTFTTime: 40.0,
+				AvgITLTime:  9.0,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -177,6 +204,12 @@ func TestDataPoint_GetInOutVars(t *testing.T) {
 			if gotX.MaxNumTokens != tt.wantX.MaxNumTokens {
 				t.Errorf("InputVars.MaxNumTokens = %v, want %v", gotX.MaxNumTokens, tt.wantX.MaxNumTokens)
 			}
+			if gotX.InputTokensStdDev != tt.wantX.InputTokensStdDev {
+				t.Errorf("InputVars.InputTokensStdDev = %v, want %v", gotX.InputTokensStdDev, tt.wantX.InputTokensStdDev)
+			}
+			if gotX.OutputTokensStdDev != tt.wantX.OutputTokensStdDev {
+				t.Errorf("InputVars.OutputTokensStdDev = %v, want %v", gotX.OutputTokensStdDev, tt.wantX.OutputTokensStdDev)
+			}
 
 			// Check OutputVars
 			if gotY.AvgTTFTTime != tt.wantY.AvgTTFTTime {
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/llm-inferno/model-trainer/pkg/config"
@@ -9,14 +10,61 @@ import (
 
 // a collection of data points representing a data set
 type DataSet struct {
-	Name string      `json:"name"`
-	Data []DataPoint `json:"data"`
+	Name  string          `json:"name"`
+	Units config.TimeUnit `json:"units,omitempty"` // unit of time fields (milliseconds if empty)
+	Data  []DataPoint     `json:"data"`
+
+	// optional provenance metadata common to all data points (see LabelsOf)
+	Metadata *Labels `json:"metadata,omitempty"`
 }
 
 func NewDataSet(name string) *DataSet {
 	return &DataSet{
-		Name: name,
-		Data: []DataPoint{}}
+		Name:  name,
+		Units: config.UnitMSecs,
+		Data:  []DataPoint{}}
+}
+
+// unmarshal a data set, normalizing time fields to milliseconds
+func (dataSet *DataSet) UnmarshalJSON(data []byte) error {
+	type plainDataSet DataSet
+	if err := json.Unmarshal(data, (*plainDataSet)(dataSet)); err != nil {
+		return err
+	}
+	return dataSet.ConvertTo(config.UnitMSecs)
+}
+
+// convert time fields in the data set to a given unit; converting to the current unit is a no-op
+func (dataSet *DataSet) ConvertTo(unit config.TimeUnit) error {
+	from, err := dataSet.Units.MSecs()
+	if err != nil {
+		return err
+	}
+	to, err := unit.MSecs()
+	if err != nil {
+		return err
+	}
+	if from != to {
+		for i := range dataSet.Data {
+			dataSet.Data[i].scaleTimes(from / to)
+		}
+	}
+	dataSet.Units = unit
+	return nil
+}
+
+// get a copy of the data set with time fields in a given unit, e.g. for export
+func (dataSet *DataSet) CopyIn(unit config.TimeUnit) (*DataSet, error) {
+	copied := &DataSet{
+		Name:     dataSet.Name,
+		Units:    dataSet.Units,
+		Data:     append([]DataPoint{}, dataSet.Data...),
+		Metadata: dataSet.Metadata,
+	}
+	if err := copied.ConvertTo(unit); err != nil {
+		return nil, err
+	}
+	return copied, nil
 }
 
 // append a data point to the data set
@@ -34,9 +82,36 @@ func (dataSet *DataSet) GetInOutVars() (xData []*config.InputVars, yData []*conf
 	return xData, yData
 }
 
-// merge another data set into the current data set
-func (dataSet *DataSet) Merge(other *DataSet) {
-	dataSet.Data = append(dataSet.Data, other.Data...)
+// converting from a data set struct to input and output variable arrays for a given statistic
+// of the latency distributions; data points not carrying the statistic are skipped
+func (dataSet *DataSet) GetInOutVarsFor(stat config.Statistic) (xData []*config.InputVars, yData []*config.OutputVars, err error) {
+	if !stat.IsValid() {
+		return nil, nil, fmt.Errorf("unknown statistic %q", stat)
+	}
+	for _, dp := range dataSet.Data {
+		y, err := dp.GetOutputVars(stat)
+		if err != nil {
+			continue
+		}
+		x, _ := dp.GetInOutVars()
+		xData = append(xData, x)
+		yData = append(yData, y)
+	}
+	return xData, yData, nil
+}
+
+// merge another data set into the current data set, converting its time fields to the units of the current data set;
+// metadata not shared by both data sets is pushed down to the labels of the data points
+func (dataSet *DataSet) Merge(other *DataSet) error {
+	converted, err := other.CopyIn(dataSet.Units)
+	if err != nil {
+		return fmt.Errorf("cannot merge data set %s: %w", other.Name, err)
+	}
+	common := dataSet.Metadata.Common(other.Metadata)
+	dataSet.pushDownMetadata(common)
+	converted.pushDownMetadata(common)
+	dataSet.Data = append(dataSet.Data, converted.Data...)
+	return nil
 }
 
 // get the size of the data set
@@ -51,11 +126,9 @@ func (dataSet *DataSet) Fix() {
 	}
 }
 
-// convert time units in the data set to milliseconds
-func (dataSet *DataSet) ToMSecs() {
-	for i := range dataSet.Data {
-		dataSet.Data[i].ToMSecs()
-	}
+// convert time units in the data set to milliseconds (idempotent)
+func (dataSet *DataSet) ToMSecs() error {
+	return dataSet.ConvertTo(config.UnitMSecs)
 }
 
 // pretty print a data set
@@ -1,8 +1,11 @@
 package core
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
 )
 
 func TestNewDataSet(t *testing.T) {
@@ -644,11 +647,17 @@ func TestDataSet_ToMSecs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ds := NewDataSet("test")
+			ds.Units = config.UnitSecs
 			for i := range tt.dataPoints {
 				ds.AppendDataPoint(&tt.dataPoints[i])
 			}
 
-			ds.ToMSecs()
+			if err := ds.ToMSecs(); err != nil {
+				t.Fatalf("ToMSecs() failed: %v", err)
+			}
+			if ds.Units != config.UnitMSecs {
+				t.Errorf("Units = %v, want %v", ds.Units, config.UnitMSecs)
+			}
 
 			if tt.validateFn != nil {
 				tt.validateFn(t, ds)
@@ -657,6 +666,135 @@ func TestDataSet_ToMSecs(t *testing.T) {
 	}
 }
 
+func TestDataSet_ConvertTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		units       config.TimeUnit
+		target      config.TimeUnit
+		ttft        float64
+		wantTTFT    float64
+		expectError bool
+	}{
+		{name: "seconds to milliseconds", units: config.UnitSecs, target: config.UnitMSecs, ttft: 1.5, wantTTFT: 1500.0},
+		{name: "milliseconds to seconds", units: config.UnitMSecs, target: config.UnitSecs, ttft: 1500.0, wantTTFT: 1.5},
+		{name: "microseconds to milliseconds", units: config.UnitUSecs, target: config.UnitMSecs, ttft: 1500.0, wantTTFT: 1.5},
+		{name: "empty units are milliseconds", units: "", target: config.UnitMSecs, ttft: 20.0, wantTTFT: 20.0},
+		{name: "unknown source unit", units: "min", target: config.UnitMSecs, ttft: 1.0, expectError: true},
+		{name: "unknown target unit", units: config.UnitSecs, target: "h", ttft: 1.0, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &DataSet{Name: "test", Units: tt.units}
+			ds.AppendDataPoint(&DataPoint{
+				AvgTTFTTime:     tt.ttft,
+				TTFTPercentiles: &config.Percentiles{P90: tt.ttft},
+			})
+
+			err := ds.ConvertTo(tt.target)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				if ds.Data[0].AvgTTFTTime != tt.ttft {
+					t.Errorf("AvgTTFTTime = %v, want unchanged %v", ds.Data[0].AvgTTFTTime, tt.ttft)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !almostEqual(ds.Data[0].AvgTTFTTime, tt.wantTTFT) {
+				t.Errorf("AvgTTFTTime = %v, want %v", ds.Data[0].AvgTTFTTime, tt.wantTTFT)
+			}
+			if !almostEqual(ds.Data[0].TTFTPercentiles.P90, tt.wantTTFT) {
+				t.Errorf("TTFT P90 = %v, want %v", ds.Data[0].TTFTPercentiles.P90, tt.wantTTFT)
+			}
+
+			// converting again is a no-op
+			if err := ds.ConvertTo(tt.target); err != nil {
+				t.Fatalf("second ConvertTo() failed: %v", err)
+			}
+			if !almostEqual(ds.Data[0].AvgTTFTTime, tt.wantTTFT) {
+				t.Errorf("after second conversion: AvgTTFTTime = %v, want %v", ds.Data[0].AvgTTFTTime, tt.wantTTFT)
+			}
+		})
+	}
+}
+
+func TestDataSet_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantTTFT    float64
+		expectError bool
+	}{
+		{name: "seconds normalized", json: `{"name":"s","units":"s","data":[{"avgTTFTTime":0.02,"avgITLTime":0.008}]}`, wantTTFT: 20.0},
+		{name: "milliseconds unchanged", json: `{"name":"ms","units":"ms","data":[{"avgTTFTTime":20,"avgITLTime":8}]}`, wantTTFT: 20.0},
+		{name: "missing units are milliseconds", json: `{"name":"none","data":[{"avgTTFTTime":20,"avgITLTime":8}]}`, wantTTFT: 20.0},
+		{name: "unknown units", json: `{"name":"bad","units":"minutes","data":[]}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ds DataSet
+			err := json.Unmarshal([]byte(tt.json), &ds)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ds.Units != config.UnitMSecs {
+				t.Errorf("Units = %v, want %v", ds.Units, config.UnitMSecs)
+			}
+			if !almostEqual(ds.Data[0].AvgTTFTTime, tt.wantTTFT) {
+				t.Errorf("AvgTTFTTime = %v, want %v", ds.Data[0].AvgTTFTTime, tt.wantTTFT)
+			}
+		})
+	}
+}
+
+func TestDataSet_MergeUnits(t *testing.T) {
+	msecs := NewDataSet("msecs")
+	msecs.AppendDataPoint(&DataPoint{AvgTTFTTime: 20.0})
+
+	secs := &DataSet{Name: "secs", Units: config.UnitSecs}
+	secs.AppendDataPoint(&DataPoint{AvgTTFTTime: 0.03})
+
+	if err := msecs.Merge(secs); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+	if !almostEqual(msecs.Data[1].AvgTTFTTime, 30.0) {
+		t.Errorf("merged AvgTTFTTime = %v, want 30.0", msecs.Data[1].AvgTTFTTime)
+	}
+	if secs.Data[0].AvgTTFTTime != 0.03 || secs.Units != config.UnitSecs {
+		t.Error("merged data set must not be modified")
+	}
+
+	bad := &DataSet{Name: "bad", Units: "minutes", Data: []DataPoint{{AvgTTFTTime: 1.0}}}
+	if err := msecs.Merge(bad); err == nil {
+		t.Error("expected error merging unknown units, got nil")
+	}
+	if msecs.Size() != 2 {
+		t.Errorf("Size() = %d, want 2 after failed merge", msecs.Size())
+	}
+
+	exported, err := msecs.CopyIn(config.UnitSecs)
+	if err != nil {
+		t.Fatalf("CopyIn() failed: %v", err)
+	}
+	if !almostEqual(exported.Data[0].AvgTTFTTime, 0.02) || exported.Units != config.UnitSecs {
+		t.Errorf("exported AvgTTFTTime = %v %s, want 0.02 s", exported.Data[0].AvgTTFTTime, exported.Units)
+	}
+	if msecs.Data[0].AvgTTFTTime != 20.0 {
+		t.Error("exported data set must be a copy")
+	}
+}
+
 func TestDataSet_DataSetPrettyPrint(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -818,6 +956,7 @@ func TestDataSet_ChainedOperations(t *testing.T) {
 	t.Run("append, merge, fix, and convert", func(t *testing.T) {
 		// Create first dataset
 		ds1 := NewDataSet("dataset1")
+		ds1.Units = config.UnitSecs
 		ds1.AppendDataPoint(&DataPoint{
 			RequestRate:    10.0,
 			InputTokens:    100.0,
@@ -828,6 +967,7 @@ func TestDataSet_ChainedOperations(t *testing.T) {
 
 		// Create second dataset
 		ds2 := NewDataSet("dataset2")
+		ds2.Units = config.UnitSecs
 		ds2.AppendDataPoint(&DataPoint{
 			RequestRate:    20.0,
 			InputTokens:    200.0,
@@ -0,0 +1,362 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/utils"
+	"gonum.org/v1/gonum/mat"
+)
+
+// experiment design specification: the candidate benchmark settings to choose from,
+// for a given server configuration
+type DesignSpec struct {
+	NumPoints      int       `json:"numPoints"`      // number of benchmark points to recommend
+	MinRequestRate float64   `json:"minRequestRate"` // minimum candidate request rate (requests/sec)
+	MaxRequestRate float64   `json:"maxRequestRate"` // maximum candidate request rate (requests/sec)
+	NumRateLevels  int       `json:"numRateLevels"`  // number of evenly spaced candidate request rates
+	InputTokens    []float64 `json:"inputTokens"`    // candidate average numbers of input tokens (default extremes in data set)
+	OutputTokens   []float64 `json:"outputTokens"`   // candidate average numbers of output tokens (default extremes in data set)
+	MaxBatchSize   int       `json:"maxBatchSize"`   // maximum batch size of the server
+	MaxNumTokens   int       `json:"maxNumTokens"`   // maximum number of tokens in a batch of the server
+
+	// maximum server utilization of a recommended point
+	MaxUtilization float64 `json:"maxUtilization"`
+	// relative noise of measured latencies (estimated from the fit residuals if zero)
+	NoiseLevel float64 `json:"noiseLevel,omitempty"`
+}
+
+// a recommended benchmark point
+type DesignPoint struct {
+	Rank         int                `json:"rank"`         // order of selection (1 is the most informative)
+	RequestRate  float64            `json:"requestRate"`  // request rate (requests/sec)
+	InputTokens  float64            `json:"inputTokens"`  // average number of input tokens
+	OutputTokens float64            `json:"outputTokens"` // average number of output tokens
+	Gain         float64            `json:"gain"`         // increase of the log-determinant of the information matrix
+	Predicted    *config.OutputVars `json:"predicted"`    // predicted performance metrics
+}
+
+// recommended benchmark points and their effect on the uncertainty of the parameters
+type DesignResult struct {
+	Points       []*DesignPoint      `json:"points"`       // recommended points, by input tokens, output tokens and rate
+	MaxBatchSize int                 `json:"maxBatchSize"` // maximum batch size of the server
+	MaxNumTokens int                 `json:"maxNumTokens"` // maximum number of tokens in a batch of the server
+	NoiseLevel   float64             `json:"noiseLevel"`   // relative noise of measured latencies assumed
+	CurrentErr   *config.ModelParams `json:"currentErr"`   // relative standard errors of parameters with the current data
+	PredictedErr *config.ModelParams `json:"predictedErr"` // relative standard errors of parameters after running the points
+}
+
+// a GuideLLM sweep of constant request rates, for a given request size
+type GuideLLMRun struct {
+	PromptTokens int       `json:"promptTokens"`
+	OutputTokens int       `json:"outputTokens"`
+	Rates        []float64 `json:"rates"`
+}
+
+// Designer recommends benchmark points that most reduce the uncertainty of fitted parameters
+type Designer struct {
+	Parms *config.ModelParams
+}
+
+func NewDesigner(parms *config.ModelParams) *Designer {
+	return &Designer{
+		Parms: parms,
+	}
+}
+
+// relative step of parameters used to compute sensitivities by finite differences
+const designStep = 1e-4
+
+// regularization of the information matrix, keeping it invertible without data
+const designRidge = 1e-9
+
+// fix any missing fields in the design specification, using the data set for defaults
+func (spec *DesignSpec) Fix(dataSet *DataSet) {
+	if spec.NumPoints <= 0 {
+		spec.NumPoints = config.DefaultNumDesignPoints
+	}
+	if spec.NumRateLevels <= 0 {
+		spec.NumRateLevels = config.DefaultNumDesignRateLevels
+	}
+	if spec.MaxUtilization <= 0 {
+		spec.MaxUtilization = config.DefaultMaxDesignUtilization
+	}
+	// ranges of the data set
+	minRate, maxRate := math.Inf(1), 0.0
+	minIn, maxIn := math.Inf(1), 0.0
+	minOut, maxOut := math.Inf(1), 0.0
+	for _, dp := range dataSet.Data {
+		minRate, maxRate = math.Min(minRate, dp.RequestRate), math.Max(maxRate, dp.RequestRate)
+		minIn, maxIn = math.Min(minIn, dp.InputTokens), math.Max(maxIn, dp.InputTokens)
+		minOut, maxOut = math.Min(minOut, dp.OutputTokens), math.Max(maxOut, dp.OutputTokens)
+	}
+	if spec.MinRequestRate <= 0 && !math.IsInf(minRate, 1) {
+		spec.MinRequestRate = minRate
+	}
+	if spec.MaxRequestRate <= 0 {
+		spec.MaxRequestRate = maxRate
+	}
+	if len(spec.InputTokens) == 0 && dataSet.Size() > 0 {
+		spec.InputTokens = levels(minIn, maxIn, 2)
+	}
+	if len(spec.OutputTokens) == 0 && dataSet.Size() > 0 {
+		spec.OutputTokens = levels(minOut, maxOut, 2)
+	}
+	if spec.MaxBatchSize <= 0 {
+		spec.MaxBatchSize = config.DefaultMaxBatchSize
+		if dataSet.Size() > 0 && dataSet.Data[0].MaxBatchSize > 0 {
+			spec.MaxBatchSize = dataSet.Data[0].MaxBatchSize
+		}
+	}
+	if spec.MaxNumTokens <= 0 {
+		spec.MaxNumTokens = config.DefaultMaxNumTokens
+		if dataSet.Size() > 0 && dataSet.Data[0].MaxNumTokens > 0 {
+			spec.MaxNumTokens = dataSet.Data[0].MaxNumTokens
+		}
+	}
+}
+
+// Recommend selects benchmark points greedily for D-optimality: each point maximizes the
+// determinant of the Fisher information matrix of the (log) parameters, accumulated over the
+// current data set and the points already selected. Sensitivities are taken of the relative
+// TTFT and ITL errors minimized by the optimizer, so the design is scale free. Candidates
+// that the model cannot evaluate, or beyond the maximum utilization, are not considered.
+func (d *Designer) Recommend(dataSet *DataSet, spec *DesignSpec, model ModelFunction) (*DesignResult, error) {
+	if !utils.CheckParmsValid(d.Parms) {
+		return nil, fmt.Errorf("invalid model parameters")
+	}
+	spec.Fix(dataSet)
+	if spec.MinRequestRate <= 0 || spec.MaxRequestRate < spec.MinRequestRate {
+		return nil, fmt.Errorf("invalid range of request rates [%v, %v]", spec.MinRequestRate, spec.MaxRequestRate)
+	}
+	if len(spec.InputTokens) == 0 || len(spec.OutputTokens) == 0 {
+		return nil, fmt.Errorf("missing candidate token counts")
+	}
+
+	// information of the current data set
+	info := mat.NewSymDense(3, nil)
+	sumSq, numResiduals := 0.0, 0
+	xData, yData := dataSet.GetInOutVars()
+	for i, x := range xData {
+		pred, rows, err := d.sensitivities(x, model)
+		if err != nil {
+			continue
+		}
+		addInformation(info, rows)
+		if yData[i].AvgTTFTTime > 0 && yData[i].AvgITLTime > 0 {
+			sumSq += math.Pow(pred.AvgTTFTTime/yData[i].AvgTTFTTime-1, 2) +
+				math.Pow(pred.AvgITLTime/yData[i].AvgITLTime-1, 2)
+			numResiduals += 2
+		}
+	}
+	noise := spec.NoiseLevel
+	if noise <= 0 {
+		if numResiduals <= 3 {
+			return nil, fmt.Errorf("too few data points to estimate the noise level, specify one")
+		}
+		noise = math.Sqrt(sumSq / float64(numResiduals-3))
+	}
+	result := &DesignResult{
+		Points:       []*DesignPoint{},
+		MaxBatchSize: spec.MaxBatchSize,
+		MaxNumTokens: spec.MaxNumTokens,
+		NoiseLevel:   noise,
+		CurrentErr:   relativeErrors(info, noise),
+	}
+
+	// candidate points
+	type candidate struct {
+		point *DesignPoint
+		rows  [][]float64
+	}
+	candidates := []*candidate{}
+	for _, in := range spec.InputTokens {
+		for _, out := range spec.OutputTokens {
+			for _, rate := range levels(spec.MinRequestRate, spec.MaxRequestRate, spec.NumRateLevels) {
+				x := &config.InputVars{
+					RequestRate:  rate,
+					InputTokens:  in,
+					OutputTokens: out,
+					MaxBatchSize: spec.MaxBatchSize,
+					MaxNumTokens: spec.MaxNumTokens,
+				}
+				pred, rows, err := d.sensitivities(x, model)
+				if err != nil || pred.Rho > spec.MaxUtilization {
+					continue
+				}
+				candidates = append(candidates, &candidate{
+					point: &DesignPoint{RequestRate: rate, InputTokens: in, OutputTokens: out, Predicted: pred},
+					rows:  rows,
+				})
+			}
+		}
+	}
+
+	// greedy selection
+	baseLogDet := logDet(info)
+	for rank := 1; rank <= spec.NumPoints && len(candidates) > 0; rank++ {
+		best, bestLogDet := -1, math.Inf(-1)
+		for k, c := range candidates {
+			trial := mat.NewSymDense(3, nil)
+			trial.CopySym(info)
+			addInformation(trial, c.rows)
+			if v := logDet(trial); v > bestLogDet {
+				best, bestLogDet = k, v
+			}
+		}
+		c := candidates[best]
+		addInformation(info, c.rows)
+		c.point.Rank = rank
+		c.point.Gain = bestLogDet - baseLogDet
+		baseLogDet = bestLogDet
+		result.Points = append(result.Points, c.point)
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	if len(result.Points) == 0 {
+		return nil, fmt.Errorf("no candidate point below utilization %v", spec.MaxUtilization)
+	}
+	result.PredictedErr = relativeErrors(info, noise)
+
+	sort.SliceStable(result.Points, func(i, j int) bool {
+		a, b := result.Points[i], result.Points[j]
+		if a.InputTokens != b.InputTokens {
+			return a.InputTokens < b.InputTokens
+		}
+		if a.OutputTokens != b.OutputTokens {
+			return a.OutputTokens < b.OutputTokens
+		}
+		return a.RequestRate < b.RequestRate
+	})
+	return result, nil
+}
+
+// get the prediction at a point and the sensitivities of the log TTFT and log ITL
+// to the log parameters (two rows of the Jacobian), by central differences
+func (d *Designer) sensitivities(x *config.InputVars, model ModelFunction) (*config.OutputVars, [][]float64, error) {
+	pred, err := model(x, d.Parms)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pred.AvgTTFTTime <= 0 || pred.AvgITLTime <= 0 {
+		return nil, nil, fmt.Errorf("non-positive prediction")
+	}
+	rows := [][]float64{make([]float64, 3), make([]float64, 3)}
+	parms := utils.CreateParmsSliceFromModelParams(d.Parms)
+	for k := range parms {
+		var y [2]*config.OutputVars
+		for s, sign := range []float64{1, -1} {
+			perturbed := append([]float64{}, parms...)
+			perturbed[k] *= math.Exp(sign * designStep)
+			if y[s], err = model(x, utils.CreateModelParamsFromParmsSlice(perturbed)); err != nil {
+				return nil, nil, err
+			}
+			if y[s].AvgTTFTTime <= 0 || y[s].AvgITLTime <= 0 {
+				return nil, nil, fmt.Errorf("non-positive prediction")
+			}
+		}
+		rows[0][k] = math.Log(y[0].AvgTTFTTime/y[1].AvgTTFTTime) / (2 * designStep)
+		rows[1][k] = math.Log(y[0].AvgITLTime/y[1].AvgITLTime) / (2 * designStep)
+	}
+	return pred, rows, nil
+}
+
+// add the information of rows of the Jacobian to an information matrix
+func addInformation(info *mat.SymDense, rows [][]float64) {
+	for _, row := range rows {
+		info.SymRankOne(info, 1, mat.NewVecDense(len(row), row))
+	}
+}
+
+// log-determinant of a regularized information matrix
+func logDet(info *mat.SymDense) float64 {
+	reg := mat.NewSymDense(3, nil)
+	reg.CopySym(info)
+	for k := 0; k < 3; k++ {
+		reg.SetSym(k, k, reg.At(k, k)+designRidge)
+	}
+	v, _ := mat.LogDet(reg)
+	return v
+}
+
+// relative standard errors of parameters, given the information matrix and the noise level
+// (standard errors of log parameters); zero information gives a (large) finite error
+func relativeErrors(info *mat.SymDense, noise float64) *config.ModelParams {
+	reg := mat.NewSymDense(3, nil)
+	reg.CopySym(info)
+	for k := 0; k < 3; k++ {
+		reg.SetSym(k, k, reg.At(k, k)+designRidge)
+	}
+	var cov mat.Dense
+	if err := cov.Inverse(reg); err != nil {
+		return nil
+	}
+	errs := make([]float64, 3)
+	for k := range errs {
+		errs[k] = noise * math.Sqrt(math.Max(cov.At(k, k), 0))
+	}
+	return utils.CreateModelParamsFromParmsSlice(errs)
+}
+
+// evenly spaced values (e.g. request rates), including both ends
+func levels(lo, hi float64, n int) []float64 {
+	if n <= 1 || hi == lo {
+		return []float64{hi}
+	}
+	levels := make([]float64, n)
+	for i := range levels {
+		levels[i] = lo + float64(i)*(hi-lo)/float64(n-1)
+	}
+	return levels
+}
+
+// group the recommended points into GuideLLM sweeps, one per request size
+func (result *DesignResult) GuideLLMRuns() []*GuideLLMRun {
+	runs := []*GuideLLMRun{}
+	var last *GuideLLMRun
+	for _, p := range result.Points {
+		in, out := int(math.Round(p.InputTokens)), int(math.Round(p.OutputTokens))
+		if last == nil || last.PromptTokens != in || last.OutputTokens != out {
+			last = &GuideLLMRun{PromptTokens: in, OutputTokens: out}
+			runs = append(runs, last)
+		}
+		last.Rates = append(last.Rates, p.RequestRate)
+	}
+	return runs
+}
+
+// get the GuideLLM command line running the sweep against a target server
+func (run *GuideLLMRun) Command(target string) string {
+	rates := make([]string, len(run.Rates))
+	for i, r := range run.Rates {
+		rates[i] = strconv.FormatFloat(r, 'f', 2, 64)
+	}
+	return fmt.Sprintf("guidellm benchmark --target %s --rate-type constant --rate %s --data prompt_tokens=%d,output_tokens=%d",
+		target, strings.Join(rates, ","), run.PromptTokens, run.OutputTokens)
+}
+
+// pretty print the recommended points and the effect on parameter uncertainty
+func DesignPrettyPrint(result *DesignResult) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "maxBatchSize=%d, maxNumTokens=%d, noise=%.4f\n\n", result.MaxBatchSize, result.MaxNumTokens, result.NoiseLevel)
+	fmt.Fprintf(&b, " rank \t rps \t inToken \t outToken \t gain \t TTFTPred \t ITLPred \t rho\n")
+	fmt.Fprintln(&b)
+	for _, p := range result.Points {
+		fmt.Fprintf(&b, " %4d \t %6.2f \t %8.2f \t %8.2f \t %6.3f \t %8.2f \t %7.2f \t %5.3f\n",
+			p.Rank, p.RequestRate, p.InputTokens, p.OutputTokens, p.Gain,
+			p.Predicted.AvgTTFTTime, p.Predicted.AvgITLTime, p.Predicted.Rho)
+	}
+	fmt.Fprintln(&b)
+	printErr := func(name string, e *config.ModelParams) {
+		if e != nil {
+			fmt.Fprintf(&b, "%s relative std errors: alpha=%.4f, beta=%.4f, gamma=%.4f\n", name, e.Alpha, e.Beta, e.Gamma)
+		}
+	}
+	printErr("current  ", result.CurrentErr)
+	printErr("predicted", result.PredictedErr)
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// mockDesignModel: queueing delay grows with utilization rate/10, unstable at saturation
+func mockDesignModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	rho := x.RequestRate / 10
+	if rho >= 1 {
+		return nil, fmt.Errorf("unstable")
+	}
+	return &config.OutputVars{
+		AvgTTFTTime: params.Alpha + params.Beta*x.InputTokens/(1-rho),
+		AvgITLTime:  params.Alpha + params.Gamma*x.OutputTokens,
+		Rho:         rho,
+	}, nil
+}
+
+// data points on the flat part of the latency curve, with a little noise
+func newDesignDataSet(parms *config.ModelParams) *DataSet {
+	ds := NewDataSet("design")
+	for i, rate := range []float64{0.5, 1.0, 1.5, 2.0} {
+		x := &config.InputVars{RequestRate: rate, InputTokens: 500, OutputTokens: 100, MaxBatchSize: 64, MaxNumTokens: 4096}
+		y, _ := mockDesignModel(x, parms)
+		f := 1 + 0.01*float64(i%2*2-1)
+		ds.AppendDataPoint(&DataPoint{RequestRate: rate, InputTokens: 500, OutputTokens: 100,
+			AvgTTFTTime: y.AvgTTFTTime * f, AvgITLTime: y.AvgITLTime / f, MaxBatchSize: 64, MaxNumTokens: 4096})
+	}
+	return ds
+}
+
+func TestDesigner_Recommend(t *testing.T) {
+	parms := &config.ModelParams{Alpha: 5.0, Beta: 0.05, Gamma: 0.1}
+	tests := []struct {
+		name        string
+		parms       *config.ModelParams
+		dataSet     *DataSet
+		spec        *DesignSpec
+		expectError bool
+		validateFn  func(t *testing.T, result *DesignResult)
+	}{
+		{
+			name:    "reduces uncertainty within utilization",
+			parms:   parms,
+			dataSet: newDesignDataSet(parms),
+			spec: &DesignSpec{NumPoints: 4, MaxRequestRate: 9.5, InputTokens: []float64{500, 2000},
+				OutputTokens: []float64{100, 400}},
+			validateFn: func(t *testing.T, result *DesignResult) {
+				if len(result.Points) != 4 {
+					t.Fatalf("got %d points, want 4", len(result.Points))
+				}
+				if result.MaxBatchSize != 64 || result.MaxNumTokens != 4096 {
+					t.Errorf("batch settings %d/%d not taken from data set", result.MaxBatchSize, result.MaxNumTokens)
+				}
+				for i, p := range result.Points {
+					if p.Predicted.Rho > config.DefaultMaxDesignUtilization {
+						t.Errorf("point %d: utilization %v beyond maximum", i, p.Predicted.Rho)
+					}
+					if p.Rank == 1 && p.RequestRate <= 2.0 {
+						t.Errorf("most informative point at rate %v, on the flat part of the curve", p.RequestRate)
+					}
+					if i > 0 && p.InputTokens == result.Points[i-1].InputTokens &&
+						p.OutputTokens == result.Points[i-1].OutputTokens && p.RequestRate < result.Points[i-1].RequestRate {
+						t.Error("points not sorted by request size and rate")
+					}
+				}
+				cur, pred := result.CurrentErr, result.PredictedErr
+				if pred.Alpha >= cur.Alpha || pred.Beta >= cur.Beta || pred.Gamma >= cur.Gamma {
+					t.Errorf("predicted errors %+v not below current %+v", *pred, *cur)
+				}
+				if result.NoiseLevel <= 0 || result.NoiseLevel > 0.05 {
+					t.Errorf("estimated noise level %v, want about 0.01", result.NoiseLevel)
+				}
+			},
+		},
+		{
+			name:    "empty data set with given noise level",
+			parms:   parms,
+			dataSet: NewDataSet("empty"),
+			spec: &DesignSpec{NumPoints: 3, MinRequestRate: 1, MaxRequestRate: 8, InputTokens: []float64{1000},
+				OutputTokens: []float64{200, 800}, NoiseLevel: 0.02},
+			validateFn: func(t *testing.T, result *DesignResult) {
+				if len(result.Points) != 3 || result.NoiseLevel != 0.02 {
+					t.Errorf("got %d points, noise %v", len(result.Points), result.NoiseLevel)
+				}
+				if result.PredictedErr.Alpha > 1 || result.PredictedErr.Beta > 1 || result.PredictedErr.Gamma > 1 {
+					t.Errorf("three points should identify the parameters, errors %+v", *result.PredictedErr)
+				}
+			},
+		},
+		{
+			name:        "noise level not estimable",
+			parms:       parms,
+			dataSet:     NewDataSet("empty"),
+			spec:        &DesignSpec{MinRequestRate: 1, MaxRequestRate: 8, InputTokens: []float64{1000}, OutputTokens: []float64{200}},
+			expectError: true,
+		},
+		{
+			name:        "no candidate below utilization",
+			parms:       parms,
+			dataSet:     newDesignDataSet(parms),
+			spec:        &DesignSpec{MinRequestRate: 5, MaxRequestRate: 9, MaxUtilization: 0.2},
+			expectError: true,
+		},
+		{
+			name:        "invalid parameters",
+			parms:       &config.ModelParams{Alpha: -1},
+			dataSet:     newDesignDataSet(parms),
+			spec:        &DesignSpec{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewDesigner(tt.parms).Recommend(tt.dataSet, tt.spec, mockDesignModel)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, result)
+			}
+		})
+	}
+}
+
+func TestDesignResult_GuideLLMRuns(t *testing.T) {
+	result := &DesignResult{Points: []*DesignPoint{
+		{RequestRate: 2, InputTokens: 500, OutputTokens: 100},
+		{RequestRate: 7.5, InputTokens: 500, OutputTokens: 100},
+		{RequestRate: 6, InputTokens: 2000, OutputTokens: 100},
+	}}
+	runs := result.GuideLLMRuns()
+	if len(runs) != 2 || len(runs[0].Rates) != 2 || runs[1].PromptTokens != 2000 {
+		t.Fatalf("unexpected runs %+v", runs)
+	}
+	cmd := runs[0].Command("http://localhost:8000")
+	if !strings.Contains(cmd, "--rate 2.00,7.50") || !strings.Contains(cmd, "prompt_tokens=500,output_tokens=100") {
+		t.Errorf("unexpected command %q", cmd)
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"math"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// implementation of the model function accounting for the dispersion of token counts
+// across requests, using the LLM queue analyzer
+func DispersionModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	return WithTokenDispersion(Model, config.DefaultNumDispersionPoints)(x, params)
+}
+
+// get the model function of a kind, given the (mean-based) model function, e.g. Model
+func ModelOfKind(model ModelFunction, kind config.ModelKind) ModelFunction {
+	if kind == config.ModelDispersion {
+		return WithTokenDispersion(model, config.DefaultNumDispersionPoints)
+	}
+	return model
+}
+
+// WithTokenDispersion wraps a (mean-based) model function so that it accounts for the dispersion
+// of token counts. When a standard deviation is given, the token count distribution is approximated
+// by a log-normal distribution, discretized into numPoints equiprobable request sizes (per dimension).
+// The resulting request classes share the server as a workload mix, evaluated by decomposition,
+// and the rate-weighted averages over classes are returned. Convexity of latency in request size
+// thus inflates the predicted averages as dispersion grows. Without dispersion, the wrapped model
+// is called as is.
+func WithTokenDispersion(model ModelFunction, numPoints int) ModelFunction {
+	return func(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+		if x.InputTokensStdDev <= 0 && x.OutputTokensStdDev <= 0 {
+			return model(x, params)
+		}
+		inNodes := dispersionNodes(x.InputTokens, x.InputTokensStdDev, numPoints)
+		outNodes := dispersionNodes(x.OutputTokens, x.OutputTokensStdDev, numPoints)
+
+		classRate := x.RequestRate / float64(len(inNodes)*len(outNodes))
+		mix := NewWorkloadMix()
+		for _, in := range inNodes {
+			for _, out := range outNodes {
+				mix.Classes = append(mix.Classes, config.RequestClass{
+					RequestRate:  classRate,
+					InputTokens:  in,
+					OutputTokens: max(out, 1),
+				})
+			}
+		}
+		prediction, err := PredictMix(mix, x.MaxBatchSize, x.MaxNumTokens, params, model, MixDecomposition)
+		if err != nil {
+			return nil, err
+		}
+		return prediction.Aggregate, nil
+	}
+}
+
+// discretize a log-normal distribution with a given mean and standard deviation into
+// equiprobable points (quantiles at bin midpoints), rescaled to preserve the mean exactly
+func dispersionNodes(mean, stdDev float64, numPoints int) []float64 {
+	if stdDev <= 0 || mean <= 0 || numPoints <= 1 {
+		return []float64{mean}
+	}
+	cv := stdDev / mean
+	sigma := math.Sqrt(math.Log(1 + cv*cv))
+	dist := distuv.LogNormal{
+		Mu:    math.Log(mean) - sigma*sigma/2,
+		Sigma: sigma,
+	}
+
+	nodes := make([]float64, numPoints)
+	sum := 0.0
+	for i := range nodes {
+		nodes[i] = dist.Quantile((float64(i) + 0.5) / float64(numPoints))
+		sum += nodes[i]
+	}
+	scale := mean * float64(numPoints) / sum
+	for i := range nodes {
+		nodes[i] *= scale
+	}
+	return nodes
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// mockConvexModel: TTFT is convex in input tokens, ITL linear in output tokens
+func mockConvexModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	return &config.OutputVars{
+		AvgTTFTTime: params.Alpha + params.Beta*x.InputTokens*x.InputTokens,
+		AvgITLTime:  params.Alpha + params.Gamma*x.OutputTokens,
+	}, nil
+}
+
+func TestWithTokenDispersion(t *testing.T) {
+	params := &config.ModelParams{
+		Alpha: 1.0,
+		Beta:  0.001,
+		Gamma: 0.01,
+	}
+	model := WithTokenDispersion(mockConvexModel, config.DefaultNumDispersionPoints)
+
+	tests := []struct {
+		name       string
+		x          *config.InputVars
+		validateFn func(t *testing.T, got, base *config.OutputVars)
+	}{
+		{
+			name: "no dispersion reduces to the wrapped model",
+			x: &config.InputVars{
+				RequestRate:  5.0,
+				InputTokens:  100.0,
+				OutputTokens: 50.0,
+				MaxBatchSize: 256,
+				MaxNumTokens: 8192,
+			},
+			validateFn: func(t *testing.T, got, base *config.OutputVars) {
+				if *got != *base {
+					t.Errorf("got %+v, want %+v", *got, *base)
+				}
+			},
+		},
+		{
+			name: "input dispersion inflates convex TTFT",
+			x: &config.InputVars{
+				RequestRate:       5.0,
+				InputTokens:       100.0,
+				OutputTokens:      50.0,
+				MaxBatchSize:      256,
+				MaxNumTokens:      8192,
+				InputTokensStdDev: 80.0,
+			},
+			validateFn: func(t *testing.T, got, base *config.OutputVars) {
+				if got.AvgTTFTTime <= base.AvgTTFTTime {
+					t.Errorf("TTFT = %v, want > %v", got.AvgTTFTTime, base.AvgTTFTTime)
+				}
+				// ITL is linear in output tokens, which have no dispersion
+				if !almostEqual(got.AvgITLTime, base.AvgITLTime) {
+					t.Errorf("ITL = %v, want %v", got.AvgITLTime, base.AvgITLTime)
+				}
+			},
+		},
+		{
+			name: "output dispersion preserves linear ITL",
+			x: &config.InputVars{
+				RequestRate:        5.0,
+				InputTokens:        100.0,
+				OutputTokens:       50.0,
+				MaxBatchSize:       256,
+				MaxNumTokens:       8192,
+				OutputTokensStdDev: 20.0,
+			},
+			validateFn: func(t *testing.T, got, base *config.OutputVars) {
+				if !almostEqual(got.AvgITLTime, base.AvgITLTime) {
+					t.Errorf("ITL = %v, want %v", got.AvgITLTime, base.AvgITLTime)
+				}
+				if !almostEqual(got.AvgTTFTTime, base.AvgTTFTTime) {
+					t.Errorf("TTFT = %v, want %v", got.AvgTTFTTime, base.AvgTTFTTime)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, _ := mockConvexModel(tt.x, params)
+			got, err := model(tt.x, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, got, base)
+		})
+	}
+}
+
+func TestDispersionNodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		mean      float64
+		stdDev    float64
+		numPoints int
+		wantLen   int
+	}{
+		{name: "no dispersion", mean: 100.0, stdDev: 0.0, numPoints: 5, wantLen: 1},
+		{name: "single point", mean: 100.0, stdDev: 50.0, numPoints: 1, wantLen: 1},
+		{name: "moderate dispersion", mean: 100.0, stdDev: 30.0, numPoints: 5, wantLen: 5},
+		{name: "heavy tail", mean: 1000.0, stdDev: 3000.0, numPoints: 7, wantLen: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := dispersionNodes(tt.mean, tt.stdDev, tt.numPoints)
+			if len(nodes) != tt.wantLen {
+				t.Fatalf("len(nodes) = %d, want %d", len(nodes), tt.wantLen)
+			}
+			sum := 0.0
+			for i, n := range nodes {
+				if n <= 0 {
+					t.Errorf("node %d = %v, want > 0", i, n)
+				}
+				if i > 0 && n < nodes[i-1] {
+					t.Errorf("nodes not increasing at %d", i)
+				}
+				sum += n
+			}
+			if mean := sum / float64(len(nodes)); !almostEqual(mean, tt.mean) {
+				t.Errorf("mean of nodes = %v, want %v", mean, tt.mean)
+			}
+		})
+	}
+}
+
+// fits of data with dispersed token counts recover the parameters with the dispersion model only
+func TestOptimizer_DispersionModel(t *testing.T) {
+	trueParams := &config.ModelParams{Alpha: 1.0, Beta: 0.001, Gamma: 0.01}
+	dispersed := WithTokenDispersion(mockConvexModel, config.DefaultNumDispersionPoints)
+	ds := NewDataSet("dispersed")
+	for _, in := range []float64{50, 100, 200, 400} {
+		for _, out := range []float64{20, 80} {
+			x := &config.InputVars{RequestRate: 5, InputTokens: in, OutputTokens: out, MaxBatchSize: 256, MaxNumTokens: 8192,
+				InputTokensStdDev: 0.8 * in, OutputTokensStdDev: 0.5 * out}
+			y, err := dispersed(x, trueParams)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ds.AppendDataPoint(&DataPoint{RequestRate: x.RequestRate, InputTokens: in, OutputTokens: out,
+				InputTokensStdDev: x.InputTokensStdDev, OutputTokensStdDev: x.OutputTokensStdDev,
+				AvgTTFTTime: y.AvgTTFTTime, AvgITLTime: y.AvgITLTime, MaxBatchSize: x.MaxBatchSize, MaxNumTokens: x.MaxNumTokens})
+		}
+	}
+
+	tests := []struct {
+		name        string
+		kind        config.ModelKind
+		expectError bool
+		recovered   bool
+	}{
+		{name: "dispersion model", kind: config.ModelDispersion, recovered: true},
+		{name: "mean model", kind: config.ModelMean},
+		{name: "unknown model", kind: "median", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimizer := NewOptimizer(&config.ModelParams{Alpha: 0.5, Beta: 0.002, Gamma: 0.005})
+			optimizer.Model = tt.kind
+			optimizer.Quiet = true
+			result, err := optimizer.Optimize(ds, mockConvexModel)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			relErr := math.Abs(result.OptimizedParms.Beta-trueParams.Beta) / trueParams.Beta
+			if recovered := relErr < 0.01; recovered != tt.recovered {
+				t.Errorf("beta = %v, relative error %v", result.OptimizedParms.Beta, relErr)
+			}
+		})
+	}
+}
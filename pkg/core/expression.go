@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParsePredicate parses a filter expression over fields of data points and their labels, e.g.
+//
+//	strategy != throughput && requestRate < 50
+//	(model == "meta-llama/Llama-3.1-8B" || tags.env == test) && !(avgTTFTTime > 1000)
+//	strategy =~ "^constant"
+//
+// Fields are given by json name (labels include the data set metadata, tags as tags.<key>).
+// Numeric fields support ==, !=, <, <=, >, >=; string fields support ==, != and =~ (regular expression).
+// Values are numbers, quoted strings, or bare words; && binds tighter than ||.
+func ParsePredicate(expr string) (Predicate, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q in expression", p.peek().text)
+	}
+	return pred, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// operators, longest first
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")"}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		if unicode.IsSpace(c) {
+			i++
+			continue
+		}
+		if c == '"' || c == '\'' {
+			end := strings.IndexRune(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in expression at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expr[i+1 : i+1+end]})
+			i += end + 2
+			continue
+		}
+		isOp := false
+		for _, op := range exprOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				tokens = append(tokens, token{kind: tokenOp, text: op})
+				i += len(op)
+				isOp = true
+				break
+			}
+		}
+		if isOp {
+			continue
+		}
+		start := i
+		for i < len(expr) && !unicode.IsSpace(rune(expr[i])) && !strings.ContainsRune("()!=<>&|\"'", rune(expr[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("unexpected %q in expression at %d", expr[i], i)
+		}
+		tokens = append(tokens, token{kind: tokenWord, text: expr[start:i]})
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *exprParser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) isOp(op string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == tokenOp && t.text == op
+}
+
+func (p *exprParser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ds *DataSet, i int) bool { return l(ds, i) || right(ds, i) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ds *DataSet, i int) bool { return l(ds, i) && right(ds, i) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (Predicate, error) {
+	if p.isOp("!") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(ds *DataSet, i int) bool { return !inner(ds, i) }, nil
+	}
+	if p.isOp("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, fmt.Errorf("missing closing parenthesis in expression")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (Predicate, error) {
+	if p.done() || p.peek().kind != tokenWord {
+		return nil, fmt.Errorf("expected field name in expression, got %q", p.peek().text)
+	}
+	field := p.peek().text
+	isNum, err := checkField(field)
+	if err != nil {
+		return nil, err
+	}
+	p.pos++
+	if p.done() || p.peek().kind != tokenOp {
+		return nil, fmt.Errorf("expected comparison operator after %s", field)
+	}
+	op := p.peek().text
+	p.pos++
+	if p.done() || p.peek().kind == tokenOp {
+		return nil, fmt.Errorf("expected value after %s %s", field, op)
+	}
+	literal := p.peek().text
+	p.pos++
+
+	value := func(ds *DataSet, i int) FieldValue {
+		v, _ := ds.FieldValue(i, field)
+		return v
+	}
+	if isNum {
+		x, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q for field %s", literal, field)
+		}
+		var cmp func(a float64) bool
+		switch op {
+		case "==":
+			cmp = func(a float64) bool { return a == x }
+		case "!=":
+			cmp = func(a float64) bool { return a != x }
+		case "<":
+			cmp = func(a float64) bool { return a < x }
+		case "<=":
+			cmp = func(a float64) bool { return a <= x }
+		case ">":
+			cmp = func(a float64) bool { return a > x }
+		case ">=":
+			cmp = func(a float64) bool { return a >= x }
+		default:
+			return nil, fmt.Errorf("operator %s not supported for numeric field %s", op, field)
+		}
+		return func(ds *DataSet, i int) bool { return cmp(value(ds, i).Num) }, nil
+	}
+
+	switch op {
+	case "==":
+		return func(ds *DataSet, i int) bool { return value(ds, i).Str == literal }, nil
+	case "!=":
+		return func(ds *DataSet, i int) bool { return value(ds, i).Str != literal }, nil
+	case "=~":
+		re, err := regexp.Compile(literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for field %s: %w", field, err)
+		}
+		return func(ds *DataSet, i int) bool { return re.MatchString(value(ds, i).Str) }, nil
+	}
+	return nil, fmt.Errorf("operator %s not supported for string field %s", op, field)
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"maps"
+	"reflect"
+)
+
+// provenance labels of benchmark data, at the data set level (metadata) or the data point level;
+// empty fields are unknown
+type Labels struct {
+	Source            string            `json:"source,omitempty"`            // source file of the data
+	BenchmarkID       string            `json:"benchmarkID,omitempty"`       // identifier of the benchmark run
+	Strategy          string            `json:"strategy,omitempty"`          // benchmark strategy (e.g. synchronous, constant)
+	Model             string            `json:"model,omitempty"`             // name of the served model
+	Accelerator       string            `json:"accelerator,omitempty"`       // accelerator type (e.g. H100)
+	TensorParallelism int               `json:"tensorParallelism,omitempty"` // tensor parallelism size
+	EngineVersion     string            `json:"engineVersion,omitempty"`     // version of the serving engine (e.g. vLLM)
+	Tags              map[string]string `json:"tags,omitempty"`              // free-form tags
+}
+
+// check if no label is set
+func (l *Labels) IsEmpty() bool {
+	return l == nil || (l.Source == "" && l.BenchmarkID == "" && l.Strategy == "" && l.Model == "" &&
+		l.Accelerator == "" && l.TensorParallelism == 0 && l.EngineVersion == "" && len(l.Tags) == 0)
+}
+
+// get a copy of the labels with the set fields of other labels overriding them (nil if both empty)
+func (l *Labels) Overlay(other *Labels) *Labels {
+	if l.IsEmpty() && other.IsEmpty() {
+		return nil
+	}
+	result := &Labels{}
+	if l != nil {
+		*result = *l
+		result.Tags = maps.Clone(l.Tags)
+	}
+	if other == nil {
+		return result
+	}
+	if other.Source != "" {
+		result.Source = other.Source
+	}
+	if other.BenchmarkID != "" {
+		result.BenchmarkID = other.BenchmarkID
+	}
+	if other.Strategy != "" {
+		result.Strategy = other.Strategy
+	}
+	if other.Model != "" {
+		result.Model = other.Model
+	}
+	if other.Accelerator != "" {
+		result.Accelerator = other.Accelerator
+	}
+	if other.TensorParallelism != 0 {
+		result.TensorParallelism = other.TensorParallelism
+	}
+	if other.EngineVersion != "" {
+		result.EngineVersion = other.EngineVersion
+	}
+	for k, v := range other.Tags {
+		if result.Tags == nil {
+			result.Tags = map[string]string{}
+		}
+		result.Tags[k] = v
+	}
+	return result
+}
+
+// get the labels shared by both labels (nil if none)
+func (l *Labels) Common(other *Labels) *Labels {
+	if l == nil || other == nil {
+		return nil
+	}
+	result := &Labels{}
+	if l.Source == other.Source {
+		result.Source = l.Source
+	}
+	if l.BenchmarkID == other.BenchmarkID {
+		result.BenchmarkID = l.BenchmarkID
+	}
+	if l.Strategy == other.Strategy {
+		result.Strategy = l.Strategy
+	}
+	if l.Model == other.Model {
+		result.Model = l.Model
+	}
+	if l.Accelerator == other.Accelerator {
+		result.Accelerator = l.Accelerator
+	}
+	if l.TensorParallelism == other.TensorParallelism {
+		result.TensorParallelism = l.TensorParallelism
+	}
+	if l.EngineVersion == other.EngineVersion {
+		result.EngineVersion = l.EngineVersion
+	}
+	for k, v := range l.Tags {
+		if w, ok := other.Tags[k]; ok && w == v {
+			if result.Tags == nil {
+				result.Tags = map[string]string{}
+			}
+			result.Tags[k] = v
+		}
+	}
+	if result.IsEmpty() {
+		return nil
+	}
+	return result
+}
+
+// get the labels of a data point in the data set, i.e. the data set metadata overridden by the point labels
+func (dataSet *DataSet) LabelsOf(index int) *Labels {
+	return dataSet.Metadata.Overlay(dataSet.Data[index].Labels)
+}
+
+// set the source of the data set
+func (dataSet *DataSet) SetSource(source string) {
+	dataSet.Metadata = dataSet.Metadata.Overlay(&Labels{Source: source})
+}
+
+// push the data set metadata down to the labels of its data points, keeping only the given metadata
+// at the data set level (the given metadata must hold for all points)
+func (dataSet *DataSet) pushDownMetadata(keep *Labels) {
+	if reflect.DeepEqual(dataSet.Metadata, keep) {
+		return
+	}
+	for i := range dataSet.Data {
+		dataSet.Data[i].Labels = dataSet.LabelsOf(i)
+	}
+	dataSet.Metadata = keep
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabels_Overlay(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  *Labels
+		other *Labels
+		want  *Labels
+	}{
+		{name: "both nil", want: nil},
+		{name: "both empty", base: &Labels{}, other: &Labels{Tags: map[string]string{}}, want: nil},
+		{
+			name: "other overrides set fields",
+			base: &Labels{Model: "llama", Accelerator: "H100", Tags: map[string]string{"run": "1"}},
+			other: &Labels{Accelerator: "A100", TensorParallelism: 4,
+				Tags: map[string]string{"env": "test"}},
+			want: &Labels{Model: "llama", Accelerator: "A100", TensorParallelism: 4,
+				Tags: map[string]string{"run": "1", "env": "test"}},
+		},
+		{
+			name:  "nil base",
+			base:  nil,
+			other: &Labels{Strategy: "constant"},
+			want:  &Labels{Strategy: "constant"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.base.Overlay(tt.other)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("base not modified", func(t *testing.T) {
+		base := &Labels{Model: "llama", Tags: map[string]string{"run": "1"}}
+		base.Overlay(&Labels{Model: "qwen", Tags: map[string]string{"run": "2"}})
+		if base.Model != "llama" || base.Tags["run"] != "1" {
+			t.Errorf("base modified: %+v", base)
+		}
+	})
+}
+
+func TestLabels_Common(t *testing.T) {
+	a := &Labels{Model: "llama", Accelerator: "H100", TensorParallelism: 4, Tags: map[string]string{"x": "1", "y": "2"}}
+	b := &Labels{Model: "llama", Accelerator: "H100", TensorParallelism: 8, Tags: map[string]string{"x": "1", "y": "3"}}
+	want := &Labels{Model: "llama", Accelerator: "H100", Tags: map[string]string{"x": "1"}}
+	if got := a.Common(b); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := a.Common(nil); got != nil {
+		t.Errorf("common with nil = %+v, want nil", got)
+	}
+	if got := (&Labels{Model: "a"}).Common(&Labels{Model: "b"}); got != nil {
+		t.Errorf("nothing in common = %+v, want nil", got)
+	}
+}
+
+func TestDataSet_MergeLabels(t *testing.T) {
+	tp4 := NewDataSet("tp4")
+	tp4.Metadata = &Labels{Model: "llama", TensorParallelism: 4}
+	tp4.AppendDataPoint(&DataPoint{RequestRate: 1.0, Labels: &Labels{BenchmarkID: "a"}})
+
+	tp8 := NewDataSet("tp8")
+	tp8.Metadata = &Labels{Model: "llama", TensorParallelism: 8}
+	tp8.SetSource("tp8.json")
+	tp8.AppendDataPoint(&DataPoint{RequestRate: 2.0})
+
+	if err := tp4.Merge(tp8); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+
+	if want := (&Labels{Model: "llama"}); !reflect.DeepEqual(tp4.Metadata, want) {
+		t.Errorf("Metadata = %+v, want %+v", tp4.Metadata, want)
+	}
+	want0 := &Labels{Model: "llama", TensorParallelism: 4, BenchmarkID: "a"}
+	if got := tp4.LabelsOf(0); !reflect.DeepEqual(got, want0) {
+		t.Errorf("LabelsOf(0) = %+v, want %+v", got, want0)
+	}
+	want1 := &Labels{Model: "llama", TensorParallelism: 8, Source: "tp8.json"}
+	if got := tp4.LabelsOf(1); !reflect.DeepEqual(got, want1) {
+		t.Errorf("LabelsOf(1) = %+v, want %+v", got, want1)
+	}
+	if tp8.Data[0].Labels != nil {
+		t.Error("merged data set must not be modified")
+	}
+}
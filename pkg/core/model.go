@@ -56,6 +56,10 @@ func Model(x *config.InputVars, params *config.ModelParams) (*config.OutputVars,
 	return &config.OutputVars{
 		AvgTTFTTime: float64(metrics.AvgTTFT),
 		AvgITLTime:  float64(metrics.AvgTokenTime),
+
+		AvgWaitTime:    float64(metrics.AvgWaitTime),
+		AvgPrefillTime: float64(metrics.AvgPrefillTime),
+		Rho:            float64(metrics.Rho),
 	}, nil
 }
 
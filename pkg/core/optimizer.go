@@ -12,6 +12,18 @@ import (
 type Optimizer struct {
 	// initial values of model parameters
 	InitParms *config.ModelParams
+	// statistic of the latency distributions to fit (average if empty)
+	Statistic config.Statistic
+	// kind of model fitted, from the given model function (mean if empty)
+	Model config.ModelKind
+	// refuse data sets with validation errors
+	Strict bool
+	// do not scale parameters by their initial values
+	DisableScaling bool
+	// maximum number of iterations (default if zero)
+	MaxIterations int
+	// do not print progress and the fitted data
+	Quiet bool
 }
 
 // result of optimization
@@ -54,14 +66,37 @@ func unscaleSlice(scaled, scale []float64) []float64 {
 // optimize model parameters to fit the data set using the given model function
 func (opt *Optimizer) Optimize(dataSet *DataSet, model ModelFunction) (*OptimizationResult, error) {
 	// prepare data
-	xData, yData := dataSet.GetInOutVars()
+	if opt.Strict {
+		if report := dataSet.Validate(); report.HasErrors() {
+			return nil, &ValidationError{Report: report}
+		}
+	}
+	if !opt.Model.IsValid() {
+		return nil, fmt.Errorf("unknown model %q", opt.Model)
+	}
+	stat := opt.Statistic
+	if stat == "" {
+		stat = config.StatisticAverage
+	}
+	xData, yData, err := dataSet.GetInOutVarsFor(stat)
+	if err != nil {
+		return nil, err
+	}
+	if len(xData) == 0 {
+		return nil, fmt.Errorf("no data points with %s of TTFT and ITL", stat)
+	}
+	model = PercentileModel(ModelOfKind(model, opt.Model), stat)
 	errVars := &config.ErrorVars{}
 
 	// Scale variables by their initial values so the optimizer sees O(1) quantities.
 	// This prevents the initial Nelder-Mead simplex from being degenerate when
 	// parameters span multiple orders of magnitude.
-	scale := utils.CreateParmsSliceFromModelParams(opt.InitParms)
-	scaledInit := scaleSlice(scale, scale) // raw=init, so result is all 1s (or 1 for zero inits)
+	initSlice := utils.CreateParmsSliceFromModelParams(opt.InitParms)
+	scale := initSlice
+	if opt.DisableScaling {
+		scale = []float64{1, 1, 1}
+	}
+	scaledInit := scaleSlice(initSlice, scale) // raw=init, so result is all 1s (or 1 for zero inits)
 
 	// Create a problem for the optimizer (operates in scaled space)
 	problem := optimize.Problem{
@@ -73,20 +108,27 @@ func (opt *Optimizer) Optimize(dataSet *DataSet, model ModelFunction) (*Optimiza
 	}
 
 	// Run the optimizer
+	maxIterations := opt.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = config.DefaultNumberOptimizationIterations
+	}
 	settings := &optimize.Settings{
-		MajorIterations: config.DefaultNumberOptimizationIterations,
+		MajorIterations: maxIterations,
 	}
 	result, err := optimize.Minimize(problem, scaledInit, settings, &optimize.NelderMead{})
 	if err != nil {
 		return nil, fmt.Errorf("optimization error: %w", err)
 	}
 	optimizedParms := utils.CreateModelParamsFromParmsSlice(unscaleSlice(result.X, scale))
-	fmt.Printf("Optimization completed. Objective value: %f\n", result.F)
+	if !opt.Quiet {
+		fmt.Printf("Optimization completed. Objective value: %f\n", result.F)
+	}
 
 	// Create analysis results using optimal solution
 	errVars = &config.ErrorVars{} // start with clean error vars
-	LossFunction(optimizedParms, xData, yData, model, errVars, true)
+	LossFunction(optimizedParms, xData, yData, model, errVars, !opt.Quiet)
 	analysisResults := utils.CreateAnalysisResultsFromErrorVars(errVars)
+	analysisResults.Statistic = stat
 	return &OptimizationResult{
 		OptimizedParms:  optimizedParms,
 		AnalysisResults: analysisResults,
@@ -321,6 +321,67 @@ func TestOptimizer_OptimizeWithInvalidParams(t *testing.T) {
 	}
 }
 
+func TestOptimizer_Options(t *testing.T) {
+	truth := &config.ModelParams{Alpha: 5.0, Beta: 0.5, Gamma: 0.02}
+	dataPoints := []DataPoint{}
+	for _, x := range [][2]float64{{1, 100}, {10, 400}, {20, 200}, {40, 800}} {
+		y, _ := mockLinearModel(&config.InputVars{RequestRate: x[0], InputTokens: x[1]}, truth)
+		dataPoints = append(dataPoints, DataPoint{RequestRate: x[0], InputTokens: x[1], OutputTokens: 50,
+			AvgTTFTTime: y.AvgTTFTTime, AvgITLTime: y.AvgITLTime})
+	}
+	relErr := func(p *config.ModelParams) float64 {
+		return math.Max(math.Abs(p.Alpha-truth.Alpha)/truth.Alpha,
+			math.Max(math.Abs(p.Beta-truth.Beta)/truth.Beta, math.Abs(p.Gamma-truth.Gamma)/truth.Gamma))
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(opt *Optimizer)
+		validateFn func(t *testing.T, result *OptimizationResult)
+	}{
+		{
+			name:   "scaled",
+			modify: func(opt *Optimizer) {},
+			validateFn: func(t *testing.T, result *OptimizationResult) {
+				if e := relErr(result.OptimizedParms); e > 0.01 {
+					t.Errorf("relative error %v exceeds 1%%", e)
+				}
+			},
+		},
+		{
+			name:   "unscaled",
+			modify: func(opt *Optimizer) { opt.DisableScaling = true },
+			validateFn: func(t *testing.T, result *OptimizationResult) {
+				if e := relErr(result.OptimizedParms); e > 0.01 {
+					t.Errorf("relative error %v exceeds 1%%", e)
+				}
+			},
+		},
+		{
+			name:   "iteration limit",
+			modify: func(opt *Optimizer) { opt.MaxIterations = 2 },
+			validateFn: func(t *testing.T, result *OptimizationResult) {
+				if e := relErr(result.OptimizedParms); e < 0.01 {
+					t.Errorf("relative error %v too small after 2 iterations", e)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimizer := NewOptimizer(&config.ModelParams{Alpha: 1.0, Beta: 0.1, Gamma: 0.01})
+			optimizer.Quiet = true
+			tt.modify(optimizer)
+			result, err := optimizer.Optimize(createTestDataSet(dataPoints), mockLinearModel)
+			if err != nil {
+				t.Fatalf("Optimize() failed: %v", err)
+			}
+			tt.validateFn(t, result)
+		})
+	}
+}
+
 // Helper function to create a test dataset
 func createTestDataSet(dataPoints []DataPoint) *DataSet {
 	dataSet := NewDataSet("test")
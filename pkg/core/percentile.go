@@ -0,0 +1,68 @@
+package core
+
+import (
+	"math"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// PercentileModel wraps a (mean-based) model function so that it predicts a percentile of the
+// TTFT and ITL distributions, rather than their averages. The percentile is obtained through a
+// distributional approximation on top of the predicted averages:
+//   - TTFT is the prefill time plus the queueing time, where a request waits with probability rho
+//     (server utilization), and the waiting time, given that it waits, is exponentially distributed;
+//   - ITL is log-normally distributed around its average, with a fixed coefficient of variation.
+//
+// For the average statistic, the wrapped model is returned as is.
+func PercentileModel(model ModelFunction, stat config.Statistic) ModelFunction {
+	q, isPercentile := stat.Quantile()
+	if !isPercentile {
+		return model
+	}
+	return func(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+		y, err := model(x, params)
+		if err != nil {
+			return nil, err
+		}
+		return &config.OutputVars{
+			AvgTTFTTime:    ttftQuantile(y, q),
+			AvgITLTime:     itlQuantile(y.AvgITLTime, config.DefaultITLCoefficientOfVariation, q),
+			AvgWaitTime:    y.AvgWaitTime,
+			AvgPrefillTime: y.AvgPrefillTime,
+			Rho:            y.Rho,
+		}, nil
+	}
+}
+
+// quantile of TTFT = prefill + wait, with P(wait > 0) = rho and an exponential conditional wait;
+// without a breakdown of TTFT, the whole TTFT is taken as deterministic
+func ttftQuantile(y *config.OutputVars, q float64) float64 {
+	prefill := y.AvgPrefillTime
+	if prefill <= 0 || prefill > y.AvgTTFTTime {
+		return y.AvgTTFTTime
+	}
+	wait := y.AvgTTFTTime - prefill
+	rho := y.Rho
+	if rho <= 0 || rho > 1 {
+		rho = 1
+	}
+	if wait <= 0 || q <= 1-rho {
+		return prefill
+	}
+	// P(wait > t) = rho * exp(-t * rho / wait)
+	return prefill + (wait/rho)*math.Log(rho/(1-q))
+}
+
+// quantile of a log-normal distribution with a given mean and coefficient of variation
+func itlQuantile(mean, cv, q float64) float64 {
+	if mean <= 0 || cv <= 0 {
+		return mean
+	}
+	sigma := math.Sqrt(math.Log(1 + cv*cv))
+	dist := distuv.LogNormal{
+		Mu:    math.Log(mean) - sigma*sigma/2,
+		Sigma: sigma,
+	}
+	return dist.Quantile(q)
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// mockBreakdownModel: TTFT made of a fixed prefill time and a waiting time growing with rate
+func mockBreakdownModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	prefill := params.Alpha + params.Beta*x.InputTokens
+	wait := x.RequestRate
+	return &config.OutputVars{
+		AvgTTFTTime:    prefill + wait,
+		AvgITLTime:     params.Alpha + params.Gamma*x.OutputTokens,
+		AvgWaitTime:    wait,
+		AvgPrefillTime: prefill,
+		Rho:            x.RequestRate / 10,
+	}, nil
+}
+
+func TestPercentileModel(t *testing.T) {
+	params := &config.ModelParams{
+		Alpha: 1.0,
+		Beta:  0.1,
+		Gamma: 0.01,
+	}
+	x := &config.InputVars{
+		RequestRate:  5.0,
+		InputTokens:  100.0,
+		OutputTokens: 50.0,
+		MaxBatchSize: 256,
+		MaxNumTokens: 8192,
+	}
+	base, _ := mockBreakdownModel(x, params)
+
+	predict := func(t *testing.T, stat config.Statistic) *config.OutputVars {
+		y, err := PercentileModel(mockBreakdownModel, stat)(x, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return y
+	}
+
+	t.Run("average reduces to the wrapped model", func(t *testing.T) {
+		for _, stat := range []config.Statistic{"", config.StatisticAverage} {
+			if got := predict(t, stat); *got != *base {
+				t.Errorf("%q: got %+v, want %+v", stat, *got, *base)
+			}
+		}
+	})
+
+	t.Run("median below average for skewed distributions", func(t *testing.T) {
+		got := predict(t, config.StatisticP50)
+		// rho = 0.5, so half of the requests do not wait
+		if !almostEqual(got.AvgTTFTTime, base.AvgPrefillTime) {
+			t.Errorf("p50 TTFT = %v, want prefill time %v", got.AvgTTFTTime, base.AvgPrefillTime)
+		}
+		if got.AvgITLTime >= base.AvgITLTime {
+			t.Errorf("p50 ITL = %v, want < %v", got.AvgITLTime, base.AvgITLTime)
+		}
+	})
+
+	t.Run("percentiles increasing", func(t *testing.T) {
+		p50 := predict(t, config.StatisticP50)
+		p90 := predict(t, config.StatisticP90)
+		p99 := predict(t, config.StatisticP99)
+		if !(p50.AvgTTFTTime < p90.AvgTTFTTime && p90.AvgTTFTTime < p99.AvgTTFTTime) {
+			t.Errorf("TTFT percentiles not increasing: %v, %v, %v", p50.AvgTTFTTime, p90.AvgTTFTTime, p99.AvgTTFTTime)
+		}
+		if !(p50.AvgITLTime < p90.AvgITLTime && p90.AvgITLTime < p99.AvgITLTime) {
+			t.Errorf("ITL percentiles not increasing: %v, %v, %v", p50.AvgITLTime, p90.AvgITLTime, p99.AvgITLTime)
+		}
+		if p90.AvgTTFTTime <= base.AvgTTFTTime {
+			t.Errorf("p90 TTFT = %v, want > average %v", p90.AvgTTFTTime, base.AvgTTFTTime)
+		}
+	})
+
+	t.Run("no breakdown keeps TTFT", func(t *testing.T) {
+		y, err := PercentileModel(mockConvexModel, config.StatisticP99)(x, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want, _ := mockConvexModel(x, params)
+		if y.AvgTTFTTime != want.AvgTTFTTime {
+			t.Errorf("TTFT = %v, want %v", y.AvgTTFTTime, want.AvgTTFTTime)
+		}
+	})
+}
+
+func TestDataPoint_GetOutputVars(t *testing.T) {
+	dp := DataPoint{
+		RequestRate:     1.0,
+		InputTokens:     100.0,
+		OutputTokens:    50.0,
+		AvgTTFTTime:     20.0,
+		AvgITLTime:      10.0,
+		TTFTPercentiles: &config.Percentiles{P50: 18.0, P90: 30.0, P99: 45.0},
+		ITLPercentiles:  &config.Percentiles{P50: 9.5, P90: 11.0, P99: 12.0},
+	}
+
+	tests := []struct {
+		name        string
+		stat        config.Statistic
+		expectError bool
+		want        config.OutputVars
+	}{
+		{name: "average", stat: config.StatisticAverage, want: config.OutputVars{AvgTTFTTime: 20.0, AvgITLTime: 10.0}},
+		{name: "empty means average", stat: "", want: config.OutputVars{AvgTTFTTime: 20.0, AvgITLTime: 10.0}},
+		{name: "p90", stat: config.StatisticP90, want: config.OutputVars{AvgTTFTTime: 30.0, AvgITLTime: 11.0}},
+		{name: "missing p95", stat: config.StatisticP95, expectError: true},
+		{name: "unknown statistic", stat: config.Statistic("p42"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dp.GetOutputVars(tt.stat)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSet_GetInOutVarsFor(t *testing.T) {
+	ds := NewDataSet("percentiles")
+	ds.AppendDataPoint(&DataPoint{RequestRate: 1.0, AvgTTFTTime: 20.0, AvgITLTime: 10.0,
+		TTFTPercentiles: &config.Percentiles{P90: 30.0}, ITLPercentiles: &config.Percentiles{P90: 11.0}})
+	ds.AppendDataPoint(&DataPoint{RequestRate: 2.0, AvgTTFTTime: 25.0, AvgITLTime: 12.0})
+
+	xData, yData, err := ds.GetInOutVarsFor(config.StatisticP90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(xData) != 1 || len(yData) != 1 {
+		t.Fatalf("got %d points, want 1 (points without p90 skipped)", len(xData))
+	}
+	if xData[0].RequestRate != 1.0 || yData[0].AvgTTFTTime != 30.0 {
+		t.Errorf("got x=%+v, y=%+v", *xData[0], *yData[0])
+	}
+
+	if xData, _, _ := ds.GetInOutVarsFor(config.StatisticAverage); len(xData) != 2 {
+		t.Errorf("average: got %d points, want 2", len(xData))
+	}
+	if _, _, err := ds.GetInOutVarsFor(config.Statistic("mode")); err == nil {
+		t.Error("expected error for unknown statistic, got nil")
+	}
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// capacity planning specification: target load, workload, SLOs, and the search space
+type PlanSpec struct {
+	TotalRequestRate float64     `json:"totalRequestRate"` // aggregate request arrival rate (requests/sec)
+	InputTokens      float64     `json:"inputTokens"`      // average number of input tokens per request (single class)
+	OutputTokens     float64     `json:"outputTokens"`     // average number of output tokens per request (single class)
+	SLO              *config.SLO `json:"slo"`              // service level objectives (applied to every class)
+
+	// optional mix of request classes, replacing the single class given by the token counts;
+	// class rates are rescaled to add up to the total request rate, if one is given
+	Workload  *WorkloadMix `json:"workload,omitempty"`
+	MixMethod MixMethod    `json:"mixMethod,omitempty"` // approximation method for the mix
+
+	MaxNumReplicas int     `json:"maxNumReplicas"` // maximum number of replicas to consider
+	MaxBatchSizes  []int   `json:"maxBatchSizes"`  // candidate maximum batch sizes
+	MaxNumTokens   []int   `json:"maxNumTokens"`   // candidate maximum numbers of tokens in a batch
+	CostPerReplica float64 `json:"costPerReplica"` // cost of a single replica
+}
+
+// a feasible configuration found by the planner
+type PlanResult struct {
+	NumReplicas    int                `json:"numReplicas"`    // number of replicas
+	MaxBatchSize   int                `json:"maxBatchSize"`   // maximum batch size per replica
+	MaxNumTokens   int                `json:"maxNumTokens"`   // maximum number of tokens in a batch per replica
+	RatePerReplica float64            `json:"ratePerReplica"` // request rate handled by each replica (requests/sec)
+	Cost           float64            `json:"cost"`           // total cost of the configuration
+	Predicted      *config.OutputVars `json:"predicted"`      // predicted (aggregate) performance metrics per replica
+	Classes        []*ClassPrediction `json:"classes"`        // predicted performance metrics per class
+}
+
+// Planner searches for minimal-cost server configurations using a parametrized model
+type Planner struct {
+	Parms *config.ModelParams
+}
+
+func NewPlanner(parms *config.ModelParams) *Planner {
+	return &Planner{
+		Parms: parms,
+	}
+}
+
+// fix any missing fields in the plan specification
+func (spec *PlanSpec) Fix() {
+	if spec.SLO == nil {
+		spec.SLO = &config.SLO{}
+	}
+	if spec.MaxNumReplicas <= 0 {
+		spec.MaxNumReplicas = config.DefaultMaxNumReplicas
+	}
+	if len(spec.MaxBatchSizes) == 0 {
+		spec.MaxBatchSizes = []int{config.DefaultMaxBatchSize}
+	}
+	if len(spec.MaxNumTokens) == 0 {
+		spec.MaxNumTokens = []int{config.DefaultMaxNumTokens}
+	}
+	if spec.CostPerReplica <= 0 {
+		spec.CostPerReplica = config.DefaultCostPerReplica
+	}
+	if spec.MixMethod == "" {
+		spec.MixMethod = MixDecomposition
+	}
+}
+
+// get the workload mix of the specification, carrying the total request rate
+func (spec *PlanSpec) GetWorkload() *WorkloadMix {
+	if spec.Workload == nil || len(spec.Workload.Classes) == 0 {
+		return NewWorkloadMix(config.RequestClass{
+			RequestRate:  spec.TotalRequestRate,
+			InputTokens:  spec.InputTokens,
+			OutputTokens: spec.OutputTokens,
+		})
+	}
+	if total := spec.Workload.TotalRate(); spec.TotalRequestRate > 0 && total > 0 {
+		return spec.Workload.Scale(spec.TotalRequestRate / total)
+	}
+	return spec.Workload
+}
+
+// Plan searches over the number of replicas and the candidate batch settings, splitting the
+// total rate evenly across replicas, and returns the feasible configurations ranked by cost.
+// For each batch setting only the smallest number of replicas meeting the SLOs is reported.
+func (p *Planner) Plan(spec *PlanSpec, model ModelFunction) ([]*PlanResult, error) {
+	spec.Fix()
+	workload := spec.GetWorkload()
+	if workload.TotalRate() <= 0 {
+		return nil, fmt.Errorf("invalid total request rate %v", workload.TotalRate())
+	}
+
+	results := []*PlanResult{}
+	for _, maxBatchSize := range spec.MaxBatchSizes {
+		for _, maxNumTokens := range spec.MaxNumTokens {
+			if result := p.minReplicas(spec, workload, maxBatchSize, maxNumTokens, model); result != nil {
+				results = append(results, result)
+			}
+		}
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no feasible configuration with at most %d replicas", spec.MaxNumReplicas)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Cost != results[j].Cost {
+			return results[i].Cost < results[j].Cost
+		}
+		if results[i].Predicted.AvgTTFTTime != results[j].Predicted.AvgTTFTTime {
+			return results[i].Predicted.AvgTTFTTime < results[j].Predicted.AvgTTFTTime
+		}
+		return results[i].Predicted.AvgITLTime < results[j].Predicted.AvgITLTime
+	})
+	return results, nil
+}
+
+// find the smallest number of replicas meeting the SLOs for a given batch setting, nil if none
+func (p *Planner) minReplicas(spec *PlanSpec, workload *WorkloadMix, maxBatchSize, maxNumTokens int,
+	model ModelFunction) *PlanResult {
+
+	for n := 1; n <= spec.MaxNumReplicas; n++ {
+		perReplica := workload.Scale(1 / float64(n))
+		prediction, err := PredictMix(perReplica, maxBatchSize, maxNumTokens, p.Parms, model, spec.MixMethod)
+		if err != nil {
+			// most likely unstable at this rate, try more replicas
+			continue
+		}
+		feasible := MeetsSLO(prediction.Aggregate, spec.SLO)
+		for _, c := range prediction.Classes {
+			feasible = feasible && MeetsSLO(c.Predicted, spec.SLO)
+		}
+		if feasible {
+			return &PlanResult{
+				NumReplicas:    n,
+				MaxBatchSize:   maxBatchSize,
+				MaxNumTokens:   maxNumTokens,
+				RatePerReplica: perReplica.TotalRate(),
+				Cost:           float64(n) * spec.CostPerReplica,
+				Predicted:      prediction.Aggregate,
+				Classes:        prediction.Classes,
+			}
+		}
+	}
+	return nil
+}
+
+// check if predicted performance metrics satisfy the service level objectives
+func MeetsSLO(y *config.OutputVars, slo *config.SLO) bool {
+	if slo == nil {
+		return true
+	}
+	if slo.TargetTTFT > 0 && y.AvgTTFTTime > slo.TargetTTFT {
+		return false
+	}
+	if slo.TargetITL > 0 && y.AvgITLTime > slo.TargetITL {
+		return false
+	}
+	return true
+}
+
+// pretty print a ranked table of plan results
+func PlanPrettyPrint(results []*PlanResult) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, " rank \t replicas \t maxBatch \t maxTokens \t rps/replica \t cost \t TTFT(msec) \t ITL(msec)\n")
+	fmt.Fprintln(&b)
+	for i, r := range results {
+		fmt.Fprintf(&b, "%5d \t %8d \t %8d \t %9d \t %11.2f \t %6.2f \t %8.2f \t %8.2f\n",
+			i+1, r.NumReplicas, r.MaxBatchSize, r.MaxNumTokens, r.RatePerReplica, r.Cost,
+			r.Predicted.AvgTTFTTime, r.Predicted.AvgITLTime)
+	}
+	return b.String()
+}
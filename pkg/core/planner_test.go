@@ -0,0 +1,208 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// mockPlannerModel: latency grows linearly with rate, unstable when rate exceeds the batch size
+func mockPlannerModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	if x.RequestRate > float64(x.MaxBatchSize) {
+		return nil, fmt.Errorf("unstable at rate=%v", x.RequestRate)
+	}
+	return &config.OutputVars{
+		AvgTTFTTime: params.Alpha + params.Beta*x.RequestRate,
+		AvgITLTime:  params.Alpha + params.Gamma*x.RequestRate,
+	}, nil
+}
+
+func TestPlanner_Plan(t *testing.T) {
+	params := &config.ModelParams{
+		Alpha: 10.0,
+		Beta:  1.0,
+		Gamma: 0.5,
+	}
+
+	tests := []struct {
+		name        string
+		spec        *PlanSpec
+		expectError bool
+		validateFn  func(t *testing.T, results []*PlanResult)
+	}{
+		{
+			name: "single configuration meets SLO with one replica",
+			spec: &PlanSpec{
+				TotalRequestRate: 10.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+				SLO:              &config.SLO{TargetTTFT: 30.0, TargetITL: 20.0},
+				MaxBatchSizes:    []int{64},
+			},
+			validateFn: func(t *testing.T, results []*PlanResult) {
+				if len(results) != 1 {
+					t.Fatalf("len(results) = %d, want 1", len(results))
+				}
+				if results[0].NumReplicas != 1 {
+					t.Errorf("NumReplicas = %d, want 1", results[0].NumReplicas)
+				}
+				if results[0].MaxNumTokens != config.DefaultMaxNumTokens {
+					t.Errorf("MaxNumTokens = %d, want %d", results[0].MaxNumTokens, config.DefaultMaxNumTokens)
+				}
+			},
+		},
+		{
+			name: "rate split evenly across minimal number of replicas",
+			spec: &PlanSpec{
+				TotalRequestRate: 100.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+				SLO:              &config.SLO{TargetTTFT: 35.0},
+				MaxBatchSizes:    []int{256},
+				CostPerReplica:   2.5,
+			},
+			validateFn: func(t *testing.T, results []*PlanResult) {
+				// TTFT = 10 + 100/n <= 35 requires n >= 4
+				if results[0].NumReplicas != 4 {
+					t.Errorf("NumReplicas = %d, want 4", results[0].NumReplicas)
+				}
+				if results[0].RatePerReplica != 25.0 {
+					t.Errorf("RatePerReplica = %v, want 25.0", results[0].RatePerReplica)
+				}
+				if results[0].Cost != 10.0 {
+					t.Errorf("Cost = %v, want 10.0", results[0].Cost)
+				}
+			},
+		},
+		{
+			name: "results ranked by cost",
+			spec: &PlanSpec{
+				TotalRequestRate: 100.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+				SLO:              &config.SLO{TargetTTFT: 200.0},
+				MaxBatchSizes:    []int{10, 50, 200},
+				MaxNumTokens:     []int{2048, 8192},
+			},
+			validateFn: func(t *testing.T, results []*PlanResult) {
+				if len(results) != 6 {
+					t.Fatalf("len(results) = %d, want 6", len(results))
+				}
+				for i := 1; i < len(results); i++ {
+					if results[i].Cost < results[i-1].Cost {
+						t.Errorf("results not ranked by cost at %d: %v < %v", i, results[i].Cost, results[i-1].Cost)
+					}
+				}
+				// batch size 200 is stable with a single replica
+				if results[0].NumReplicas != 1 || results[0].MaxBatchSize != 200 {
+					t.Errorf("best = %d replicas with batch %d, want 1 with batch 200",
+						results[0].NumReplicas, results[0].MaxBatchSize)
+				}
+				// batch size 10 requires 10 replicas to be stable
+				last := results[len(results)-1]
+				if last.NumReplicas != 10 || last.MaxBatchSize != 10 {
+					t.Errorf("worst = %d replicas with batch %d, want 10 with batch 10",
+						last.NumReplicas, last.MaxBatchSize)
+				}
+			},
+		},
+		{
+			name: "no SLO means any stable configuration",
+			spec: &PlanSpec{
+				TotalRequestRate: 20.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+				MaxBatchSizes:    []int{16},
+			},
+			validateFn: func(t *testing.T, results []*PlanResult) {
+				if results[0].NumReplicas != 2 {
+					t.Errorf("NumReplicas = %d, want 2", results[0].NumReplicas)
+				}
+			},
+		},
+		{
+			name: "infeasible SLO",
+			spec: &PlanSpec{
+				TotalRequestRate: 10.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+				SLO:              &config.SLO{TargetITL: 5.0},
+				MaxNumReplicas:   8,
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid total rate",
+			spec: &PlanSpec{
+				TotalRequestRate: 0.0,
+				InputTokens:      100.0,
+				OutputTokens:     50.0,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := NewPlanner(params)
+			results, err := planner.Plan(tt.spec, mockPlannerModel)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, results)
+			}
+		})
+	}
+}
+
+func TestMeetsSLO(t *testing.T) {
+	y := &config.OutputVars{AvgTTFTTime: 50.0, AvgITLTime: 10.0}
+
+	tests := []struct {
+		name string
+		slo  *config.SLO
+		want bool
+	}{
+		{name: "nil SLO", slo: nil, want: true},
+		{name: "unconstrained SLO", slo: &config.SLO{}, want: true},
+		{name: "both met", slo: &config.SLO{TargetTTFT: 50.0, TargetITL: 10.0}, want: true},
+		{name: "TTFT violated", slo: &config.SLO{TargetTTFT: 49.0, TargetITL: 10.0}, want: false},
+		{name: "ITL violated", slo: &config.SLO{TargetITL: 9.0}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MeetsSLO(y, tt.slo); got != tt.want {
+				t.Errorf("MeetsSLO() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanPrettyPrint(t *testing.T) {
+	results := []*PlanResult{
+		{
+			NumReplicas:    2,
+			MaxBatchSize:   128,
+			MaxNumTokens:   4096,
+			RatePerReplica: 5.0,
+			Cost:           2.0,
+			Predicted:      &config.OutputVars{AvgTTFTTime: 20.0, AvgITLTime: 8.0},
+		},
+	}
+	out := PlanPrettyPrint(results)
+	for _, want := range []string{"replicas", "128", "4096", "5.00", "20.00", "8.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// predicate on a data point of a data set
+type Predicate func(dataSet *DataSet, index int) bool
+
+// value of a field of a data point, either numeric or string
+type FieldValue struct {
+	Num   float64
+	Str   string
+	IsNum bool
+}
+
+func (v FieldValue) String() string {
+	if v.IsNum {
+		return strconv.FormatFloat(v.Num, 'g', -1, 64)
+	}
+	return v.Str
+}
+
+// compare two values of the same field
+func (v FieldValue) compare(other FieldValue) int {
+	if v.IsNum {
+		switch {
+		case v.Num < other.Num:
+			return -1
+		case v.Num > other.Num:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(v.Str, other.Str)
+}
+
+// prefix of label tag fields, e.g. tags.env
+const tagFieldPrefix = "tags."
+
+// numeric fields of data points, by json name
+var numericFields = map[string]func(dp *DataPoint) float64{
+	"requestRate":        func(dp *DataPoint) float64 { return dp.RequestRate },
+	"inputTokens":        func(dp *DataPoint) float64 { return dp.InputTokens },
+	"outputTokens":       func(dp *DataPoint) float64 { return dp.OutputTokens },
+	"avgITLTime":         func(dp *DataPoint) float64 { return dp.AvgITLTime },
+	"avgTTFTTime":        func(dp *DataPoint) float64 { return dp.AvgTTFTTime },
+	"avgWaitTime":        func(dp *DataPoint) float64 { return dp.AvgWaitTime },
+	"avgPrefillTime":     func(dp *DataPoint) float64 { return dp.AvgPrefillTime },
+	"maxBatchSize":       func(dp *DataPoint) float64 { return float64(dp.MaxBatchSize) },
+	"maxNumTokens":       func(dp *DataPoint) float64 { return float64(dp.MaxNumTokens) },
+	"inputTokensStdDev":  func(dp *DataPoint) float64 { return dp.InputTokensStdDev },
+	"outputTokensStdDev": func(dp *DataPoint) float64 { return dp.OutputTokensStdDev },
+}
+
+// label fields, by json name (tensor parallelism is numeric)
+var labelFields = map[string]func(l *Labels) string{
+	"source":        func(l *Labels) string { return l.Source },
+	"benchmarkID":   func(l *Labels) string { return l.BenchmarkID },
+	"strategy":      func(l *Labels) string { return l.Strategy },
+	"model":         func(l *Labels) string { return l.Model },
+	"accelerator":   func(l *Labels) string { return l.Accelerator },
+	"engineVersion": func(l *Labels) string { return l.EngineVersion },
+}
+
+const tensorParallelismField = "tensorParallelism"
+
+// check if a field is known, and whether it is numeric
+func checkField(field string) (isNum bool, err error) {
+	if _, ok := numericFields[field]; ok || field == tensorParallelismField {
+		return true, nil
+	}
+	if _, ok := labelFields[field]; ok || (strings.HasPrefix(field, tagFieldPrefix) && len(field) > len(tagFieldPrefix)) {
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown field %q", field)
+}
+
+// get the value of a field of a data point, given by json name; labels include the data set metadata
+func (dataSet *DataSet) FieldValue(index int, field string) (FieldValue, error) {
+	if get, ok := numericFields[field]; ok {
+		return FieldValue{Num: get(&dataSet.Data[index]), IsNum: true}, nil
+	}
+	if _, err := checkField(field); err != nil {
+		return FieldValue{}, err
+	}
+	labels := dataSet.LabelsOf(index)
+	if labels == nil {
+		labels = &Labels{}
+	}
+	if field == tensorParallelismField {
+		return FieldValue{Num: float64(labels.TensorParallelism), IsNum: true}, nil
+	}
+	if get, ok := labelFields[field]; ok {
+		return FieldValue{Str: get(labels)}, nil
+	}
+	return FieldValue{Str: labels.Tags[strings.TrimPrefix(field, tagFieldPrefix)]}, nil
+}
+
+// get a data set with the data points at given indexes, sharing the name, units and metadata
+func (dataSet *DataSet) subset(indexes []int) *DataSet {
+	sub := &DataSet{
+		Name:     dataSet.Name,
+		Units:    dataSet.Units,
+		Data:     make([]DataPoint, 0, len(indexes)),
+		Metadata: dataSet.Metadata,
+	}
+	for _, i := range indexes {
+		sub.Data = append(sub.Data, dataSet.Data[i])
+	}
+	return sub
+}
+
+// get a data set with the data points satisfying the predicate
+func (dataSet *DataSet) Filter(pred Predicate) *DataSet {
+	indexes := []int{}
+	for i := range dataSet.Data {
+		if pred(dataSet, i) {
+			indexes = append(indexes, i)
+		}
+	}
+	return dataSet.subset(indexes)
+}
+
+// get a data set with the data points satisfying a filter expression (see ParsePredicate)
+func (dataSet *DataSet) FilterExpr(expr string) (*DataSet, error) {
+	pred, err := ParsePredicate(expr)
+	if err != nil {
+		return nil, err
+	}
+	return dataSet.Filter(pred), nil
+}
+
+// get a data set without duplicates of identical data points, keeping the first occurrence
+func (dataSet *DataSet) Dedup() *DataSet {
+	seen := map[string]bool{}
+	indexes := []int{}
+	for i, dp := range dataSet.Data {
+		key, err := json.Marshal(dp)
+		if err != nil {
+			// not comparable (e.g. NaN values), keep it
+			indexes = append(indexes, i)
+			continue
+		}
+		if !seen[string(key)] {
+			seen[string(key)] = true
+			indexes = append(indexes, i)
+		}
+	}
+	return dataSet.subset(indexes)
+}
+
+// sort the data points by the value of a field (stable)
+func (dataSet *DataSet) SortBy(field string, descending bool) error {
+	if _, err := checkField(field); err != nil {
+		return err
+	}
+	values := make([]FieldValue, len(dataSet.Data))
+	for i := range dataSet.Data {
+		values[i], _ = dataSet.FieldValue(i, field)
+	}
+	order := make([]int, len(dataSet.Data))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		c := values[order[a]].compare(values[order[b]])
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	dataSet.Data = dataSet.subset(order).Data
+	return nil
+}
+
+// split the data set randomly into train and test data sets, with a given fraction of test points
+func (dataSet *DataSet) SplitRandom(testFraction float64, seed int64) (train, test *DataSet) {
+	all := make([]int, len(dataSet.Data))
+	for i := range all {
+		all[i] = i
+	}
+	rng := rand.New(rand.NewSource(seed))
+	trainIdx, testIdx := splitIndexes(all, testFraction, rng)
+	return dataSet.subset(trainIdx), dataSet.subset(testIdx)
+}
+
+// split the data set into train and test data sets, with a given fraction of test points
+// drawn randomly from each group of points sharing the value of a field
+func (dataSet *DataSet) SplitStratified(testFraction float64, seed int64, field string) (train, test *DataSet, err error) {
+	if _, err := checkField(field); err != nil {
+		return nil, nil, err
+	}
+	groups := map[string][]int{}
+	for i := range dataSet.Data {
+		v, _ := dataSet.FieldValue(i, field)
+		groups[v.String()] = append(groups[v.String()], i)
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rng := rand.New(rand.NewSource(seed))
+	trainIdx, testIdx := []int{}, []int{}
+	for _, k := range keys {
+		tr, te := splitIndexes(groups[k], testFraction, rng)
+		trainIdx = append(trainIdx, tr...)
+		testIdx = append(testIdx, te...)
+	}
+	sort.Ints(trainIdx)
+	sort.Ints(testIdx)
+	return dataSet.subset(trainIdx), dataSet.subset(testIdx), nil
+}
+
+// split the data set into train and test data sets, the test points being those with
+// a field value among the given ones
+func (dataSet *DataSet) SplitByLabel(field string, testValues ...string) (train, test *DataSet, err error) {
+	if _, err := checkField(field); err != nil {
+		return nil, nil, err
+	}
+	trainIdx, testIdx := []int{}, []int{}
+	for i := range dataSet.Data {
+		v, _ := dataSet.FieldValue(i, field)
+		if slices.Contains(testValues, v.String()) {
+			testIdx = append(testIdx, i)
+		} else {
+			trainIdx = append(trainIdx, i)
+		}
+	}
+	return dataSet.subset(trainIdx), dataSet.subset(testIdx), nil
+}
+
+// randomly split indexes, keeping their order, with a given fraction (rounded) in the second part
+func splitIndexes(indexes []int, fraction float64, rng *rand.Rand) (first, second []int) {
+	numSecond := int(math.Round(math.Max(0, math.Min(1, fraction)) * float64(len(indexes))))
+	isSecond := map[int]bool{}
+	for _, p := range rng.Perm(len(indexes))[:numSecond] {
+		isSecond[p] = true
+	}
+	first, second = []int{}, []int{}
+	for p, i := range indexes {
+		if isSecond[p] {
+			second = append(second, i)
+		} else {
+			first = append(first, i)
+		}
+	}
+	return first, second
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"testing"
+)
+
+func newQueryDataSet() *DataSet {
+	ds := NewDataSet("query")
+	ds.Metadata = &Labels{Model: "llama", TensorParallelism: 4}
+	points := []DataPoint{
+		{RequestRate: 1.0, InputTokens: 100, AvgTTFTTime: 20, Labels: &Labels{Strategy: "synchronous"}},
+		{RequestRate: 50.0, InputTokens: 100, AvgTTFTTime: 900, Labels: &Labels{Strategy: "throughput"}},
+		{RequestRate: 10.0, InputTokens: 100, AvgTTFTTime: 30, Labels: &Labels{Strategy: "constant@10.00"}},
+		{RequestRate: 20.0, InputTokens: 200, AvgTTFTTime: 60, Labels: &Labels{Strategy: "constant@20.00",
+			Tags: map[string]string{"env": "test"}}},
+		{RequestRate: 30.0, InputTokens: 200, AvgTTFTTime: 120, Labels: &Labels{Strategy: "constant@30.00",
+			Model: "qwen"}},
+	}
+	for i := range points {
+		ds.AppendDataPoint(&points[i])
+	}
+	return ds
+}
+
+func rates(ds *DataSet) []float64 {
+	r := []float64{}
+	for _, dp := range ds.Data {
+		r = append(r, dp.RequestRate)
+	}
+	return r
+}
+
+func equalRates(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDataSet_FilterExpr(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		want        []float64
+		expectError bool
+	}{
+		{name: "drop throughput", expr: "strategy != throughput", want: []float64{1, 10, 20, 30}},
+		{name: "numeric comparison", expr: "requestRate < 20", want: []float64{1, 10}},
+		{name: "conjunction", expr: "requestRate >= 10 && inputTokens == 200", want: []float64{20, 30}},
+		{name: "disjunction binds looser", expr: "requestRate == 1 || requestRate > 10 && requestRate < 30", want: []float64{1, 20}},
+		{name: "negation and parentheses", expr: "!(requestRate < 20 || strategy == throughput)", want: []float64{20, 30}},
+		{name: "regular expression", expr: `strategy =~ "^constant@[12]"`, want: []float64{10, 20}},
+		{name: "metadata label", expr: "model == llama", want: []float64{1, 50, 10, 20}},
+		{name: "quoted value", expr: `model == 'qwen'`, want: []float64{30}},
+		{name: "numeric label", expr: "tensorParallelism == 4", want: []float64{1, 50, 10, 20, 30}},
+		{name: "tag", expr: "tags.env == test", want: []float64{20}},
+		{name: "unknown field", expr: "foo < 3", expectError: true},
+		{name: "invalid number", expr: "requestRate < fast", expectError: true},
+		{name: "unsupported string operator", expr: "strategy < b", expectError: true},
+		{name: "missing parenthesis", expr: "(requestRate < 3", expectError: true},
+		{name: "trailing tokens", expr: "requestRate < 3 requestRate", expectError: true},
+		{name: "unterminated string", expr: `model == "llama`, expectError: true},
+	}
+
+	ds := newQueryDataSet()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ds.FilterExpr(tt.expr)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalRates(rates(got), tt.want) {
+				t.Errorf("got rates %v, want %v", rates(got), tt.want)
+			}
+			if got.Metadata != ds.Metadata || got.Units != ds.Units {
+				t.Error("filtered data set must keep metadata and units")
+			}
+		})
+	}
+}
+
+func TestDataSet_Dedup(t *testing.T) {
+	ds := newQueryDataSet()
+	dup := ds.Data[2]
+	dup.Labels = &Labels{Strategy: "constant@10.00"} // same values, different pointer
+	ds.AppendDataPoint(&dup)
+	ds.AppendDataPoint(&ds.Data[0])
+
+	got := ds.Dedup()
+	if want := []float64{1, 50, 10, 20, 30}; !equalRates(rates(got), want) {
+		t.Errorf("got rates %v, want %v", rates(got), want)
+	}
+	if ds.Size() != 7 {
+		t.Error("Dedup() must not modify the data set")
+	}
+}
+
+func TestDataSet_SortBy(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		descending  bool
+		want        []float64
+		expectError bool
+	}{
+		{name: "ascending rate", field: "requestRate", want: []float64{1, 10, 20, 30, 50}},
+		{name: "descending TTFT", field: "avgTTFTTime", descending: true, want: []float64{50, 30, 20, 10, 1}},
+		{name: "stable on ties", field: "inputTokens", want: []float64{1, 50, 10, 20, 30}},
+		{name: "string label", field: "strategy", want: []float64{10, 20, 30, 1, 50}},
+		{name: "unknown field", field: "foo", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := newQueryDataSet()
+			err := ds.SortBy(tt.field, tt.descending)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalRates(rates(ds), tt.want) {
+				t.Errorf("got rates %v, want %v", rates(ds), tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSet_Split(t *testing.T) {
+	t.Run("random split is seeded and complete", func(t *testing.T) {
+		ds := newQueryDataSet()
+		train, test := ds.SplitRandom(0.4, 7)
+		if train.Size() != 3 || test.Size() != 2 {
+			t.Fatalf("sizes = %d/%d, want 3/2", train.Size(), test.Size())
+		}
+		train2, test2 := ds.SplitRandom(0.4, 7)
+		if !equalRates(rates(train), rates(train2)) || !equalRates(rates(test), rates(test2)) {
+			t.Error("same seed must give the same split")
+		}
+		seen := map[float64]int{}
+		for _, r := range append(rates(train), rates(test)...) {
+			seen[r]++
+		}
+		if len(seen) != ds.Size() {
+			t.Errorf("split does not partition the data set: %v", seen)
+		}
+	})
+
+	t.Run("stratified split draws from every group", func(t *testing.T) {
+		ds := newQueryDataSet()
+		train, test, err := ds.SplitStratified(0.5, 1, "inputTokens")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// groups of 3 (100 tokens) and 2 (200 tokens) points: round(1.5)=2 and 1 test points
+		counts := map[float64]int{}
+		for _, dp := range test.Data {
+			counts[dp.InputTokens]++
+		}
+		if counts[100] != 2 || counts[200] != 1 || train.Size() != 2 {
+			t.Errorf("test counts = %v, train size = %d", counts, train.Size())
+		}
+	})
+
+	t.Run("split by label", func(t *testing.T) {
+		ds := newQueryDataSet()
+		train, test, err := ds.SplitByLabel("model", "qwen")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalRates(rates(test), []float64{30}) || train.Size() != 4 {
+			t.Errorf("test rates = %v, train size = %d", rates(test), train.Size())
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		ds := newQueryDataSet()
+		if _, _, err := ds.SplitByLabel("foo", "x"); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if _, _, err := ds.SplitStratified(0.5, 1, "foo"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
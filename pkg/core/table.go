@@ -0,0 +1,400 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// options of exporting a data set as a table, a row per data point, with time fields in milliseconds
+type ExportOptions struct {
+	Labels   bool // add columns of the labels of data points
+	Metadata bool // add the data set metadata to the labels of data points (implies Labels)
+
+	// fitted parameters, adding columns of predicted latencies and residuals (measured - predicted) if set
+	Params    *config.ModelParams
+	Model     ModelFunction    // model of the predictions (default Model)
+	Statistic config.Statistic // statistic of the measured and predicted latencies (default average)
+}
+
+// columns of data point fields, in order of export
+var tableFields = []string{
+	"requestRate", "inputTokens", "outputTokens", "avgTTFTTime", "avgITLTime", "avgWaitTime", "avgPrefillTime",
+	"maxBatchSize", "maxNumTokens", "inputTokensStdDev", "outputTokensStdDev",
+}
+
+// columns of label fields, in order of export, followed by tag columns (e.g. tags.env)
+var tableLabelFields = []string{
+	"source", "benchmarkID", "strategy", "model", "accelerator", tensorParallelismField, "engineVersion",
+}
+
+// columns of percentiles of latency distributions (e.g. ttftPercentiles.p90)
+var (
+	tablePercentileFields = []string{"ttftPercentiles", "itlPercentiles"}
+	tablePercentiles      = []config.Statistic{config.StatisticP50, config.StatisticP90, config.StatisticP95, config.StatisticP99}
+)
+
+// columns of predictions, ignored when importing
+var tablePredictionFields = []string{"predictedTTFTTime", "predictedITLTime", "residualTTFTTime", "residualITLTime"}
+
+// required columns of imported tables
+var tableRequiredFields = []string{"requestRate", "inputTokens", "outputTokens", "avgITLTime"}
+
+// a column of an exported table
+type tableColumn struct {
+	name  string
+	value func(index int) (FieldValue, bool) // value of the column for a data point, false if unset
+}
+
+// get the columns of the exported table of a data set, with times in milliseconds
+func (dataSet *DataSet) tableColumns(opts *ExportOptions) ([]tableColumn, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	columns := []tableColumn{}
+	for _, field := range tableFields {
+		get := numericFields[field]
+		columns = append(columns, tableColumn{
+			name:  field,
+			value: func(i int) (FieldValue, bool) { return FieldValue{Num: get(&dataSet.Data[i]), IsNum: true}, true },
+		})
+	}
+
+	// percentiles, if any data point has them
+	for _, field := range tablePercentileFields {
+		percentiles := func(i int) *config.Percentiles {
+			if field == "ttftPercentiles" {
+				return dataSet.Data[i].TTFTPercentiles
+			}
+			return dataSet.Data[i].ITLPercentiles
+		}
+		hasPercentiles := false
+		for i := range dataSet.Data {
+			if percentiles(i) != nil {
+				hasPercentiles = true
+				break
+			}
+		}
+		if !hasPercentiles {
+			continue
+		}
+		for _, stat := range tablePercentiles {
+			columns = append(columns, tableColumn{
+				name: field + "." + string(stat),
+				value: func(i int) (FieldValue, bool) {
+					v, ok := percentiles(i).Get(stat)
+					return FieldValue{Num: v, IsNum: true}, ok
+				},
+			})
+		}
+	}
+
+	if opts.Labels || opts.Metadata {
+		columns = append(columns, dataSet.labelColumns(opts.Metadata)...)
+	}
+	if opts.Params != nil {
+		predictionColumns, err := dataSet.predictionColumns(opts)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, predictionColumns...)
+	}
+	return columns, nil
+}
+
+// get the columns of labels set for some data point, possibly including the data set metadata
+func (dataSet *DataSet) labelColumns(withMetadata bool) []tableColumn {
+	labels := make([]*Labels, len(dataSet.Data))
+	tags := map[string]bool{}
+	for i := range dataSet.Data {
+		if withMetadata {
+			labels[i] = dataSet.LabelsOf(i)
+		} else {
+			labels[i] = dataSet.Data[i].Labels
+		}
+		if labels[i] == nil {
+			labels[i] = &Labels{}
+		}
+		for k := range labels[i].Tags {
+			tags[k] = true
+		}
+	}
+
+	columns := []tableColumn{}
+	add := func(name string, value func(l *Labels) (FieldValue, bool)) {
+		for _, l := range labels {
+			if _, ok := value(l); ok {
+				columns = append(columns, tableColumn{
+					name:  name,
+					value: func(i int) (FieldValue, bool) { return value(labels[i]) },
+				})
+				return
+			}
+		}
+	}
+	for _, field := range tableLabelFields {
+		if field == tensorParallelismField {
+			add(field, func(l *Labels) (FieldValue, bool) {
+				return FieldValue{Num: float64(l.TensorParallelism), IsNum: true}, l.TensorParallelism != 0
+			})
+			continue
+		}
+		get := labelFields[field]
+		add(field, func(l *Labels) (FieldValue, bool) { return FieldValue{Str: get(l)}, get(l) != "" })
+	}
+	tagNames := make([]string, 0, len(tags))
+	for k := range tags {
+		tagNames = append(tagNames, k)
+	}
+	sort.Strings(tagNames)
+	for _, k := range tagNames {
+		add(tagFieldPrefix+k, func(l *Labels) (FieldValue, bool) {
+			v, ok := l.Tags[k]
+			return FieldValue{Str: v}, ok
+		})
+	}
+	return columns
+}
+
+// get the columns of predicted latencies and residuals; cells are unset for data points the model
+// fails on, and residuals for data points without the measured statistic
+func (dataSet *DataSet) predictionColumns(opts *ExportOptions) ([]tableColumn, error) {
+	stat := opts.Statistic
+	if stat == "" {
+		stat = config.StatisticAverage
+	}
+	if !stat.IsValid() {
+		return nil, fmt.Errorf("unknown statistic %q", stat)
+	}
+	model := opts.Model
+	if model == nil {
+		model = Model
+	}
+	model = PercentileModel(model, stat)
+
+	predicted := make([]*config.OutputVars, len(dataSet.Data))
+	measured := make([]*config.OutputVars, len(dataSet.Data))
+	for i := range dataSet.Data {
+		dp := dataSet.Data[i]
+		x, _ := dp.GetInOutVars()
+		predicted[i], _ = model(x, opts.Params)
+		measured[i], _ = dp.GetOutputVars(stat)
+	}
+	prediction := func(get func(y *config.OutputVars) float64) func(i int) (FieldValue, bool) {
+		return func(i int) (FieldValue, bool) {
+			if predicted[i] == nil {
+				return FieldValue{}, false
+			}
+			return FieldValue{Num: get(predicted[i]), IsNum: true}, true
+		}
+	}
+	residual := func(get func(y *config.OutputVars) float64) func(i int) (FieldValue, bool) {
+		return func(i int) (FieldValue, bool) {
+			if predicted[i] == nil || measured[i] == nil {
+				return FieldValue{}, false
+			}
+			return FieldValue{Num: get(measured[i]) - get(predicted[i]), IsNum: true}, true
+		}
+	}
+	ttft := func(y *config.OutputVars) float64 { return y.AvgTTFTTime }
+	itl := func(y *config.OutputVars) float64 { return y.AvgITLTime }
+	return []tableColumn{
+		{name: tablePredictionFields[0], value: prediction(ttft)},
+		{name: tablePredictionFields[1], value: prediction(itl)},
+		{name: tablePredictionFields[2], value: residual(ttft)},
+		{name: tablePredictionFields[3], value: residual(itl)},
+	}, nil
+}
+
+// write the data set as CSV, with a header row of column names (json names of fields, e.g. requestRate,
+// ttftPercentiles.p90, model, tags.env); unset values are empty cells
+func (dataSet *DataSet) WriteCSV(w io.Writer, opts *ExportOptions) error {
+	msecs, err := dataSet.CopyIn(config.UnitMSecs)
+	if err != nil {
+		return err
+	}
+	columns, err := msecs.tableColumns(opts)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(w)
+	row := make([]string, len(columns))
+	for j, c := range columns {
+		row[j] = c.name
+	}
+	if err := csvWriter.Write(row); err != nil {
+		return err
+	}
+	for i := range msecs.Data {
+		for j, c := range columns {
+			row[j] = ""
+			if v, ok := c.value(i); ok {
+				row[j] = v.String()
+			}
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// write the data set as JSON Lines, a json object per data point, with the columns of WriteCSV as keys
+// in the same order; unset values are left out
+func (dataSet *DataSet) WriteJSONLines(w io.Writer, opts *ExportOptions) error {
+	msecs, err := dataSet.CopyIn(config.UnitMSecs)
+	if err != nil {
+		return err
+	}
+	columns, err := msecs.tableColumns(opts)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	for i := range msecs.Data {
+		b.Reset()
+		b.WriteByte('{')
+		for _, c := range columns {
+			v, ok := c.value(i)
+			if !ok {
+				continue
+			}
+			var value any = v.Str
+			if v.IsNum {
+				if math.IsNaN(v.Num) || math.IsInf(v.Num, 0) {
+					return fmt.Errorf("point %d: %s: not a finite number: %v", i, c.name, v.Num)
+				}
+				value = v.Num
+			}
+			key, _ := json.Marshal(c.name)
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if b.Len() > 1 {
+				b.WriteByte(',')
+			}
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(valueBytes)
+		}
+		b.WriteString("}\n")
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read a data set from CSV in the schema of WriteCSV, with time fields in milliseconds; columns of
+// predictions are ignored, as well as unknown columns, which are returned. Empty cells are unset, and
+// blank lines are skipped.
+func ReadCSV(dataBytes []byte) (dataSet *DataSet, ignored []string, err error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(dataBytes, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV data: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("empty CSV data")
+	}
+	header := make([]string, len(rows[0]))
+	for j, name := range rows[0] {
+		header[j] = strings.TrimSpace(name)
+	}
+	missing := []string{}
+	for _, name := range tableRequiredFields {
+		if !slices.Contains(header, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("missing columns in CSV header: %q", missing)
+	}
+
+	// set a value in the json object of a data point, by column (nil if not imported)
+	setters := make([]func(point map[string]any, value any), len(header))
+	isNum := make([]bool, len(header))
+	ignored = []string{}
+	for j, name := range header {
+		group, key, isNested := strings.Cut(name, ".")
+		switch {
+		case numericFields[name] != nil:
+			setters[j] = func(point map[string]any, value any) { point[name] = value }
+			isNum[j] = true
+		case isNested && slices.Contains(tablePercentileFields, group) && slices.Contains(tablePercentiles, config.Statistic(key)):
+			setters[j] = func(point map[string]any, value any) { nestedObject(point, group)[key] = value }
+			isNum[j] = true
+		case name == tensorParallelismField || labelFields[name] != nil:
+			setters[j] = func(point map[string]any, value any) { nestedObject(point, "labels")[name] = value }
+			isNum[j] = name == tensorParallelismField
+		case strings.HasPrefix(name, tagFieldPrefix) && len(name) > len(tagFieldPrefix):
+			tag := strings.TrimPrefix(name, tagFieldPrefix)
+			setters[j] = func(point map[string]any, value any) {
+				nestedObject(nestedObject(point, "labels"), "tags")[tag] = value
+			}
+		case slices.Contains(tablePredictionFields, name):
+		default:
+			ignored = append(ignored, name)
+		}
+	}
+
+	dataSet = NewDataSet("")
+	for i, row := range rows[1:] {
+		line := i + 2
+		if len(row) > len(header) {
+			return nil, nil, fmt.Errorf("CSV line %d: %d fields, header has %d", line, len(row), len(header))
+		}
+		point := map[string]any{}
+		for j, cell := range row {
+			cell = strings.TrimSpace(cell)
+			if cell == "" || setters[j] == nil {
+				continue
+			}
+			var value any = cell
+			if isNum[j] {
+				x, err := strconv.ParseFloat(cell, 64)
+				if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+					return nil, nil, fmt.Errorf("CSV line %d: %s: invalid number %q", line, header[j], cell)
+				}
+				value = x
+			}
+			setters[j](point, value)
+		}
+		if len(point) == 0 {
+			// skip blank lines
+			continue
+		}
+		pointBytes, err := json.Marshal(point)
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		var dp DataPoint
+		if err := json.Unmarshal(pointBytes, &dp); err != nil {
+			return nil, nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		dataSet.AppendDataPoint(&dp)
+	}
+	return dataSet, ignored, nil
+}
+
+// get a nested json object by key, added if missing
+func nestedObject(object map[string]any, key string) map[string]any {
+	nested, ok := object[key].(map[string]any)
+	if !ok {
+		nested = map[string]any{}
+		object[key] = nested
+	}
+	return nested
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// a model predicting latencies proportional to the request rate, failing above a rate of 40
+func mockTableModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	if x.RequestRate > 40 {
+		return nil, fmt.Errorf("saturated")
+	}
+	return &config.OutputVars{AvgTTFTTime: params.Alpha * x.RequestRate, AvgITLTime: params.Beta * x.RequestRate}, nil
+}
+
+func newTableDataSet() *DataSet {
+	ds := newQueryDataSet()
+	ds.Data[0].TTFTPercentiles = &config.Percentiles{P50: 18, P90: 25, P99: 40}
+	return ds
+}
+
+func TestDataSet_WriteCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *ExportOptions
+		expectError string
+		validateFn  func(t *testing.T, rows []string)
+	}{
+		{
+			name: "fields",
+			validateFn: func(t *testing.T, rows []string) {
+				want := "requestRate,inputTokens,outputTokens,avgTTFTTime,avgITLTime,avgWaitTime,avgPrefillTime," +
+					"maxBatchSize,maxNumTokens,inputTokensStdDev,outputTokensStdDev," +
+					"ttftPercentiles.p50,ttftPercentiles.p90,ttftPercentiles.p95,ttftPercentiles.p99"
+				if rows[0] != want {
+					t.Errorf("got header %q", rows[0])
+				}
+				if len(rows) != 6 || rows[1] != "1,100,0,20,0,0,0,0,0,0,0,18,25,,40" || !strings.HasSuffix(rows[2], "0,,,,") {
+					t.Errorf("unexpected rows %q", rows)
+				}
+			},
+		},
+		{
+			name: "labels of data points",
+			opts: &ExportOptions{Labels: true},
+			validateFn: func(t *testing.T, rows []string) {
+				if !strings.HasSuffix(rows[0], "p99,strategy,model,tags.env") || !strings.HasSuffix(rows[4], "constant@20.00,,test") {
+					t.Errorf("unexpected rows %q", rows)
+				}
+			},
+		},
+		{
+			name: "labels with metadata",
+			opts: &ExportOptions{Metadata: true},
+			validateFn: func(t *testing.T, rows []string) {
+				if !strings.HasSuffix(rows[0], "strategy,model,tensorParallelism,tags.env") ||
+					!strings.HasSuffix(rows[1], "synchronous,llama,4,") || !strings.HasSuffix(rows[5], "qwen,4,") {
+					t.Errorf("unexpected rows %q", rows)
+				}
+			},
+		},
+		{
+			name: "predictions",
+			opts: &ExportOptions{Params: &config.ModelParams{Alpha: 2, Beta: 0.5}, Model: mockTableModel},
+			validateFn: func(t *testing.T, rows []string) {
+				if !strings.HasSuffix(rows[0], "predictedTTFTTime,predictedITLTime,residualTTFTTime,residualITLTime") {
+					t.Errorf("got header %q", rows[0])
+				}
+				// residuals are measured - predicted; no prediction above a rate of 40
+				if !strings.HasSuffix(rows[4], ",40,10,20,-10") || !strings.HasSuffix(rows[2], ",,,,") {
+					t.Errorf("unexpected rows %q", rows)
+				}
+			},
+		},
+		{
+			name: "predictions of a percentile",
+			opts: &ExportOptions{Params: &config.ModelParams{Alpha: 2, Beta: 0.5}, Model: mockTableModel, Statistic: config.StatisticP90},
+			validateFn: func(t *testing.T, rows []string) {
+				// predicted P90 of a deterministic TTFT; no residuals without the measured P90 of ITL
+				cells := strings.Split(rows[1], ",")
+				if n := len(cells); cells[n-4] != "2" || cells[n-3] == "" || cells[n-2] != "" || cells[n-1] != "" {
+					t.Errorf("unexpected row %q", rows[1])
+				}
+			},
+		},
+		{
+			name:        "unknown statistic",
+			opts:        &ExportOptions{Params: &config.ModelParams{Alpha: 2}, Statistic: "p42"},
+			expectError: "unknown statistic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := newTableDataSet().WriteCSV(&b, tt.opts)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n"))
+		})
+	}
+}
+
+func TestDataSet_WriteJSONLines(t *testing.T) {
+	ds := newTableDataSet()
+	ds.Units = config.UnitSecs
+	var b bytes.Buffer
+	if err := ds.WriteJSONLines(&b, &ExportOptions{Metadata: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5", len(lines))
+	}
+	// keys in the order of the columns, unset values left out, times in milliseconds
+	if !strings.HasPrefix(lines[0], `{"requestRate":1,"inputTokens":100,"outputTokens":0,"avgTTFTTime":20000,`) ||
+		!strings.HasSuffix(lines[0], `"ttftPercentiles.p90":25000,"ttftPercentiles.p99":40000,"strategy":"synchronous","model":"llama","tensorParallelism":4}`) {
+		t.Errorf("unexpected line %s", lines[0])
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[3]), &record); err != nil || record["tags.env"] != "test" {
+		t.Errorf("unexpected record %v: %v", record, err)
+	}
+	if ds.Units != config.UnitSecs || ds.Data[0].AvgTTFTTime != 20 {
+		t.Error("exported data set was modified")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectError string
+		validateFn  func(t *testing.T, ds *DataSet, ignored []string)
+	}{
+		{
+			name: "edited spreadsheet",
+			data: "\xef\xbb\xbfrequestRate, inputTokens,outputTokens,avgITLTime,avgTTFTTime,notes,model,tags.env,tensorParallelism,itlPercentiles.p90,residualITLTime\n" +
+				"2,100,50,10,30,rerun,llama,prod,4,12,0.5\n" +
+				",,,,,,,,,,\n" +
+				"4.5,200,50,12,,,,,,,\n",
+			validateFn: func(t *testing.T, ds *DataSet, ignored []string) {
+				if ds.Size() != 2 || !reflect.DeepEqual(ignored, []string{"notes"}) {
+					t.Fatalf("got %d points, ignored %q", ds.Size(), ignored)
+				}
+				want := DataPoint{RequestRate: 2, InputTokens: 100, OutputTokens: 50, AvgITLTime: 10, AvgTTFTTime: 30,
+					ITLPercentiles: &config.Percentiles{P90: 12},
+					Labels:         &Labels{Model: "llama", TensorParallelism: 4, Tags: map[string]string{"env": "prod"}}}
+				if !reflect.DeepEqual(ds.Data[0], want) {
+					t.Errorf("got %+v, want %+v", ds.Data[0], want)
+				}
+				if dp := ds.Data[1]; dp.RequestRate != 4.5 || dp.Labels != nil || dp.ITLPercentiles != nil {
+					t.Errorf("unexpected point %+v", dp)
+				}
+			},
+		},
+		{
+			name:        "missing columns",
+			data:        "requestRate,avgTTFTTime\n1,2\n",
+			expectError: `missing columns in CSV header: ["inputTokens" "outputTokens" "avgITLTime"]`,
+		},
+		{
+			name:        "invalid number",
+			data:        "requestRate,inputTokens,outputTokens,avgITLTime\n1,100,50,NaN\n",
+			expectError: `CSV line 2: avgITLTime: invalid number "NaN"`,
+		},
+		{
+			name:        "fractional batch size",
+			data:        "requestRate,inputTokens,outputTokens,avgITLTime,maxBatchSize\n1,100,50,10,2.5\n",
+			expectError: "CSV line 2",
+		},
+		{
+			name:        "empty",
+			data:        "",
+			expectError: "empty CSV data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, ignored, err := ReadCSV([]byte(tt.data))
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, ds, ignored)
+		})
+	}
+}
+
+func TestReadCSV_RoundTrip(t *testing.T) {
+	ds := newTableDataSet()
+	ds.Data[1].MaxBatchSize, ds.Data[1].OutputTokensStdDev = 256, 12.5
+	ds.Data[2].ITLPercentiles = &config.Percentiles{P50: 0.1, P95: 1.0 / 3}
+	var b bytes.Buffer
+	opts := &ExportOptions{Metadata: true, Params: &config.ModelParams{Alpha: 2, Beta: 0.5}, Model: mockTableModel}
+	if err := ds.WriteCSV(&b, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imported, ignored, err := ReadCSV(b.Bytes())
+	if err != nil || len(ignored) != 0 {
+		t.Fatalf("unexpected error %v, ignored %q", err, ignored)
+	}
+	if imported.Size() != ds.Size() {
+		t.Fatalf("got %d points, want %d", imported.Size(), ds.Size())
+	}
+	for i := range ds.Data {
+		want, got := ds.Data[i], imported.Data[i]
+		want.Labels, got.Labels = nil, nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("point %d: got %+v, want %+v", i, got, want)
+		}
+		if !reflect.DeepEqual(imported.LabelsOf(i), ds.LabelsOf(i)) {
+			t.Errorf("point %d: got labels %+v, want %+v", i, imported.LabelsOf(i), ds.LabelsOf(i))
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// severity of a validation issue
+type Severity string
+
+const (
+	// the data cannot be used for fitting
+	SeverityError Severity = "error"
+	// the data is suspicious, but may be used for fitting
+	SeverityWarning Severity = "warning"
+)
+
+// an issue found in a field of a data point (index -1 for data set level issues)
+type ValidationIssue struct {
+	Index    int      `json:"index"`    // index of the data point in the data set
+	Field    string   `json:"field"`    // json name of the field
+	Severity Severity `json:"severity"` // severity of the issue
+	Message  string   `json:"message"`  // description of the issue
+}
+
+// report of all issues found when validating a data set
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// error returned when refusing to use an invalid data set
+type ValidationError struct {
+	Report *ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	errors := e.Report.Errors()
+	if len(errors) == 0 {
+		return "invalid data set"
+	}
+	return fmt.Sprintf("invalid data set: %d error(s), first: %s", len(errors), errors[0])
+}
+
+func (issue ValidationIssue) String() string {
+	if issue.Index < 0 {
+		return fmt.Sprintf("%s: %s: %s", issue.Severity, issue.Field, issue.Message)
+	}
+	return fmt.Sprintf("%s: point %d: %s: %s", issue.Severity, issue.Index, issue.Field, issue.Message)
+}
+
+// check if the report has any issue of error severity
+func (r *ValidationReport) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+// get issues of error severity
+func (r *ValidationReport) Errors() []ValidationIssue {
+	return r.filter(SeverityError)
+}
+
+// get issues of warning severity
+func (r *ValidationReport) Warnings() []ValidationIssue {
+	return r.filter(SeverityWarning)
+}
+
+func (r *ValidationReport) filter(severity Severity) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *ValidationReport) add(index int, field string, severity Severity, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{
+		Index:    index,
+		Field:    field,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// pretty print a validation report
+func (r *ValidationReport) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Validation: %d error(s), %d warning(s)\n", len(r.Errors()), len(r.Warnings()))
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "  %s\n", issue)
+	}
+	return b.String()
+}
+
+// validate the data set, without modifying it; missing fields that Fix would fill in are not issues
+func (dataSet *DataSet) Validate() *ValidationReport {
+	report := &ValidationReport{
+		Issues: []ValidationIssue{},
+	}
+	if _, err := dataSet.Units.MSecs(); err != nil {
+		report.add(-1, "units", SeverityError, "%v", err)
+	}
+	if len(dataSet.Data) == 0 {
+		report.add(-1, "data", SeverityError, "empty data set")
+	}
+	for i := range dataSet.Data {
+		dataSet.Data[i].validate(i, report)
+	}
+	return report
+}
+
+// validate the fields of a data point, adding issues to the report
+func (dataPoint *DataPoint) validate(index int, report *ValidationReport) {
+	finite := func(field string, v float64) bool {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			report.add(index, field, SeverityError, "not a finite number: %v", v)
+			return false
+		}
+		return true
+	}
+	positive := func(field string, v float64) {
+		if finite(field, v) && v <= 0 {
+			report.add(index, field, SeverityError, "must be positive: %v", v)
+		}
+	}
+	nonNegative := func(field string, v float64) bool {
+		if !finite(field, v) {
+			return false
+		}
+		if v < 0 {
+			report.add(index, field, SeverityError, "must not be negative: %v", v)
+			return false
+		}
+		return true
+	}
+
+	positive("requestRate", dataPoint.RequestRate)
+	positive("inputTokens", dataPoint.InputTokens)
+	positive("outputTokens", dataPoint.OutputTokens)
+	positive("avgITLTime", dataPoint.AvgITLTime)
+	nonNegative("inputTokensStdDev", dataPoint.InputTokensStdDev)
+	nonNegative("outputTokensStdDev", dataPoint.OutputTokensStdDev)
+
+	// TTFT, possibly given as wait time + prefill time
+	okTTFT := nonNegative("avgTTFTTime", dataPoint.AvgTTFTTime)
+	okWait := nonNegative("avgWaitTime", dataPoint.AvgWaitTime)
+	okPrefill := nonNegative("avgPrefillTime", dataPoint.AvgPrefillTime)
+	ttft := dataPoint.AvgTTFTTime
+	if okTTFT && ttft == 0 && okWait && okPrefill {
+		ttft = dataPoint.AvgWaitTime + dataPoint.AvgPrefillTime
+		if ttft <= 0 {
+			report.add(index, "avgTTFTTime", SeverityError, "missing, and avgWaitTime + avgPrefillTime is not positive")
+		}
+	}
+	if itl := dataPoint.AvgITLTime; ttft > 0 && itl > config.DefaultMaxITLToTTFTRatio*ttft {
+		report.add(index, "avgITLTime", SeverityError,
+			"%v exceeds TTFT %v by more than a factor of %v (mixed units?)", itl, ttft, config.DefaultMaxITLToTTFTRatio)
+	}
+
+	if dataPoint.MaxBatchSize < 0 {
+		report.add(index, "maxBatchSize", SeverityWarning, "negative value %d replaced by default %d",
+			dataPoint.MaxBatchSize, config.DefaultMaxBatchSize)
+	}
+	if dataPoint.MaxNumTokens < 0 {
+		report.add(index, "maxNumTokens", SeverityWarning, "negative value %d replaced by default %d",
+			dataPoint.MaxNumTokens, config.DefaultMaxNumTokens)
+	}
+
+	validatePercentiles(index, "ttftPercentiles", dataPoint.TTFTPercentiles, report)
+	validatePercentiles(index, "itlPercentiles", dataPoint.ITLPercentiles, report)
+}
+
+// validate percentiles: finite, non-negative, and non-decreasing
+func validatePercentiles(index int, field string, p *config.Percentiles, report *ValidationReport) {
+	if p == nil {
+		return
+	}
+	values := []float64{p.P50, p.P90, p.P95, p.P99}
+	last := 0.0
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			report.add(index, field, SeverityError, "invalid percentile value: %v", v)
+			return
+		}
+		if v == 0 {
+			// unknown percentile
+			continue
+		}
+		if v < last {
+			report.add(index, field, SeverityWarning, "percentiles not increasing: %+v", *p)
+			return
+		}
+		last = v
+	}
+}
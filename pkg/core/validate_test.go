@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+func validPoint() DataPoint {
+	return DataPoint{
+		RequestRate:  1.0,
+		InputTokens:  100.0,
+		OutputTokens: 50.0,
+		AvgTTFTTime:  20.0,
+		AvgITLTime:   8.0,
+	}
+}
+
+func TestDataSet_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(dp *DataPoint)
+		units      config.TimeUnit
+		empty      bool
+		wantErrors []string // fields with error issues
+		wantWarns  []string // fields with warning issues
+	}{
+		{
+			name:   "valid data point",
+			modify: func(dp *DataPoint) {},
+		},
+		{
+			name: "TTFT from wait and prefill times",
+			modify: func(dp *DataPoint) {
+				dp.AvgTTFTTime = 0
+				dp.AvgWaitTime = 5.0
+				dp.AvgPrefillTime = 15.0
+			},
+		},
+		{
+			name:       "missing TTFT with zero wait and prefill times",
+			modify:     func(dp *DataPoint) { dp.AvgTTFTTime = 0 },
+			wantErrors: []string{"avgTTFTTime"},
+		},
+		{
+			name:       "negative rate",
+			modify:     func(dp *DataPoint) { dp.RequestRate = -1.0 },
+			wantErrors: []string{"requestRate"},
+		},
+		{
+			name: "zero tokens",
+			modify: func(dp *DataPoint) {
+				dp.InputTokens = 0
+				dp.OutputTokens = 0
+			},
+			wantErrors: []string{"inputTokens", "outputTokens"},
+		},
+		{
+			name:       "NaN ITL",
+			modify:     func(dp *DataPoint) { dp.AvgITLTime = math.NaN() },
+			wantErrors: []string{"avgITLTime"},
+		},
+		{
+			name:       "infinite TTFT",
+			modify:     func(dp *DataPoint) { dp.AvgTTFTTime = math.Inf(1) },
+			wantErrors: []string{"avgTTFTTime"},
+		},
+		{
+			name:       "ITL orders of magnitude above TTFT",
+			modify:     func(dp *DataPoint) { dp.AvgTTFTTime = 0.02 },
+			wantErrors: []string{"avgITLTime"},
+		},
+		{
+			name:      "negative batch size",
+			modify:    func(dp *DataPoint) { dp.MaxBatchSize = -8 },
+			wantWarns: []string{"maxBatchSize"},
+		},
+		{
+			name: "decreasing percentiles",
+			modify: func(dp *DataPoint) {
+				dp.TTFTPercentiles = &config.Percentiles{P50: 20.0, P90: 15.0}
+			},
+			wantWarns: []string{"ttftPercentiles"},
+		},
+		{
+			name:       "unknown units",
+			modify:     func(dp *DataPoint) {},
+			units:      "minutes",
+			wantErrors: []string{"units"},
+		},
+		{
+			name:       "empty data set",
+			empty:      true,
+			wantErrors: []string{"data"},
+		},
+	}
+
+	fields := func(issues []ValidationIssue) map[string]bool {
+		m := map[string]bool{}
+		for _, issue := range issues {
+			m[issue.Field] = true
+		}
+		return m
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := NewDataSet("test")
+			if tt.units != "" {
+				ds.Units = tt.units
+			}
+			if !tt.empty {
+				dp := validPoint()
+				tt.modify(&dp)
+				ds.AppendDataPoint(&dp)
+			}
+
+			report := ds.Validate()
+			gotErrors, gotWarns := fields(report.Errors()), fields(report.Warnings())
+			if len(gotErrors) != len(tt.wantErrors) || len(gotWarns) != len(tt.wantWarns) {
+				t.Fatalf("got issues %v, want errors %v and warnings %v", report.Issues, tt.wantErrors, tt.wantWarns)
+			}
+			for _, f := range tt.wantErrors {
+				if !gotErrors[f] {
+					t.Errorf("missing error on field %s, got %v", f, report.Issues)
+				}
+			}
+			for _, f := range tt.wantWarns {
+				if !gotWarns[f] {
+					t.Errorf("missing warning on field %s, got %v", f, report.Issues)
+				}
+			}
+			if report.HasErrors() != (len(tt.wantErrors) > 0) {
+				t.Errorf("HasErrors() = %v, want %v", report.HasErrors(), len(tt.wantErrors) > 0)
+			}
+		})
+	}
+}
+
+func TestDataSet_ValidateIndexes(t *testing.T) {
+	ds := NewDataSet("test")
+	good, bad := validPoint(), validPoint()
+	bad.RequestRate = 0
+	ds.AppendDataPoint(&good)
+	ds.AppendDataPoint(&bad)
+
+	report := ds.Validate()
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1: %v", len(report.Issues), report.Issues)
+	}
+	if issue := report.Issues[0]; issue.Index != 1 || issue.Field != "requestRate" || issue.Severity != SeverityError {
+		t.Errorf("got issue %+v", issue)
+	}
+	if ds.Data[1].RequestRate != 0 {
+		t.Error("Validate() must not modify the data set")
+	}
+}
+
+func TestOptimizer_Strict(t *testing.T) {
+	ds := NewDataSet("test")
+	dp := validPoint()
+	dp.AvgITLTime = math.NaN()
+	ds.AppendDataPoint(&dp)
+
+	optimizer := NewOptimizer(&config.ModelParams{Alpha: 1.0, Beta: 1.0, Gamma: 1.0})
+	optimizer.Strict = true
+	_, err := optimizer.Optimize(ds, mockLinearModel)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(validationErr.Report.Errors()) != 1 {
+		t.Errorf("got %d errors, want 1", len(validationErr.Report.Errors()))
+	}
+}
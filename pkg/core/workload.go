@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// method used to approximate a mix of request classes with the single-class model
+type MixMethod string
+
+const (
+	// evaluate the model once with rate-weighted average token counts;
+	// all classes share the same (aggregate) prediction
+	MixEffectiveAverage MixMethod = "effective-average"
+
+	// evaluate the model once per class, at the equivalent rate of that class alone
+	// generating the same amount of work as the whole mix; aggregates are rate-weighted
+	MixDecomposition MixMethod = "decomposition"
+)
+
+// a workload made of several request classes sharing a server
+type WorkloadMix struct {
+	Classes []config.RequestClass `json:"classes"`
+}
+
+// prediction of performance metrics for a single request class in a mix
+type ClassPrediction struct {
+	Name           string             `json:"name"`           // name of the request class
+	RequestRate    float64            `json:"requestRate"`    // request arrival rate of the class (requests/sec)
+	EquivalentRate float64            `json:"equivalentRate"` // rate at which the model was evaluated (requests/sec)
+	Predicted      *config.OutputVars `json:"predicted"`      // predicted performance metrics of the class
+}
+
+// prediction of performance metrics for a workload mix
+type MixPrediction struct {
+	Method    MixMethod          `json:"method"`    // approximation method
+	Classes   []*ClassPrediction `json:"classes"`   // per-class predictions
+	Aggregate *config.OutputVars `json:"aggregate"` // aggregate (rate-weighted) prediction
+}
+
+func NewWorkloadMix(classes ...config.RequestClass) *WorkloadMix {
+	return &WorkloadMix{
+		Classes: classes,
+	}
+}
+
+// get the total request rate of all classes
+func (mix *WorkloadMix) TotalRate() float64 {
+	total := 0.0
+	for _, c := range mix.Classes {
+		total += c.RequestRate
+	}
+	return total
+}
+
+// get a copy of the mix with all class rates multiplied by a factor
+func (mix *WorkloadMix) Scale(factor float64) *WorkloadMix {
+	scaled := &WorkloadMix{
+		Classes: make([]config.RequestClass, len(mix.Classes)),
+	}
+	for i, c := range mix.Classes {
+		c.RequestRate *= factor
+		scaled.Classes[i] = c
+	}
+	return scaled
+}
+
+// get input variables of a single class with the total rate and rate-weighted average token counts
+func (mix *WorkloadMix) EffectiveInputVars(maxBatchSize, maxNumTokens int) *config.InputVars {
+	total := mix.TotalRate()
+	x := &config.InputVars{
+		RequestRate:  total,
+		MaxBatchSize: maxBatchSize,
+		MaxNumTokens: maxNumTokens,
+	}
+	if total <= 0 {
+		return x
+	}
+	for _, c := range mix.Classes {
+		weight := c.RequestRate / total
+		x.InputTokens += weight * c.InputTokens
+		x.OutputTokens += weight * c.OutputTokens
+	}
+	return x
+}
+
+// check validity of the workload mix
+func (mix *WorkloadMix) Check() error {
+	if len(mix.Classes) == 0 {
+		return fmt.Errorf("empty workload mix")
+	}
+	for i, c := range mix.Classes {
+		if c.RequestRate < 0 {
+			return fmt.Errorf("class %d (%s): invalid request rate %v", i, c.Name, c.RequestRate)
+		}
+		if c.InputTokens <= 0 || c.OutputTokens <= 0 {
+			return fmt.Errorf("class %d (%s): invalid token counts in=%v, out=%v", i, c.Name, c.InputTokens, c.OutputTokens)
+		}
+	}
+	if mix.TotalRate() <= 0 {
+		return fmt.Errorf("invalid total request rate %v", mix.TotalRate())
+	}
+	return nil
+}
+
+// work (service demand) per request, following the load terms of the queueing model:
+// beta*(in+out) + gamma*(in+out/2)*(out-1)
+func requestWork(inTokens, outTokens float64, params *config.ModelParams) float64 {
+	return params.Beta*(inTokens+outTokens) + params.Gamma*(inTokens+outTokens/2)*(outTokens-1)
+}
+
+// predict per-class and aggregate performance metrics of a workload mix on a server
+func PredictMix(mix *WorkloadMix, maxBatchSize, maxNumTokens int, params *config.ModelParams,
+	model ModelFunction, method MixMethod) (*MixPrediction, error) {
+
+	if err := mix.Check(); err != nil {
+		return nil, err
+	}
+	switch method {
+	case MixEffectiveAverage:
+		return predictMixEffectiveAverage(mix, maxBatchSize, maxNumTokens, params, model)
+	case MixDecomposition:
+		return predictMixDecomposition(mix, maxBatchSize, maxNumTokens, params, model)
+	default:
+		return nil, fmt.Errorf("unknown mix method %q", method)
+	}
+}
+
+func predictMixEffectiveAverage(mix *WorkloadMix, maxBatchSize, maxNumTokens int, params *config.ModelParams,
+	model ModelFunction) (*MixPrediction, error) {
+
+	x := mix.EffectiveInputVars(maxBatchSize, maxNumTokens)
+	y, err := model(x, params)
+	if err != nil {
+		return nil, err
+	}
+	prediction := &MixPrediction{
+		Method:    MixEffectiveAverage,
+		Aggregate: y,
+	}
+	for _, c := range mix.Classes {
+		prediction.Classes = append(prediction.Classes, &ClassPrediction{
+			Name:           c.Name,
+			RequestRate:    c.RequestRate,
+			EquivalentRate: x.RequestRate,
+			Predicted:      y,
+		})
+	}
+	return prediction, nil
+}
+
+func predictMixDecomposition(mix *WorkloadMix, maxBatchSize, maxNumTokens int, params *config.ModelParams,
+	model ModelFunction) (*MixPrediction, error) {
+
+	total := mix.TotalRate()
+	prediction := &MixPrediction{
+		Method:    MixDecomposition,
+		Aggregate: &config.OutputVars{},
+	}
+	for i, c := range mix.Classes {
+		// rate of this class alone generating the same work as the whole mix
+		equivalentRate := total
+		if work := requestWork(c.InputTokens, c.OutputTokens, params); work > 0 {
+			equivalentRate = c.RequestRate
+			for j, other := range mix.Classes {
+				if j != i {
+					equivalentRate += other.RequestRate * requestWork(other.InputTokens, other.OutputTokens, params) / work
+				}
+			}
+		}
+		x := &config.InputVars{
+			RequestRate:  equivalentRate,
+			InputTokens:  c.InputTokens,
+			OutputTokens: c.OutputTokens,
+			MaxBatchSize: maxBatchSize,
+			MaxNumTokens: maxNumTokens,
+		}
+		y, err := model(x, params)
+		if err != nil {
+			return nil, fmt.Errorf("class %s: %w", c.Name, err)
+		}
+		prediction.Classes = append(prediction.Classes, &ClassPrediction{
+			Name:           c.Name,
+			RequestRate:    c.RequestRate,
+			EquivalentRate: equivalentRate,
+			Predicted:      y,
+		})
+		weight := c.RequestRate / total
+		prediction.Aggregate.AvgTTFTTime += weight * y.AvgTTFTTime
+		prediction.Aggregate.AvgITLTime += weight * y.AvgITLTime
+	}
+	return prediction, nil
+}
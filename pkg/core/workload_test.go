@@ -0,0 +1,216 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// mockWorkloadModel: TTFT grows with input tokens and rate, ITL grows with rate
+func mockWorkloadModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	return &config.OutputVars{
+		AvgTTFTTime: params.Alpha + params.Beta*x.InputTokens + x.RequestRate,
+		AvgITLTime:  params.Alpha + params.Gamma*x.OutputTokens*x.RequestRate,
+	}, nil
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// a mix with a single class must reduce to the single-class model
+func TestPredictMix_SingleClassReduction(t *testing.T) {
+	params := &config.ModelParams{
+		Alpha: 6.0,
+		Beta:  0.02,
+		Gamma: 0.00005,
+	}
+	x := &config.InputVars{
+		RequestRate:  2.0,
+		InputTokens:  500.0,
+		OutputTokens: 500.0,
+		MaxBatchSize: 256,
+		MaxNumTokens: 8192,
+	}
+	want, err := Model(x, params)
+	if err != nil {
+		t.Fatalf("Model() failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		mix  *WorkloadMix
+	}{
+		{
+			name: "single class",
+			mix: NewWorkloadMix(config.RequestClass{
+				Name:         "only",
+				RequestRate:  2.0,
+				InputTokens:  500.0,
+				OutputTokens: 500.0,
+			}),
+		},
+		{
+			name: "two identical classes splitting the rate",
+			mix: NewWorkloadMix(
+				config.RequestClass{Name: "a", RequestRate: 0.5, InputTokens: 500.0, OutputTokens: 500.0},
+				config.RequestClass{Name: "b", RequestRate: 1.5, InputTokens: 500.0, OutputTokens: 500.0},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		for _, method := range []MixMethod{MixEffectiveAverage, MixDecomposition} {
+			t.Run(tt.name+"/"+string(method), func(t *testing.T) {
+				got, err := PredictMix(tt.mix, x.MaxBatchSize, x.MaxNumTokens, params, Model, method)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got.Method != method {
+					t.Errorf("Method = %v, want %v", got.Method, method)
+				}
+				if !almostEqual(got.Aggregate.AvgTTFTTime, want.AvgTTFTTime) {
+					t.Errorf("aggregate TTFT = %v, want %v", got.Aggregate.AvgTTFTTime, want.AvgTTFTTime)
+				}
+				if !almostEqual(got.Aggregate.AvgITLTime, want.AvgITLTime) {
+					t.Errorf("aggregate ITL = %v, want %v", got.Aggregate.AvgITLTime, want.AvgITLTime)
+				}
+				if len(got.Classes) != len(tt.mix.Classes) {
+					t.Fatalf("len(Classes) = %d, want %d", len(got.Classes), len(tt.mix.Classes))
+				}
+				for _, c := range got.Classes {
+					if !almostEqual(c.EquivalentRate, x.RequestRate) {
+						t.Errorf("class %s: EquivalentRate = %v, want %v", c.Name, c.EquivalentRate, x.RequestRate)
+					}
+					if !almostEqual(c.Predicted.AvgTTFTTime, want.AvgTTFTTime) {
+						t.Errorf("class %s: TTFT = %v, want %v", c.Name, c.Predicted.AvgTTFTTime, want.AvgTTFTTime)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPredictMix(t *testing.T) {
+	params := &config.ModelParams{
+		Alpha: 1.0,
+		Beta:  0.1,
+		Gamma: 0.01,
+	}
+	chat := config.RequestClass{Name: "chat", RequestRate: 3.0, InputTokens: 100.0, OutputTokens: 500.0}
+	summary := config.RequestClass{Name: "summary", RequestRate: 1.0, InputTokens: 2000.0, OutputTokens: 100.0}
+
+	tests := []struct {
+		name        string
+		mix         *WorkloadMix
+		method      MixMethod
+		expectError bool
+		validateFn  func(t *testing.T, got *MixPrediction)
+	}{
+		{
+			name:   "effective average uses rate-weighted token counts",
+			mix:    NewWorkloadMix(chat, summary),
+			method: MixEffectiveAverage,
+			validateFn: func(t *testing.T, got *MixPrediction) {
+				// in = (3*100 + 1*2000)/4 = 575, rate = 4
+				wantTTFT := 1.0 + 0.1*575.0 + 4.0
+				if !almostEqual(got.Aggregate.AvgTTFTTime, wantTTFT) {
+					t.Errorf("aggregate TTFT = %v, want %v", got.Aggregate.AvgTTFTTime, wantTTFT)
+				}
+				for _, c := range got.Classes {
+					if c.Predicted != got.Aggregate {
+						t.Errorf("class %s does not share the aggregate prediction", c.Name)
+					}
+				}
+			},
+		},
+		{
+			name:   "decomposition distinguishes classes",
+			mix:    NewWorkloadMix(chat, summary),
+			method: MixDecomposition,
+			validateFn: func(t *testing.T, got *MixPrediction) {
+				if len(got.Classes) != 2 {
+					t.Fatalf("len(Classes) = %d, want 2", len(got.Classes))
+				}
+				chatPred, summaryPred := got.Classes[0], got.Classes[1]
+				if summaryPred.Predicted.AvgTTFTTime <= chatPred.Predicted.AvgTTFTTime {
+					t.Errorf("summary TTFT %v should exceed chat TTFT %v",
+						summaryPred.Predicted.AvgTTFTTime, chatPred.Predicted.AvgTTFTTime)
+				}
+				// equivalent rates conserve the total work of the mix
+				work := func(c config.RequestClass) float64 {
+					return requestWork(c.InputTokens, c.OutputTokens, params)
+				}
+				totalWork := chat.RequestRate*work(chat) + summary.RequestRate*work(summary)
+				if !almostEqual(chatPred.EquivalentRate*work(chat), totalWork) {
+					t.Errorf("chat equivalent work = %v, want %v", chatPred.EquivalentRate*work(chat), totalWork)
+				}
+				if !almostEqual(summaryPred.EquivalentRate*work(summary), totalWork) {
+					t.Errorf("summary equivalent work = %v, want %v", summaryPred.EquivalentRate*work(summary), totalWork)
+				}
+				// aggregate is the rate-weighted average of the classes
+				wantTTFT := (3.0*chatPred.Predicted.AvgTTFTTime + 1.0*summaryPred.Predicted.AvgTTFTTime) / 4.0
+				if !almostEqual(got.Aggregate.AvgTTFTTime, wantTTFT) {
+					t.Errorf("aggregate TTFT = %v, want %v", got.Aggregate.AvgTTFTTime, wantTTFT)
+				}
+			},
+		},
+		{
+			name:        "empty mix",
+			mix:         NewWorkloadMix(),
+			method:      MixDecomposition,
+			expectError: true,
+		},
+		{
+			name:        "zero total rate",
+			mix:         NewWorkloadMix(config.RequestClass{Name: "idle", InputTokens: 10.0, OutputTokens: 10.0}),
+			method:      MixDecomposition,
+			expectError: true,
+		},
+		{
+			name:        "invalid token counts",
+			mix:         NewWorkloadMix(config.RequestClass{Name: "bad", RequestRate: 1.0, OutputTokens: 10.0}),
+			method:      MixEffectiveAverage,
+			expectError: true,
+		},
+		{
+			name:        "unknown method",
+			mix:         NewWorkloadMix(chat),
+			method:      MixMethod("unknown"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PredictMix(tt.mix, 256, 8192, params, mockWorkloadModel, tt.method)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, got)
+			}
+		})
+	}
+}
+
+func TestWorkloadMix_Scale(t *testing.T) {
+	mix := NewWorkloadMix(
+		config.RequestClass{Name: "a", RequestRate: 2.0, InputTokens: 10.0, OutputTokens: 10.0},
+		config.RequestClass{Name: "b", RequestRate: 6.0, InputTokens: 10.0, OutputTokens: 10.0},
+	)
+	scaled := mix.Scale(0.5)
+	if scaled.TotalRate() != 4.0 {
+		t.Errorf("scaled TotalRate() = %v, want 4.0", scaled.TotalRate())
+	}
+	if mix.TotalRate() != 8.0 {
+		t.Errorf("original TotalRate() = %v, want 8.0 (must not be modified)", mix.TotalRate())
+	}
+}
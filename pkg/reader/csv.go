@@ -0,0 +1,145 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// separator of the levels of hierarchical column names (GuideLLM CSV v2)
+const csvHeaderSeparator = " | "
+
+// maximum number of header rows of a CSV file (GuideLLM CSV v2 uses one per level)
+const csvMaxHeaderRows = 3
+
+// check whether data holds json (as opposed to CSV), ignoring leading spaces and a byte order mark
+func isJSONData(dataBytes []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(dataBytes, []byte("\xef\xbb\xbf")))
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+}
+
+// parse CSV data into records of type T, mapping columns to fields by their json names.
+// Column names may span up to maxHeaderRows rows, joined by " | " (skipping empty cells);
+// the smallest number of header rows holding all required columns is used.
+// Empty cells are left unset; numeric cells are converted to numbers, except for string fields.
+func fromCSVToSpec[T any](dataBytes []byte, required []string, maxHeaderRows int) ([]T, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(dataBytes, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV data: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty CSV data")
+	}
+
+	// find the header
+	var header, missing []string
+	numHeaderRows := 0
+	for k := 1; k <= maxHeaderRows && k <= len(rows); k++ {
+		h := joinHeaderRows(rows[:k])
+		m := missingColumns(h, required)
+		if missing == nil || len(m) < len(missing) {
+			header, missing = h, m
+		}
+		if len(m) == 0 {
+			numHeaderRows = k
+			break
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns in CSV header: %q", missing)
+	}
+
+	var t T
+	stringColumns := stringFields(reflect.TypeOf(t))
+	records := []T{}
+	for i, row := range rows[numHeaderRows:] {
+		line := numHeaderRows + i + 1
+		if len(row) > len(header) {
+			return nil, fmt.Errorf("CSV line %d: %d fields, header has %d", line, len(row), len(header))
+		}
+		values := map[string]any{}
+		for j, cell := range row {
+			cell = strings.TrimSpace(cell)
+			if header[j] == "" || cell == "" {
+				continue
+			}
+			if !stringColumns[header[j]] {
+				if x, err := strconv.ParseFloat(cell, 64); err == nil {
+					if !math.IsNaN(x) && !math.IsInf(x, 0) {
+						values[header[j]] = x
+					}
+					continue
+				}
+			}
+			values[header[j]] = cell
+		}
+		if len(values) == 0 {
+			// skip blank lines
+			continue
+		}
+		dataBytes, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		var record T
+		if err := json.Unmarshal(dataBytes, &record); err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// join header rows into column names, one per column
+func joinHeaderRows(rows [][]string) []string {
+	numColumns := 0
+	for _, row := range rows {
+		numColumns = max(numColumns, len(row))
+	}
+	header := make([]string, numColumns)
+	for j := range header {
+		parts := []string{}
+		for _, row := range rows {
+			if j < len(row) && strings.TrimSpace(row[j]) != "" {
+				parts = append(parts, strings.TrimSpace(row[j]))
+			}
+		}
+		header[j] = strings.Join(parts, csvHeaderSeparator)
+	}
+	return header
+}
+
+// get the required columns missing in a header
+func missingColumns(header []string, required []string) []string {
+	present := map[string]bool{}
+	for _, name := range header {
+		present[name] = true
+	}
+	missing := []string{}
+	for _, name := range required {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// get the json names of the string fields of a struct type
+func stringFields(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" && f.Type.Kind() == reflect.String {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,45 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+	"github.com/llm-inferno/model-trainer/pkg/utils"
+)
+
+// data in the native data set json format
+type DataSetData struct {
+	DataSet *core.DataSet
+}
+
+func NewDataSetData() *DataSetData {
+	return &DataSetData{}
+}
+
+func (d *DataSetData) ReadFrom(dataBytes []byte) error {
+	dataSet, err := utils.FromDataToSpec(dataBytes, core.DataSet{})
+	if err != nil {
+		return err
+	}
+	d.DataSet = dataSet
+	return nil
+}
+
+func (d *DataSetData) CreateDataSet() *core.DataSet {
+	if d.DataSet == nil {
+		return core.NewDataSet("")
+	}
+	return d.DataSet
+}
+
+func (d *DataSetData) Print() {
+	fmt.Print(d.CreateDataSet().DataSetPrettyPrint())
+}
+
+func (d *DataSetData) Dump() string {
+	if jsonStr, err := json.Marshal(d.DataSet); err == nil {
+		return fmt.Sprintf("Data set: %v\n", string(jsonStr))
+	}
+	return "Data set: <unavailable>"
+}
@@ -0,0 +1,65 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// required columns of data sets exported as CSV
+var dataSetCSVRequiredColumns = []string{"requestRate", "inputTokens", "outputTokens", "avgITLTime"}
+
+// data set exported as CSV (see core.DataSet.WriteCSV), possibly edited in a spreadsheet
+type DataSetCSVData struct {
+	DataSet *core.DataSet
+	Ignored []string // columns not in the data set schema
+}
+
+func NewDataSetCSVData() *DataSetCSVData {
+	return &DataSetCSVData{}
+}
+
+func (d *DataSetCSVData) ReadFrom(dataBytes []byte) error {
+	dataSet, ignored, err := core.ReadCSV(dataBytes)
+	if err != nil {
+		return err
+	}
+	dataSet.Name = "CSV data set"
+	d.DataSet, d.Ignored = dataSet, ignored
+	return nil
+}
+
+func (d *DataSetCSVData) CreateDataSet() *core.DataSet {
+	if d.DataSet == nil {
+		return core.NewDataSet("")
+	}
+	return d.DataSet
+}
+
+func (d *DataSetCSVData) Warnings() []string {
+	if len(d.Ignored) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("ignored columns %q", d.Ignored)}
+}
+
+func (d *DataSetCSVData) Print() {
+	fmt.Print(d.CreateDataSet().DataSetPrettyPrint())
+}
+
+func (d *DataSetCSVData) Dump() string {
+	var b bytes.Buffer
+	if err := d.CreateDataSet().WriteCSV(&b, &core.ExportOptions{Metadata: true}); err != nil {
+		return "CSV data set: <unavailable>"
+	}
+	return fmt.Sprintf("CSV data set:\n%s", b.String())
+}
+
+// sniff CSV data (not json) with the columns of an exported data set
+func sniffDataSetCSV(dataBytes []byte, filename string) error {
+	if isJSONData(dataBytes) {
+		return fmt.Errorf("not CSV")
+	}
+	return sniffCSV(dataSetCSVRequiredColumns, 1)(dataBytes, filename)
+}
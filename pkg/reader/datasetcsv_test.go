@@ -0,0 +1,62 @@
+package reader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDataSetCSVData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, d *DataSetCSVData)
+	}{
+		{
+			name: "exported data set",
+			data: readTestFile(t, "testdata/dataset.csv"),
+			validateFn: func(t *testing.T, d *DataSetCSVData) {
+				ds := d.CreateDataSet()
+				if ds.Size() != 3 || d.Warnings() != nil {
+					t.Fatalf("got %d points, warnings %q", ds.Size(), d.Warnings())
+				}
+				labels := ds.LabelsOf(1)
+				if labels.Strategy != "constant@27.13" || labels.Tags["ttftStat"] != "median" || ds.Data[1].TTFTPercentiles.P99 == 0 {
+					t.Errorf("unexpected point %+v with labels %+v", ds.Data[1], labels)
+				}
+			},
+		},
+		{
+			name: "edited with extra columns",
+			data: []byte("requestRate,inputTokens,outputTokens,avgITLTime,avgTTFTTime,comment,predictedITLTime\n1,100,50,8,30,ok,7\n"),
+			validateFn: func(t *testing.T, d *DataSetCSVData) {
+				if want := []string{`ignored columns ["comment"]`}; !reflect.DeepEqual(d.Warnings(), want) {
+					t.Errorf("got warnings %q, want %q", d.Warnings(), want)
+				}
+			},
+		},
+		{
+			name:        "missing columns",
+			data:        []byte("requestRate,inputTokens\n1,100\n"),
+			expectError: "missing columns in CSV header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDataSetCSVData()
+			err := d.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, d)
+		})
+	}
+}
@@ -1,5 +1,16 @@
 package reader
 
 const (
+	// maximum number of data points read from a benchmark file by DefaultOptions
 	DefaultLimitNumDataPoints = 7
+
+	// default fraction of the saturation rate below which points are selected
+	DefaultSaturationThreshold = 0.9
+
+	// default aggregation of request traces: length of time windows, warm-up and cool-down periods
+	// discarded (sec), and minimum number of requests of a data point
+	DefaultTraceWindow      = 60.0
+	DefaultTraceWarmUp      = 10.0
+	DefaultTraceCoolDown    = 10.0
+	DefaultTraceMinRequests = 10
 )
@@ -0,0 +1,412 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// data in NVIDIA GenAI-Perf exports (profile_export_genai_perf.json or .csv), one run per export
+type GenAIPerfData struct {
+	Runs []RunGenAIPerf
+	readerOptions
+}
+
+// statistics of a run exported by GenAI-Perf
+type RunGenAIPerf struct {
+	RequestThroughput     GenAIPerfMetric `json:"request_throughput"` // requests/sec
+	RequestLatency        GenAIPerfMetric `json:"request_latency"`
+	TimeToFirstToken      GenAIPerfMetric `json:"time_to_first_token"`
+	InterTokenLatency     GenAIPerfMetric `json:"inter_token_latency"`
+	OutputTokenThroughput GenAIPerfMetric `json:"output_token_throughput"`
+	InputSequenceLength   GenAIPerfMetric `json:"input_sequence_length"`  // tokens
+	OutputSequenceLength  GenAIPerfMetric `json:"output_sequence_length"` // tokens
+
+	// command line options of the run (nested in recent versions), absent from CSV exports
+	InputConfig map[string]any `json:"input_config,omitempty"`
+
+	ID string `json:"-"` // directory of the export, when read by ReadDir
+}
+
+// statistics of a metric, in the unit given (e.g. ms, or ns in early versions)
+type GenAIPerfMetric struct {
+	Unit        string             `json:"unit"`
+	Avg         float64            `json:"avg"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Std         float64            `json:"std"`
+	Percentiles map[string]float64 `json:"-"` // keyed like p99
+}
+
+// names of GenAI-Perf exports in an artifacts directory end with these suffixes
+const (
+	genAIPerfJSONSuffix = "_genai_perf.json"
+	genAIPerfCSVSuffix  = "_genai_perf.csv"
+)
+
+// keys of percentiles of GenAI-Perf metrics, e.g. p99
+var genAIPerfPercentileKey = regexp.MustCompile(`^p[0-9.]+$`)
+
+// CSV rows of GenAI-Perf metrics, e.g. "Time To First Token (ms)"
+var genAIPerfCSVMetric = regexp.MustCompile(`^(.+?)\s*\((.*)\)$`)
+
+func (m *GenAIPerfMetric) UnmarshalJSON(data []byte) error {
+	type plainGenAIPerfMetric GenAIPerfMetric
+	if err := json.Unmarshal(data, (*plainGenAIPerfMetric)(m)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Percentiles = map[string]float64{}
+	for key, value := range raw {
+		if genAIPerfPercentileKey.MatchString(key) {
+			var v float64
+			if err := json.Unmarshal(value, &v); err != nil {
+				return fmt.Errorf("invalid %s: %s", key, string(value))
+			}
+			m.Percentiles[key] = v
+		}
+	}
+	return nil
+}
+
+// get the factor converting a duration in a unit to msec (msec if no unit)
+func timeScale(unit string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "ns":
+		return 1e-6, nil
+	case "us", "µs":
+		return 1e-3, nil
+	case "ms", "":
+		return 1, nil
+	case "s", "sec":
+		return 1000, nil
+	}
+	return 0, fmt.Errorf("unsupported time unit %q", unit)
+}
+
+// get the statistics of a metric, scaled by a factor
+func (m *GenAIPerfMetric) stats(scale float64) metricStats {
+	scaled := map[string]float64{}
+	for key, v := range m.Percentiles {
+		scaled[key] = v * scale
+	}
+	return metricStats{Mean: m.Avg * scale, Median: scaled["p50"], Percentiles: percentilesFromMap(scaled)}
+}
+
+// get the latency metrics of a run, by json key
+func (r *RunGenAIPerf) latencies() map[string]*GenAIPerfMetric {
+	return map[string]*GenAIPerfMetric{
+		"request_latency":     &r.RequestLatency,
+		"time_to_first_token": &r.TimeToFirstToken,
+		"inter_token_latency": &r.InterTokenLatency,
+	}
+}
+
+// get the metrics of a run, by json key
+func (r *RunGenAIPerf) metrics() map[string]*GenAIPerfMetric {
+	metrics := r.latencies()
+	metrics["request_throughput"] = &r.RequestThroughput
+	metrics["output_token_throughput"] = &r.OutputTokenThroughput
+	metrics["input_sequence_length"] = &r.InputSequenceLength
+	metrics["output_sequence_length"] = &r.OutputSequenceLength
+	return metrics
+}
+
+// find the first value of one of the keys in the input configuration, at any depth
+func (r *RunGenAIPerf) config(keys ...string) any {
+	var find func(v any) any
+	find = func(v any) any {
+		object, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for _, key := range keys {
+			if value, ok := object[key]; ok && value != nil {
+				return value
+			}
+		}
+		names := []string{}
+		for key := range object {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := find(object[name]); value != nil {
+				return value
+			}
+		}
+		return nil
+	}
+	return find(r.InputConfig)
+}
+
+// get the model of a run, given as a name or a list of names
+func (r *RunGenAIPerf) model() string {
+	switch v := r.config("model", "model_names").(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			if s, ok := v[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// get the strategy of a run, named as GuideLLM strategies; perf_analyzer sends requests at
+// a constant rate by default
+func (r *RunGenAIPerf) strategy() string {
+	if n, ok := positiveInt(r.config("concurrency")); ok {
+		return fmt.Sprintf("%s@%d", StrategyConcurrent, n)
+	}
+	if rate, ok := r.config("request_rate").(float64); ok && rate > 0 {
+		return fmt.Sprintf("%s@%.2f", StrategyConstant, rate)
+	}
+	return ""
+}
+
+func NewGenAIPerfData() *GenAIPerfData {
+	return &GenAIPerfData{}
+}
+
+// read a GenAI-Perf export, in json or CSV
+func (g *GenAIPerfData) ReadFrom(dataBytes []byte) error {
+	run, err := decodeGenAIPerf(dataBytes)
+	if err != nil {
+		return err
+	}
+	g.Runs = []RunGenAIPerf{*run}
+	return nil
+}
+
+// ReadDir reads the GenAI-Perf exports in a directory and its subdirectories (an artifacts directory
+// holds one subdirectory per run), the CSV export of a run only if it has no json export; runs are
+// ordered by request throughput
+func (g *GenAIPerfData) ReadDir(dir string) error {
+	paths := map[string]string{} // export of each directory
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		name := strings.ToLower(entry.Name())
+		exportDir := filepath.Dir(path)
+		switch {
+		case strings.HasSuffix(name, genAIPerfJSONSuffix):
+			paths[exportDir] = path
+		case strings.HasSuffix(name, genAIPerfCSVSuffix):
+			if _, ok := paths[exportDir]; !ok {
+				paths[exportDir] = path
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	runs := []RunGenAIPerf{}
+	for exportDir, path := range paths {
+		dataBytes, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		run, err := decodeGenAIPerf(dataBytes)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if run.ID, err = filepath.Rel(dir, exportDir); err != nil || run.ID == "." {
+			run.ID = filepath.Base(exportDir)
+		}
+		runs = append(runs, *run)
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no GenAI-Perf exports in %s", dir)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].RequestThroughput.Avg != runs[j].RequestThroughput.Avg {
+			return runs[i].RequestThroughput.Avg < runs[j].RequestThroughput.Avg
+		}
+		return runs[i].ID < runs[j].ID
+	})
+	g.Runs = runs
+	return nil
+}
+
+// decode a GenAI-Perf export, in json or CSV, checking the units of latencies
+func decodeGenAIPerf(dataBytes []byte) (*RunGenAIPerf, error) {
+	run := &RunGenAIPerf{}
+	if isJSONData(dataBytes) {
+		if err := json.Unmarshal(dataBytes, run); err != nil {
+			return nil, fmt.Errorf("invalid GenAI-Perf export: %w", err)
+		}
+	} else if err := run.readCSV(dataBytes); err != nil {
+		return nil, err
+	}
+	if run.TimeToFirstToken.Avg == 0 || run.RequestThroughput.Avg == 0 {
+		return nil, fmt.Errorf("no time to first token or request throughput in GenAI-Perf export")
+	}
+	for key, metric := range run.latencies() {
+		if _, err := timeScale(metric.Unit); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return run, nil
+}
+
+// read a CSV export: a table of latency and token count statistics (Metric,avg,min,max,p99,...),
+// followed by a table of throughputs (Metric,Value); numbers may have thousands separators
+func (r *RunGenAIPerf) readCSV(dataBytes []byte) error {
+	csvReader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(dataBytes, []byte("\xef\xbb\xbf"))))
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid GenAI-Perf CSV export: %w", err)
+	}
+	metrics := r.metrics()
+	var header []string
+	for i, record := range records {
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(record[0]), "Metric") {
+			header = record
+			continue
+		}
+		m := genAIPerfCSVMetric.FindStringSubmatch(strings.TrimSpace(record[0]))
+		if header == nil || m == nil {
+			continue
+		}
+		metric, ok := metrics[strings.ReplaceAll(strings.ToLower(m[1]), " ", "_")]
+		if !ok {
+			continue
+		}
+		metric.Unit = m[2]
+		if strings.HasPrefix(metric.Unit, "per ") || metric.Unit == "tokens" {
+			metric.Unit = ""
+		}
+		metric.Percentiles = map[string]float64{}
+		for k := 1; k < len(record) && k < len(header); k++ {
+			cell := strings.ReplaceAll(strings.TrimSpace(record[k]), ",", "")
+			if cell == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return fmt.Errorf("row %d: invalid %s of %s: %q", i+1, header[k], record[0], record[k])
+			}
+			switch column := strings.ToLower(strings.TrimSpace(header[k])); {
+			case column == "avg" || column == "value":
+				metric.Avg = v
+			case column == "min":
+				metric.Min = v
+			case column == "max":
+				metric.Max = v
+			case column == "std":
+				metric.Std = v
+			case genAIPerfPercentileKey.MatchString(column):
+				metric.Percentiles[column] = v
+			}
+		}
+	}
+	return nil
+}
+
+// create a data set object from the runs, selected by the reader options; the time per output token
+// is the mean request latency over the mean output length
+func (g *GenAIPerfData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
+	for i := range g.Runs {
+		run := &g.Runs[i]
+		ttftScale, _ := timeScale(run.TimeToFirstToken.Unit)
+		itlScale, _ := timeScale(run.InterTokenLatency.Unit)
+		latencyScale, _ := timeScale(run.RequestLatency.Unit)
+		ttftStats := run.TimeToFirstToken.stats(ttftScale)
+		itlStats := run.InterTokenLatency.stats(itlScale)
+		tpotStats := metricStats{}
+		if run.OutputSequenceLength.Avg > 0 {
+			tpotStats.Mean = run.RequestLatency.Avg * latencyScale / run.OutputSequenceLength.Avg
+			tpotStats.Median = tpotStats.Mean
+		}
+		ttft, itl, input, output := g.values(&benchmarkStats{
+			TTFT:         ttftStats,
+			ITL:          itlStats,
+			TPOT:         tpotStats,
+			InputTokens:  run.InputSequenceLength.stats(1),
+			OutputTokens: run.OutputSequenceLength.stats(1),
+		})
+		maxBatchSize, maxNumTokens := serverFromMetadata(run.InputConfig).limits()
+		points = append(points, &core.DataPoint{
+			RequestRate:        run.RequestThroughput.Avg,
+			InputTokens:        input,
+			OutputTokens:       output,
+			InputTokensStdDev:  run.InputSequenceLength.Std,
+			OutputTokensStdDev: run.OutputSequenceLength.Std,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    ttftStats.Percentiles,
+			ITLPercentiles:     itlStats.Percentiles,
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: run.ID,
+				Strategy:    run.strategy(),
+				Model:       run.model(),
+			},
+		})
+	}
+	return g.createDataSet("GenAI-Perf data", points)
+}
+
+func (g *GenAIPerfData) Print() {
+	for _, run := range g.Runs {
+		fmt.Printf("Run: %s\n", run.ID)
+		fmt.Printf("  Model: %s\n", run.model())
+		fmt.Printf("  Strategy: %s\n", run.strategy())
+		fmt.Printf("  RPS: %.2f\n", run.RequestThroughput.Avg)
+		fmt.Printf("  Tokens: Input=%.2f, Output=%.2f\n", run.InputSequenceLength.Avg, run.OutputSequenceLength.Avg)
+		fmt.Printf("  TTFT: Avg=%.2f, Std=%.2f (%s)\n", run.TimeToFirstToken.Avg, run.TimeToFirstToken.Std, run.TimeToFirstToken.Unit)
+		fmt.Printf("  ITL: Avg=%.2f, Std=%.2f (%s)\n", run.InterTokenLatency.Avg, run.InterTokenLatency.Std, run.InterTokenLatency.Unit)
+	}
+}
+
+func (g *GenAIPerfData) Dump() string {
+	if jsonStr, err := json.Marshal(g); err == nil {
+		return fmt.Sprintf("GenAI-Perf data: %v\n", string(jsonStr))
+	}
+	return "GenAI-Perf data: <unavailable>"
+}
+
+// sniff GenAI-Perf exports: a json object with time to first token and request throughput metrics,
+// or CSV with a time to first token row
+func sniffGenAIPerf(dataBytes []byte, filename string) error {
+	if !isJSONData(dataBytes) {
+		if !bytes.Contains(dataBytes, []byte("Time To First Token (")) {
+			return fmt.Errorf("no \"Time To First Token\" row")
+		}
+		return nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(dataBytes, &object); err != nil {
+		return fmt.Errorf("not a json object")
+	}
+	for _, key := range []string{"time_to_first_token", "request_throughput"} {
+		if _, ok := object[key]; !ok {
+			return fmt.Errorf("no %q key", key)
+		}
+	}
+	return nil
+}
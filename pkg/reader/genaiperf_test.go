@@ -0,0 +1,111 @@
+package reader
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGenAIPerfData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, g *GenAIPerfData)
+	}{
+		{
+			name: "json export",
+			data: readTestFile(t, "testdata/genai-perf/qwen-openai-chat-concurrency4/profile_export_genai_perf.json"),
+			validateFn: func(t *testing.T, g *GenAIPerfData) {
+				dp := g.CreateDataSet().Data[0]
+				if dp.RequestRate != 3.6 || dp.InputTokens != 511.7 || dp.OutputTokens != 128.5 || dp.InputTokensStdDev != 10.3 {
+					t.Errorf("unexpected rate or token counts %+v", dp)
+				}
+				if dp.AvgTTFTTime != 40.1 || dp.AvgITLTime != 9.5 || dp.TTFTPercentiles == nil || dp.TTFTPercentiles.P99 != 70 {
+					t.Errorf("unexpected latencies %+v", dp)
+				}
+				// model and concurrency nested in the input configuration
+				if dp.Labels.Model != "Qwen/Qwen2.5-0.5B" || dp.Labels.Strategy != "concurrent@4" {
+					t.Errorf("unexpected labels %+v", dp.Labels)
+				}
+				g.SetOptions(&Options{ITLMetric: MetricTPOT})
+				if dp := g.CreateDataSet().Data[0]; math.Abs(dp.AvgITLTime-1249.5/128.5) > 1e-9 {
+					t.Errorf("got TPOT %v, want %v", dp.AvgITLTime, 1249.5/128.5)
+				}
+			},
+		},
+		{
+			name: "csv export in nanoseconds",
+			data: readTestFile(t, "testdata/genai-perf/qwen-openai-chat-concurrency1/profile_export_genai_perf.csv"),
+			validateFn: func(t *testing.T, g *GenAIPerfData) {
+				dp := g.CreateDataSet().Data[0]
+				if dp.RequestRate != 0.96 || dp.InputTokens != 512 || dp.OutputTokens != 128 {
+					t.Errorf("unexpected rate or token counts %+v", dp)
+				}
+				if math.Abs(dp.AvgTTFTTime-24) > 1e-9 || math.Abs(dp.AvgITLTime-8) > 1e-9 || math.Abs(dp.TTFTPercentiles.P99-39) > 1e-9 {
+					t.Errorf("latencies not converted to msec %+v", dp)
+				}
+			},
+		},
+		{
+			name: "flat input configuration",
+			data: []byte(`{"request_throughput": {"avg": 2.4}, "time_to_first_token": {"unit": "s", "avg": 0.05},
+				"input_config": {"model": "granite", "request_rate": 2.5}}`),
+			validateFn: func(t *testing.T, g *GenAIPerfData) {
+				g.SetOptions(&Options{TTFTStat: StatMean})
+				dp := g.CreateDataSet().Data[0]
+				if dp.AvgTTFTTime != 50 || dp.Labels.Model != "granite" || dp.Labels.Strategy != "constant@2.50" {
+					t.Errorf("unexpected data point %+v, labels %+v", dp, dp.Labels)
+				}
+			},
+		},
+		{
+			name:        "unsupported unit",
+			data:        []byte(`{"request_throughput": {"avg": 1}, "time_to_first_token": {"unit": "ms", "avg": 20}, "inter_token_latency": {"unit": "ticks", "avg": 3}}`),
+			expectError: `inter_token_latency: unsupported time unit "ticks"`,
+		},
+		{
+			name:        "invalid csv value",
+			data:        []byte("Metric,avg,p50\nTime To First Token (ms),fast,20\n"),
+			expectError: "row 2: invalid avg of Time To First Token (ms)",
+		},
+		{
+			name:        "no metrics",
+			data:        []byte("Metric,Value\nRequest Count (count),10\n"),
+			expectError: "no time to first token or request throughput",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenAIPerfData()
+			err := g.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, g)
+		})
+	}
+}
+
+func TestGenAIPerfData_ReadDir(t *testing.T) {
+	g := NewGenAIPerfData()
+	if err := g.ReadDir("testdata/genai-perf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Runs) != 2 || g.Runs[0].ID != "qwen-openai-chat-concurrency1" || g.Runs[1].RequestThroughput.Avg != 3.6 {
+		t.Fatalf("runs not read in order of throughput: %+v", g.Runs)
+	}
+	if ds := g.CreateDataSet(); ds.Data[0].Labels.BenchmarkID != "qwen-openai-chat-concurrency1" {
+		t.Errorf("unexpected benchmark ID %q", ds.Data[0].Labels.BenchmarkID)
+	}
+	if err := NewGenAIPerfData().ReadDir(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without exports")
+	}
+}
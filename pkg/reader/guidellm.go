@@ -2,9 +2,10 @@ package reader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 
-	"github.com/llm-inferno/model-trainer/pkg/config"
 	"github.com/llm-inferno/model-trainer/pkg/core"
 	"github.com/llm-inferno/model-trainer/pkg/utils"
 )
@@ -12,11 +13,30 @@ import (
 // data in a GuideLLM sweep json results file
 type GuideLLMData struct {
 	Benchmarks []Benchmark `json:"benchmarks"`
+	readerOptions
 }
 
 type Benchmark struct {
-	ID      string  `json:"id_"`
-	Metrics Metrics `json:"metrics"`
+	ID      string         `json:"id_"`
+	Args    BenchmarkArgs  `json:"args"`
+	Worker  Worker         `json:"worker"`
+	Metrics Metrics        `json:"metrics"`
+	Extras  map[string]any `json:"extras"`
+}
+
+type BenchmarkArgs struct {
+	Strategy Strategy `json:"strategy"`
+}
+
+type Strategy struct {
+	Type string  `json:"type_"`
+	Rate float64 `json:"rate"`
+}
+
+type Worker struct {
+	BackendModel  string         `json:"backend_model"`
+	BackendTarget string         `json:"backend_target"`
+	BackendInfo   map[string]any `json:"backend_info"`
 }
 
 type Metrics struct {
@@ -39,6 +59,13 @@ type MetricMeasures struct {
 	Mean   float64 `json:"mean"`
 	Median float64 `json:"median"`
 	STDev  float64 `json:"std_dev"`
+
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// get the statistics of measures
+func (m *MetricMeasures) stats() metricStats {
+	return metricStats{Mean: m.Mean, Median: m.Median, Percentiles: percentilesFromMap(m.Percentiles)}
 }
 
 func NewGuideLLMData() *GuideLLMData {
@@ -55,25 +82,57 @@ func (g *GuideLLMData) ReadFrom(dataBytes []byte) error {
 	return nil
 }
 
-// create a data set object from benchmark data
-func (g *GuideLLMData) CreateDataSet() *core.DataSet {
-	dataSet := core.NewDataSet("GuideLLM benchmark data")
+// decode the results from a stream, without holding the json text in memory
+func (g *GuideLLMData) ReadFromStream(in io.Reader) error {
+	decoder := json.NewDecoder(in)
+	var benchmarks GuideLLMData
+	if err := decoder.Decode(&benchmarks); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid data after the json results")
+	}
+	g.Benchmarks = benchmarks.Benchmarks
+	return nil
+}
 
+// create a data set object from benchmark data, selected by the reader options
+func (g *GuideLLMData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
 	for _, benchmark := range g.Benchmarks {
 		metrics := benchmark.Metrics
+		ttft, itl, inputTokens, outputTokens := g.values(&benchmarkStats{
+			TTFT:         metrics.TTFT.Successful.stats(),
+			ITL:          metrics.ITL.Successful.stats(),
+			TPOT:         metrics.TPOT.Successful.stats(),
+			InputTokens:  metrics.InputTokens.Successful.stats(),
+			OutputTokens: metrics.OutputTokens.Successful.stats(),
+		})
+		maxBatchSize, maxNumTokens := serverFromMetadata(map[string]any{
+			"backend_info": benchmark.Worker.BackendInfo,
+			"extras":       benchmark.Extras,
+		}).limits()
 		dataPoint := &core.DataPoint{
-			RequestRate:  metrics.RPS.Successful.Mean,
-			InputTokens:  metrics.InputTokens.Successful.Mean,
-			OutputTokens: metrics.OutputTokens.Successful.Mean,
-			AvgTTFTTime:  metrics.TTFT.Successful.Median, // using median instead of mean since TTFT has a long tail
-			AvgITLTime:   metrics.ITL.Successful.Mean,
-			// TODO: how to get the max batch size and max num tokens from the data?
-			MaxBatchSize: config.DefaultMaxBatchSize,
-			MaxNumTokens: config.DefaultMaxNumTokens,
+			RequestRate:        metrics.RPS.Successful.Mean,
+			InputTokens:        inputTokens,
+			OutputTokens:       outputTokens,
+			InputTokensStdDev:  metrics.InputTokens.Successful.STDev,
+			OutputTokensStdDev: metrics.OutputTokens.Successful.STDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    percentilesFromMap(metrics.TTFT.Successful.Percentiles),
+			ITLPercentiles:     percentilesFromMap(metrics.ITL.Successful.Percentiles),
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: benchmark.ID,
+				Strategy:    benchmark.Args.Strategy.Type,
+				Model:       benchmark.Worker.BackendModel,
+			},
 		}
-		dataSet.AppendDataPoint(dataPoint)
+		points = append(points, dataPoint)
 	}
-	return dataSet
+	return g.createDataSet("GuideLLM benchmark data", points)
 }
 
 func (g *GuideLLMData) Print() {
@@ -89,6 +148,7 @@ func (g *GuideLLMData) Print() {
 		itl := metrics.ITL.Successful
 
 		fmt.Printf("Benchmark ID: %s\n", benchmark.ID)
+		fmt.Printf("  Strategy: %s, Model: %s\n", benchmark.Args.Strategy.Type, benchmark.Worker.BackendModel)
 		fmt.Printf("  RPS: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", rps.Mean, rps.Median, rps.STDev)
 		fmt.Printf("  Concurrency: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", concurrency.Mean, concurrency.Median, concurrency.STDev)
 		fmt.Printf("  Latency: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", latency.Mean, latency.Median, latency.STDev)
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/llm-inferno/model-trainer/pkg/config"
 	"github.com/llm-inferno/model-trainer/pkg/core"
 	"github.com/llm-inferno/model-trainer/pkg/utils"
 )
@@ -12,28 +11,95 @@ import (
 // data in a GuideLLM CSV results file
 type GuideLLMCSVData struct {
 	Benchmarks []BenchmarkCSV
+	readerOptions
 }
 
 // benchmark data in GuideLLM CSV results file
 
 type BenchmarkCSV struct {
-	ID           string  `json:"Id"`
-	Name         string  `json:"Name"`
-	RPS          float64 `json:"Successful Requests per second mean"`
-	Concurrency  float64 `json:"Successful Request concurrency mean"`
-	Latency      float64 `json:"Successful Request latency mean"`
-	InputTokens  float64 `json:"Successful Prompt token count mean"`
-	OutputTokens float64 `json:"Successful Output token count mean"`
-	TTFT         float64 `json:"Successful Time to first token ms median"`
-	TPOT         float64 `json:"Successful Time per output token ms mean"`
-	ITL          float64 `json:"Successful Inter token latency ms mean"`
+	ID                 string  `json:"Id"`
+	Name               string  `json:"Name"`
+	RPS                float64 `json:"Successful Requests per second mean"`
+	Concurrency        float64 `json:"Successful Request concurrency mean"`
+	Latency            float64 `json:"Successful Request latency mean"`
+	InputTokens        float64 `json:"Successful Prompt token count mean"`
+	OutputTokens       float64 `json:"Successful Output token count mean"`
+	InputTokensMedian  float64 `json:"Successful Prompt token count median"`
+	OutputTokensMedian float64 `json:"Successful Output token count median"`
+	InputTokensStdDev  float64 `json:"Successful Prompt token count std dev"`
+	OutputTokensStdDev float64 `json:"Successful Output token count std dev"`
+	TTFT               float64 `json:"Successful Time to first token ms median"`
+	TTFTMean           float64 `json:"Successful Time to first token ms mean"`
+	TPOT               float64 `json:"Successful Time per output token ms mean"`
+	TPOTMedian         float64 `json:"Successful Time per output token ms median"`
+	ITL                float64 `json:"Successful Inter token latency ms mean"`
+	ITLMedian          float64 `json:"Successful Inter token latency ms median"`
+	Worker             string  `json:"Worker"` // json string of the worker
+
+	// keys of percentile lists contain commas, hence cannot be given as struct tags
+	TTFTPercentiles PercentileList `json:"-"`
+	ITLPercentiles  PercentileList `json:"-"`
+	TPOTPercentiles PercentileList `json:"-"`
+}
+
+// keys of percentile lists in GuideLLM CSV results
+const (
+	csvTTFTPercentilesKey = "Successful Time to first token ms [min, 0.1, 1, 5, 10, 25, 75, 90, 95, 99, max]"
+	csvITLPercentilesKey  = "Successful Inter token latency ms [min, 0.1, 1, 5, 10, 25, 75, 90, 95, 99, max]"
+	csvTPOTPercentilesKey = "Successful Time per output token ms [min, 0.1, 1, 5, 10, 25, 75, 90, 95, 99, max]"
+)
+
+func (b *BenchmarkCSV) UnmarshalJSON(data []byte) error {
+	type plainBenchmarkCSV BenchmarkCSV
+	if err := json.Unmarshal(data, (*plainBenchmarkCSV)(b)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if v, ok := raw[csvTTFTPercentilesKey]; ok {
+		if err := b.TTFTPercentiles.UnmarshalJSON(v); err != nil {
+			return err
+		}
+	}
+	if v, ok := raw[csvITLPercentilesKey]; ok {
+		if err := b.ITLPercentiles.UnmarshalJSON(v); err != nil {
+			return err
+		}
+	}
+	if v, ok := raw[csvTPOTPercentilesKey]; ok {
+		if err := b.TPOTPercentiles.UnmarshalJSON(v); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func NewGuideLLMCSVData() *GuideLLMCSVData {
 	return &GuideLLMCSVData{}
 }
 
+// columns required in GuideLLM CSV results
+var csvRequiredColumns = []string{
+	"Name",
+	"Successful Requests per second mean",
+	"Successful Prompt token count mean",
+	"Successful Output token count mean",
+	"Successful Time to first token ms median",
+	"Successful Inter token latency ms mean",
+}
+
+// read GuideLLM CSV results, given either as CSV or as a json array of rows keyed by column name
 func (g *GuideLLMCSVData) ReadFrom(dataBytes []byte) error {
+	if !isJSONData(dataBytes) {
+		benchmarks, err := fromCSVToSpec[BenchmarkCSV](dataBytes, csvRequiredColumns, 1)
+		if err != nil {
+			return err
+		}
+		g.Benchmarks = benchmarks
+		return nil
+	}
 	benchmarks, err := utils.FromDataToSpec(dataBytes, []BenchmarkCSV{})
 	if err != nil {
 		return err
@@ -42,43 +108,57 @@ func (g *GuideLLMCSVData) ReadFrom(dataBytes []byte) error {
 	return nil
 }
 
+// create a data set object from benchmark data, selected by the reader options
 func (g *GuideLLMCSVData) CreateDataSet() *core.DataSet {
-	dataSet := core.NewDataSet("GuideLLM CSV benchmark data")
+	points := []*core.DataPoint{}
 	for _, benchmark := range g.Benchmarks {
-		if benchmark.Name == "throughput" {
-			// skip throughput benchmark data point
-			continue
-		}
-		if dataSet.Size() >= DefaultLimitNumDataPoints {
-			// limit number of data points for model training
-			break
-		}
+		ttft, itl, inputTokens, outputTokens := g.values(&benchmarkStats{
+			TTFT:         metricStats{Mean: benchmark.TTFTMean, Median: benchmark.TTFT, Percentiles: benchmark.TTFTPercentiles.Percentiles(benchmark.TTFT)},
+			ITL:          metricStats{Mean: benchmark.ITL, Median: benchmark.ITLMedian, Percentiles: benchmark.ITLPercentiles.Percentiles(benchmark.ITLMedian)},
+			TPOT:         metricStats{Mean: benchmark.TPOT, Median: benchmark.TPOTMedian, Percentiles: benchmark.TPOTPercentiles.Percentiles(benchmark.TPOTMedian)},
+			InputTokens:  metricStats{Mean: benchmark.InputTokens, Median: benchmark.InputTokensMedian},
+			OutputTokens: metricStats{Mean: benchmark.OutputTokens, Median: benchmark.OutputTokensMedian},
+		})
+		maxBatchSize, maxNumTokens := serverFromJSON(benchmark.Worker).limits()
 		dataPoint := &core.DataPoint{
-			RequestRate:  benchmark.RPS,
-			InputTokens:  benchmark.InputTokens,
-			OutputTokens: benchmark.OutputTokens,
-			AvgTTFTTime:  benchmark.TTFT, // using median instead of mean since TTFT has a long tail
-			AvgITLTime:   benchmark.ITL,
-			// TODO: how to get the max batch size and max num tokens from the data?
-			MaxBatchSize: config.DefaultMaxBatchSize,
-			MaxNumTokens: config.DefaultMaxNumTokens,
+			RequestRate:        benchmark.RPS,
+			InputTokens:        inputTokens,
+			OutputTokens:       outputTokens,
+			InputTokensStdDev:  benchmark.InputTokensStdDev,
+			OutputTokensStdDev: benchmark.OutputTokensStdDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    benchmark.TTFTPercentiles.Percentiles(benchmark.TTFT),
+			ITLPercentiles:     benchmark.ITLPercentiles.Percentiles(benchmark.ITLMedian),
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: benchmark.ID,
+				Strategy:    benchmark.Name,
+				Model:       backendModel(benchmark.Worker, "backend_model"),
+			},
 		}
-		dataSet.AppendDataPoint(dataPoint)
+		points = append(points, dataPoint)
 	}
-	return dataSet
+	return g.createDataSet("GuideLLM CSV benchmark data", points)
 }
 
 func (g *GuideLLMCSVData) Print() {
 	for _, benchmark := range g.Benchmarks {
 		fmt.Printf("Benchmark ID: %s\n", benchmark.ID)
+		fmt.Printf("  Name: %s\n", benchmark.Name)
 		fmt.Printf("  RPS: Mean=%.2f\n", benchmark.RPS)
 		fmt.Printf("  Concurrency: Mean=%.2f\n", benchmark.Concurrency)
 		fmt.Printf("  Latency: Mean=%.2f\n", benchmark.Latency)
 		fmt.Printf("  Input Tokens: Mean=%.2f\n", benchmark.InputTokens)
 		fmt.Printf("  Output Tokens: Mean=%.2f\n", benchmark.OutputTokens)
-		fmt.Printf("  TTFT: Median=%.2f\n", benchmark.TTFT)
-		fmt.Printf("  TPOT: Mean=%.2f\n", benchmark.TPOT)
-		fmt.Printf("  ITL: Mean=%.2f\n", benchmark.ITL)
+		fmt.Printf("  Input Tokens: StdDev=%.2f\n", benchmark.InputTokensStdDev)
+		fmt.Printf("  Output Tokens: StdDev=%.2f\n", benchmark.OutputTokensStdDev)
+		fmt.Printf("  TTFT: Mean=%.2f, Median=%.2f\n", benchmark.TTFTMean, benchmark.TTFT)
+		fmt.Printf("  TPOT: Mean=%.2f, Median=%.2f\n", benchmark.TPOT, benchmark.TPOTMedian)
+		fmt.Printf("  ITL: Mean=%.2f, Median=%.2f\n", benchmark.ITL, benchmark.ITLMedian)
+		fmt.Printf("  TTFT: Percentiles=%v\n", benchmark.TTFTPercentiles)
+		fmt.Printf("  ITL: Percentiles=%v\n", benchmark.ITLPercentiles)
 	}
 }
 
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/llm-inferno/model-trainer/pkg/config"
 	"github.com/llm-inferno/model-trainer/pkg/core"
 	"github.com/llm-inferno/model-trainer/pkg/utils"
 )
@@ -12,27 +11,60 @@ import (
 // data in a GuideLLM CSV results file (v2 format with pipe-separated hierarchical fields)
 type GuideLLMCSV2Data struct {
 	Benchmarks []BenchmarkCSV2
+	readerOptions
 }
 
 // benchmark data in GuideLLM CSV results file (v2 format)
 type BenchmarkCSV2 struct {
-	ID           string  `json:"Benchmark | ID"`
-	Name         string  `json:"Benchmark | Strategy"`
-	RPS          float64 `json:"Server Throughput | Successful Requests/Sec | Mean"`
-	Concurrency  float64 `json:"Server Throughput | Successful Concurrency | Mean"`
-	Latency      float64 `json:"Request Latency | Successful Sec | Mean"`
-	InputTokens  float64 `json:"Token Metrics | Successful Input Tokens | Mean"`
-	OutputTokens float64 `json:"Token Metrics | Successful Output Tokens | Mean"`
-	TTFT         float64 `json:"Time to First Token | Successful ms | Median"`
-	TPOT         float64 `json:"Time per Output Token | Successful ms | Mean"`
-	ITL          float64 `json:"Inter Token Latency | Successful ms | Mean"`
+	ID                 string  `json:"Benchmark | ID"`
+	Name               string  `json:"Benchmark | Strategy"`
+	RPS                float64 `json:"Server Throughput | Successful Requests/Sec | Mean"`
+	Concurrency        float64 `json:"Server Throughput | Successful Concurrency | Mean"`
+	Latency            float64 `json:"Request Latency | Successful Sec | Mean"`
+	InputTokens        float64 `json:"Token Metrics | Successful Input Tokens | Mean"`
+	OutputTokens       float64 `json:"Token Metrics | Successful Output Tokens | Mean"`
+	InputTokensMedian  float64 `json:"Token Metrics | Successful Input Tokens | Median"`
+	OutputTokensMedian float64 `json:"Token Metrics | Successful Output Tokens | Median"`
+	InputTokensStdDev  float64 `json:"Token Metrics | Successful Input Tokens | Std Dev"`
+	OutputTokensStdDev float64 `json:"Token Metrics | Successful Output Tokens | Std Dev"`
+	TTFT               float64 `json:"Time to First Token | Successful ms | Median"`
+	TTFTMean           float64 `json:"Time to First Token | Successful ms | Mean"`
+	TPOT               float64 `json:"Time per Output Token | Successful ms | Mean"`
+	TPOTMedian         float64 `json:"Time per Output Token | Successful ms | Median"`
+	ITL                float64 `json:"Inter Token Latency | Successful ms | Mean"`
+	ITLMedian          float64 `json:"Inter Token Latency | Successful ms | Median"`
+	Backend            string  `json:"Run Info | Backend"` // json string of the backend
+
+	TTFTPercentiles PercentileList `json:"Time to First Token | Successful ms | Percentiles"`
+	ITLPercentiles  PercentileList `json:"Inter Token Latency | Successful ms | Percentiles"`
+	TPOTPercentiles PercentileList `json:"Time per Output Token | Successful ms | Percentiles"`
 }
 
 func NewGuideLLMCSV2Data() *GuideLLMCSV2Data {
 	return &GuideLLMCSV2Data{}
 }
 
+// columns required in GuideLLM CSV results (v2 format)
+var csv2RequiredColumns = []string{
+	"Benchmark | Strategy",
+	"Server Throughput | Successful Requests/Sec | Mean",
+	"Token Metrics | Successful Input Tokens | Mean",
+	"Token Metrics | Successful Output Tokens | Mean",
+	"Time to First Token | Successful ms | Median",
+	"Inter Token Latency | Successful ms | Mean",
+}
+
+// read GuideLLM CSV results (v2 format), given either as CSV with one header row per level
+// of the hierarchical column names, or as a json array of rows keyed by pipe-separated column name
 func (g *GuideLLMCSV2Data) ReadFrom(dataBytes []byte) error {
+	if !isJSONData(dataBytes) {
+		benchmarks, err := fromCSVToSpec[BenchmarkCSV2](dataBytes, csv2RequiredColumns, csvMaxHeaderRows)
+		if err != nil {
+			return err
+		}
+		g.Benchmarks = benchmarks
+		return nil
+	}
 	benchmarks, err := utils.FromDataToSpec(dataBytes, []BenchmarkCSV2{})
 	if err != nil {
 		return err
@@ -41,30 +73,39 @@ func (g *GuideLLMCSV2Data) ReadFrom(dataBytes []byte) error {
 	return nil
 }
 
+// create a data set object from benchmark data, selected by the reader options
 func (g *GuideLLMCSV2Data) CreateDataSet() *core.DataSet {
-	dataSet := core.NewDataSet("GuideLLM CSV v2 benchmark data")
+	points := []*core.DataPoint{}
 	for _, benchmark := range g.Benchmarks {
-		if benchmark.Name == "throughput" {
-			// skip throughput benchmark data point
-			continue
-		}
-		if dataSet.Size() >= DefaultLimitNumDataPoints {
-			// limit number of data points for model training
-			break
-		}
+		ttft, itl, inputTokens, outputTokens := g.values(&benchmarkStats{
+			TTFT:         metricStats{Mean: benchmark.TTFTMean, Median: benchmark.TTFT, Percentiles: benchmark.TTFTPercentiles.Percentiles(benchmark.TTFT)},
+			ITL:          metricStats{Mean: benchmark.ITL, Median: benchmark.ITLMedian, Percentiles: benchmark.ITLPercentiles.Percentiles(benchmark.ITLMedian)},
+			TPOT:         metricStats{Mean: benchmark.TPOT, Median: benchmark.TPOTMedian, Percentiles: benchmark.TPOTPercentiles.Percentiles(benchmark.TPOTMedian)},
+			InputTokens:  metricStats{Mean: benchmark.InputTokens, Median: benchmark.InputTokensMedian},
+			OutputTokens: metricStats{Mean: benchmark.OutputTokens, Median: benchmark.OutputTokensMedian},
+		})
+		maxBatchSize, maxNumTokens := serverFromJSON(benchmark.Backend).limits()
 		dataPoint := &core.DataPoint{
-			RequestRate:  benchmark.RPS,
-			InputTokens:  benchmark.InputTokens,
-			OutputTokens: benchmark.OutputTokens,
-			AvgTTFTTime:  benchmark.TTFT, // using median instead of mean since TTFT has a long tail
-			AvgITLTime:   benchmark.ITL,
-			// TODO: how to get the max batch size and max num tokens from the data?
-			MaxBatchSize: config.DefaultMaxBatchSize,
-			MaxNumTokens: config.DefaultMaxNumTokens,
+			RequestRate:        benchmark.RPS,
+			InputTokens:        inputTokens,
+			OutputTokens:       outputTokens,
+			InputTokensStdDev:  benchmark.InputTokensStdDev,
+			OutputTokensStdDev: benchmark.OutputTokensStdDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    benchmark.TTFTPercentiles.Percentiles(benchmark.TTFT),
+			ITLPercentiles:     benchmark.ITLPercentiles.Percentiles(benchmark.ITLMedian),
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: benchmark.ID,
+				Strategy:    benchmark.Name,
+				Model:       backendModel(benchmark.Backend, "model"),
+			},
 		}
-		dataSet.AppendDataPoint(dataPoint)
+		points = append(points, dataPoint)
 	}
-	return dataSet
+	return g.createDataSet("GuideLLM CSV v2 benchmark data", points)
 }
 
 func (g *GuideLLMCSV2Data) Print() {
@@ -76,9 +117,13 @@ func (g *GuideLLMCSV2Data) Print() {
 		fmt.Printf("  Latency: Mean=%.2f\n", benchmark.Latency)
 		fmt.Printf("  Input Tokens: Mean=%.2f\n", benchmark.InputTokens)
 		fmt.Printf("  Output Tokens: Mean=%.2f\n", benchmark.OutputTokens)
-		fmt.Printf("  TTFT: Median=%.2f\n", benchmark.TTFT)
-		fmt.Printf("  TPOT: Mean=%.2f\n", benchmark.TPOT)
-		fmt.Printf("  ITL: Mean=%.2f\n", benchmark.ITL)
+		fmt.Printf("  Input Tokens: StdDev=%.2f\n", benchmark.InputTokensStdDev)
+		fmt.Printf("  Output Tokens: StdDev=%.2f\n", benchmark.OutputTokensStdDev)
+		fmt.Printf("  TTFT: Mean=%.2f, Median=%.2f\n", benchmark.TTFTMean, benchmark.TTFT)
+		fmt.Printf("  TPOT: Mean=%.2f, Median=%.2f\n", benchmark.TPOT, benchmark.TPOTMedian)
+		fmt.Printf("  ITL: Mean=%.2f, Median=%.2f\n", benchmark.ITL, benchmark.ITLMedian)
+		fmt.Printf("  TTFT: Percentiles=%v\n", benchmark.TTFTPercentiles)
+		fmt.Printf("  ITL: Percentiles=%v\n", benchmark.ITLPercentiles)
 	}
 }
 
@@ -0,0 +1,163 @@
+package reader
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func readTestFile(t *testing.T, path string) []byte {
+	t.Helper()
+	dataBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return dataBytes
+}
+
+func TestGuideLLMCSVData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, g *GuideLLMCSVData)
+	}{
+		{
+			name: "csv file",
+			data: readTestFile(t, "testdata/guidellm.csv"),
+			validateFn: func(t *testing.T, g *GuideLLMCSVData) {
+				if len(g.Benchmarks) != 4 {
+					t.Fatalf("got %d benchmarks, want 4", len(g.Benchmarks))
+				}
+				b := g.Benchmarks[0]
+				if b.Name != "synchronous" || b.RPS <= 0 || b.InputTokens <= 0 || b.TTFT <= 0 || b.ITL <= 0 {
+					t.Errorf("unexpected benchmark %+v", b)
+				}
+				if len(b.TTFTPercentiles) != percentileListLen || len(b.ITLPercentiles) != percentileListLen {
+					t.Errorf("percentile lists not read: %v, %v", b.TTFTPercentiles, b.ITLPercentiles)
+				}
+				ds := g.CreateDataSet()
+				if ds.Size() != 3 || ds.Data[0].TTFTPercentiles == nil || ds.Data[0].Labels.Model == "" {
+					t.Errorf("unexpected data set %s", ds.DataSetPrettyPrint())
+				}
+			},
+		},
+		{
+			name: "json array keyed by column",
+			data: readTestFile(t, "../../samples/guidellm-csv.json"),
+			validateFn: func(t *testing.T, g *GuideLLMCSVData) {
+				if len(g.Benchmarks) != 10 || g.Benchmarks[0].Name != "synchronous" {
+					t.Errorf("got %d benchmarks", len(g.Benchmarks))
+				}
+			},
+		},
+		{
+			name: "same values from csv and json",
+			data: readTestFile(t, "testdata/guidellm.csv"),
+			validateFn: func(t *testing.T, g *GuideLLMCSVData) {
+				j := NewGuideLLMCSVData()
+				if err := j.ReadFrom(readTestFile(t, "../../samples/guidellm-csv.json")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for i, b := range g.Benchmarks {
+					if b.ID != j.Benchmarks[i].ID || b.RPS != j.Benchmarks[i].RPS || b.TTFT != j.Benchmarks[i].TTFT {
+						t.Errorf("benchmark %d differs between csv and json", i)
+					}
+				}
+			},
+		},
+		{
+			name:        "missing columns",
+			data:        []byte("Name,Successful Requests per second mean\nsynchronous,1.5\n"),
+			expectError: "Successful Prompt token count mean",
+		},
+		{
+			name: "invalid value",
+			data: []byte("Name,Successful Requests per second mean,Successful Prompt token count mean," +
+				"Successful Output token count mean,Successful Time to first token ms median,Successful Inter token latency ms mean\n" +
+				"synchronous,fast,100,100,20,5\n"),
+			expectError: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGuideLLMCSVData()
+			err := g.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, g)
+		})
+	}
+}
+
+func TestGuideLLMCSV2Data_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, g *GuideLLMCSV2Data)
+	}{
+		{
+			name: "csv file with hierarchical header rows",
+			data: readTestFile(t, "testdata/guidellm-v2.csv"),
+			validateFn: func(t *testing.T, g *GuideLLMCSV2Data) {
+				if len(g.Benchmarks) != 4 {
+					t.Fatalf("got %d benchmarks, want 4", len(g.Benchmarks))
+				}
+				b := g.Benchmarks[0]
+				if b.ID == "" || b.Name != "synchronous" || b.RPS <= 0 || b.ITLMedian <= 0 || len(b.ITLPercentiles) != percentileListLen {
+					t.Errorf("unexpected benchmark %+v", b)
+				}
+				ds := g.CreateDataSet()
+				if ds.Size() != 3 || ds.Data[0].Labels.Model != "meta-llama/Llama-3.1-8B-Instruct" {
+					t.Errorf("unexpected data set %s", ds.DataSetPrettyPrint())
+				}
+			},
+		},
+		{
+			name: "json array keyed by pipe-separated column",
+			data: []byte(`[{"Benchmark | Strategy": "synchronous", "Server Throughput | Successful Requests/Sec | Mean": 1.5,
+				"Time to First Token | Successful ms | Percentiles": "[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11]"}]`),
+			validateFn: func(t *testing.T, g *GuideLLMCSV2Data) {
+				if len(g.Benchmarks) != 1 || g.Benchmarks[0].RPS != 1.5 || len(g.Benchmarks[0].TTFTPercentiles) != percentileListLen {
+					t.Errorf("unexpected benchmarks %+v", g.Benchmarks)
+				}
+			},
+		},
+		{
+			name:        "v1 csv is missing v2 columns",
+			data:        readTestFile(t, "testdata/guidellm.csv"),
+			expectError: "Benchmark | Strategy",
+		},
+		{
+			name:        "empty data",
+			data:        []byte("\n"),
+			expectError: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGuideLLMCSV2Data()
+			err := g.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, g)
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package reader
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
 
@@ -15,6 +19,11 @@ type GuideLLMHTMLData struct {
 	Benchmarks       []BenchmarkHTML
 	PromptTokenStats *TokenStats
 	OutputTokenStats *TokenStats
+	Model            string
+	Server           *ServerConfig // server configuration in the run information or workload details
+	readerOptions
+
+	warnings []string // data missing in the report, replaced by guesses
 }
 
 // benchmark data extracted from GuideLLM HTML results file
@@ -24,9 +33,16 @@ type BenchmarkHTML struct {
 	Latency      float64
 	InputTokens  float64
 	OutputTokens float64
+	InputStdDev  float64
+	OutputStdDev float64
 	TTFT         float64
-	TPOT         float64
 	ITL          float64
+	TPOT         float64
+
+	TTFTPercentiles *config.Percentiles
+	ITLPercentiles  *config.Percentiles
+
+	stats benchmarkStats // statistics of all metrics, from which data points take the chosen ones
 }
 
 // Statistical data structure from HTML
@@ -36,6 +52,32 @@ type StatisticsData struct {
 	StdDev float64 `json:"stdDev"`
 	Min    float64 `json:"min"`
 	Max    float64 `json:"max"`
+
+	Percentiles    map[string]float64 `json:"percentiles"`
+	PercentileRows []PercentileRow    `json:"percentileRows"`
+}
+
+// a row of the percentiles table in HTML
+type PercentileRow struct {
+	Percentile string  `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// get the percentiles of the statistics, the table rows taking precedence over the map
+func (s *StatisticsData) GetPercentiles() *config.Percentiles {
+	values := make(map[string]float64, len(s.Percentiles)+len(s.PercentileRows))
+	for k, v := range s.Percentiles {
+		values[k] = v
+	}
+	for _, row := range s.PercentileRows {
+		values[row.Percentile] = row.Value
+	}
+	return percentilesFromMap(values)
+}
+
+// get the statistics of a metric
+func (s *StatisticsData) stats() metricStats {
+	return metricStats{Mean: s.Mean, Median: s.Median, Percentiles: s.GetPercentiles()}
 }
 
 // Token statistics from workloadDetails
@@ -52,12 +94,118 @@ type RawBenchmark struct {
 	Throughput        StatisticsData `json:"throughput"`
 	RequestsPerSecond float64        `json:"requestsPerSecond"`
 	TimePerRequest    StatisticsData `json:"timePerRequest"`
+
+	// reported by recent GuideLLM versions only
+	Strategy     *StrategyHTML   `json:"strategy"`
+	PromptTokens *StatisticsData `json:"promptTokens"`
+	OutputTokens *StatisticsData `json:"outputTokens"`
+	TPOT         *StatisticsData `json:"tpot"`
+}
+
+// strategy of a benchmark in HTML, given either as a name (e.g. constant@2.00) or as an object
+type StrategyHTML struct {
+	Type string  `json:"type"`
+	Rate float64 `json:"rate"`
+}
+
+func (s *StrategyHTML) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		kind, rate, _ := strings.Cut(strings.TrimSpace(name), "@")
+		s.Type = strings.ToLower(kind)
+		if rate != "" {
+			if _, err := fmt.Sscanf(rate, "%g", &s.Rate); err != nil {
+				return fmt.Errorf("invalid strategy %q", name)
+			}
+		}
+		return nil
+	}
+	var object struct {
+		Type  string  `json:"type"`
+		Type_ string  `json:"type_"`
+		Rate  float64 `json:"rate"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid strategy: %s", string(data))
+	}
+	s.Type, s.Rate = strings.ToLower(object.Type), object.Rate
+	if s.Type == "" {
+		s.Type = strings.ToLower(object.Type_)
+	}
+	return nil
+}
+
+// get the name of the strategy, e.g. constant@2.00, as in other GuideLLM outputs
+func (s *StrategyHTML) Name() string {
+	if s.Rate > 0 && s.Type != StrategySynchronous && s.Type != StrategyThroughput {
+		return fmt.Sprintf("%s@%.2f", s.Type, s.Rate)
+	}
+	return s.Type
 }
 
 func NewGuideLLMHTMLData() *GuideLLMHTMLData {
 	return &GuideLLMHTMLData{}
 }
 
+// read a report from a stream, holding only the text of its script elements, where the data is
+func (g *GuideLLMHTMLData) ReadFromStream(in io.Reader) error {
+	scripts, err := readScripts(in)
+	if err != nil {
+		return err
+	}
+	return g.ReadFrom(scripts)
+}
+
+// read the text of the script elements of an html stream, dropping the rest
+func readScripts(in io.Reader) ([]byte, error) {
+	buffered := bufio.NewReader(in)
+	var scripts bytes.Buffer
+	for {
+		err := readPast(buffered, nil, "<script")
+		if err == nil {
+			_, err = buffered.ReadBytes('>')
+		}
+		if err == nil {
+			err = readPast(buffered, &scripts, "</script")
+			scripts.WriteByte('\n')
+		}
+		if errors.Is(err, io.EOF) {
+			return scripts.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// read a stream past a lowercase marker, matched ignoring case, copying what comes before it to out if not nil
+func readPast(in *bufio.Reader, out *bytes.Buffer, marker string) error {
+	for matched := 0; matched < len(marker); {
+		c, err := in.ReadByte()
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			out.WriteByte(c)
+		}
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		switch {
+		case c == marker[matched]:
+			matched++
+		case c == marker[0]:
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+	if out != nil {
+		out.Truncate(out.Len() - len(marker))
+	}
+	return nil
+}
+
 func (g *GuideLLMHTMLData) ReadFrom(dataBytes []byte) error {
 	htmlContent := string(dataBytes)
 
@@ -73,38 +221,114 @@ func (g *GuideLLMHTMLData) ReadFrom(dataBytes []byte) error {
 	}
 
 	// Extract token statistics from workloadDetails
+	g.PromptTokenStats, g.OutputTokenStats = nil, nil
 	g.extractTokenStats(htmlContent)
 
+	// Extract model name from runInfo
+	g.extractModel(htmlContent)
+
+	// Extract server configuration from runInfo and workloadDetails
+	g.extractServer(htmlContent)
+
 	// Convert raw benchmarks to our format
+	g.Benchmarks, g.warnings = nil, nil
+	missingStrategy, missingRate, missingTokens, defaultTokens := 0, 0, 0, 0
 	for i, raw := range rawBenchmarks {
-		strategy := g.inferStrategy(i)
+		strategy := ""
+		if raw.Strategy != nil && raw.Strategy.Type != "" {
+			strategy = raw.Strategy.Name()
+		} else {
+			strategy = g.inferStrategy(i)
+			missingStrategy++
+		}
+		if raw.RequestsPerSecond <= 0 {
+			missingRate++
+		}
 
-		// Use average token counts from overall statistics
-		inputTokens := 64.0  // default
-		outputTokens := 64.0 // default
-		if g.PromptTokenStats != nil {
-			inputTokens = g.PromptTokenStats.Mean
+		inputTokens, inputStdDev, inputOK := tokenStats(raw.PromptTokens, g.PromptTokenStats)
+		outputTokens, outputStdDev, outputOK := tokenStats(raw.OutputTokens, g.OutputTokenStats)
+		if raw.PromptTokens == nil || raw.OutputTokens == nil {
+			missingTokens++
 		}
-		if g.OutputTokenStats != nil {
-			outputTokens = g.OutputTokenStats.Mean
+		if !inputOK || !outputOK {
+			defaultTokens++
 		}
 
+		tpot := StatisticsData{}
+		if raw.TPOT != nil {
+			tpot = *raw.TPOT
+		}
 		benchmark := BenchmarkHTML{
 			Strategy:     strategy,
 			RPS:          raw.RequestsPerSecond,
 			Latency:      raw.TimePerRequest.Mean,
-			InputTokens:  inputTokens,
-			OutputTokens: outputTokens,
+			InputTokens:  inputTokens.Mean,
+			OutputTokens: outputTokens.Mean,
+			InputStdDev:  inputStdDev,
+			OutputStdDev: outputStdDev,
 			TTFT:         raw.TTFT.Median,
-			TPOT:         raw.ITL.Mean,
 			ITL:          raw.ITL.Mean,
+			TPOT:         tpot.Mean,
+
+			TTFTPercentiles: raw.TTFT.GetPercentiles(),
+			ITLPercentiles:  raw.ITL.GetPercentiles(),
+
+			stats: benchmarkStats{
+				TTFT:         raw.TTFT.stats(),
+				ITL:          raw.ITL.stats(),
+				TPOT:         tpot.stats(),
+				InputTokens:  inputTokens,
+				OutputTokens: outputTokens,
+			},
 		}
 		g.Benchmarks = append(g.Benchmarks, benchmark)
 	}
 
+	if missingStrategy > 0 {
+		g.warn("%d of %d benchmarks without strategy in report, inferred from position (synchronous, throughput, then constant)",
+			missingStrategy, len(rawBenchmarks))
+	}
+	if missingRate > 0 {
+		g.warn("%d of %d benchmarks without request rate in report", missingRate, len(rawBenchmarks))
+	}
+	if missingTokens > defaultTokens {
+		g.warn("%d of %d benchmarks without token counts in report, using the overall token distributions",
+			missingTokens-defaultTokens, len(rawBenchmarks))
+	}
+	if defaultTokens > 0 {
+		g.warn("%d of %d benchmarks without token counts in report, assuming %d tokens",
+			defaultTokens, len(rawBenchmarks), defaultHTMLTokens)
+	}
 	return nil
 }
 
+// token counts assumed when missing in a report
+const defaultHTMLTokens = 64
+
+// get the statistics of token counts of a benchmark, falling back to the overall statistics;
+// false if neither is available (default token counts)
+func tokenStats(benchmark *StatisticsData, overall *TokenStats) (metricStats, float64, bool) {
+	switch {
+	case benchmark != nil:
+		return metricStats{Mean: benchmark.Mean, Median: benchmark.Median}, benchmark.StdDev, true
+	case overall != nil:
+		return metricStats{Mean: overall.Mean, Median: overall.Median}, overall.StdDev, true
+	default:
+		return metricStats{Mean: defaultHTMLTokens, Median: defaultHTMLTokens}, 0, false
+	}
+}
+
+// record a warning about data missing in the report
+func (g *GuideLLMHTMLData) warn(format string, args ...any) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// get the warnings about data missing in the report, replaced by guesses, or read as zero in the last
+// data set created
+func (g *GuideLLMHTMLData) Warnings() []string {
+	return append(append([]string{}, g.warnings...), g.readerOptions.Warnings()...)
+}
+
 func (g *GuideLLMHTMLData) extractTokenStats(htmlContent string) {
 	// Try to extract token distribution statistics from workloadDetails
 	workloadJSON, err := extractJSONObject(htmlContent, "window.workloadDetails")
@@ -144,6 +368,44 @@ func (g *GuideLLMHTMLData) extractTokenStats(htmlContent string) {
 	}
 }
 
+func (g *GuideLLMHTMLData) extractModel(htmlContent string) {
+	runInfoJSON, err := extractJSONObject(htmlContent, "window.runInfo")
+	if err != nil {
+		return
+	}
+	var runInfo struct {
+		Model struct {
+			Name string `json:"name"`
+		} `json:"model"`
+	}
+	if err := json.Unmarshal([]byte(runInfoJSON), &runInfo); err != nil {
+		return
+	}
+	if name := runInfo.Model.Name; name != "N/A" {
+		g.Model = name
+	}
+}
+
+func (g *GuideLLMHTMLData) extractServer(htmlContent string) {
+	g.Server = nil
+	for _, varName := range []string{"window.runInfo", "window.workloadDetails"} {
+		objectJSON, err := extractJSONObject(htmlContent, varName)
+		if err != nil {
+			continue
+		}
+		var object map[string]any
+		if err := json.Unmarshal([]byte(objectJSON), &object); err != nil {
+			continue
+		}
+		// skip prompt and generation samples, which may contain arbitrary text
+		delete(object, "prompts")
+		delete(object, "generations")
+		if server := serverFromMetadata(object); server != nil {
+			g.Server = g.Server.Overlay(server)
+		}
+	}
+}
+
 func getFloat(m map[string]interface{}, key string) float64 {
 	if val, ok := m[key].(float64); ok {
 		return val
@@ -265,6 +527,7 @@ func extractJSONObject(content, varName string) (string, error) {
 	return "", fmt.Errorf("matching closing brace not found")
 }
 
+// infer the strategy of a benchmark, missing in the report, from its position in a sweep
 func (g *GuideLLMHTMLData) inferStrategy(index int) string {
 	// First benchmark is typically synchronous
 	if index == 0 {
@@ -280,27 +543,33 @@ func (g *GuideLLMHTMLData) inferStrategy(index int) string {
 	return "constant"
 }
 
+// create a data set object from benchmark data, selected by the reader options
 func (g *GuideLLMHTMLData) CreateDataSet() *core.DataSet {
-	dataSet := core.NewDataSet("GuideLLM HTML benchmark data")
+	points := []*core.DataPoint{}
+	maxBatchSize, maxNumTokens := g.Server.limits()
 	for _, benchmark := range g.Benchmarks {
-		if strings.EqualFold(benchmark.Strategy, "throughput") {
-			// skip throughput benchmark data point
-			continue
-		}
-		if dataSet.Size() >= DefaultLimitNumDataPoints {
-			// limit number of data points for model training
-			break
-		}
+		ttft, itl, inputTokens, outputTokens := g.values(&benchmark.stats)
 		dataPoint := &core.DataPoint{
-			RequestRate:  benchmark.RPS,
-			InputTokens:  benchmark.InputTokens,
-			OutputTokens: benchmark.OutputTokens,
-			AvgTTFTTime:  benchmark.TTFT,
-			AvgITLTime:   benchmark.ITL,
-			MaxBatchSize: config.DefaultMaxBatchSize,
-			MaxNumTokens: config.DefaultMaxNumTokens,
+			RequestRate:        benchmark.RPS,
+			InputTokens:        inputTokens,
+			OutputTokens:       outputTokens,
+			InputTokensStdDev:  benchmark.InputStdDev,
+			OutputTokensStdDev: benchmark.OutputStdDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    benchmark.TTFTPercentiles,
+			ITLPercentiles:     benchmark.ITLPercentiles,
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				Strategy: benchmark.Strategy,
+			},
 		}
-		dataSet.AppendDataPoint(dataPoint)
+		points = append(points, dataPoint)
+	}
+	dataSet := g.createDataSet("GuideLLM HTML benchmark data", points)
+	if g.Model != "" {
+		dataSet.Metadata.Model = g.Model
 	}
 	return dataSet
 }
@@ -315,6 +584,9 @@ func (g *GuideLLMHTMLData) Print() {
 		fmt.Printf("  Output Tokens: Mean=%.2f, Median=%.2f, StdDev=%.2f\n",
 			g.OutputTokenStats.Mean, g.OutputTokenStats.Median, g.OutputTokenStats.StdDev)
 	}
+	for _, w := range g.Warnings() {
+		fmt.Printf("Warning: %s\n", w)
+	}
 	fmt.Println("\nBenchmarks:")
 	for i, benchmark := range g.Benchmarks {
 		fmt.Printf("Benchmark %d:\n", i)
@@ -324,8 +596,13 @@ func (g *GuideLLMHTMLData) Print() {
 		fmt.Printf("  Input Tokens: %.2f\n", benchmark.InputTokens)
 		fmt.Printf("  Output Tokens: %.2f\n", benchmark.OutputTokens)
 		fmt.Printf("  TTFT: %.2f ms\n", benchmark.TTFT)
-		fmt.Printf("  TPOT: %.2f ms\n", benchmark.TPOT)
 		fmt.Printf("  ITL: %.2f ms\n", benchmark.ITL)
+		if p := benchmark.TTFTPercentiles; p != nil {
+			fmt.Printf("  TTFT: P50=%.2f, P90=%.2f, P95=%.2f, P99=%.2f ms\n", p.P50, p.P90, p.P95, p.P99)
+		}
+		if p := benchmark.ITLPercentiles; p != nil {
+			fmt.Printf("  ITL: P50=%.2f, P90=%.2f, P95=%.2f, P99=%.2f ms\n", p.P50, p.P90, p.P95, p.P99)
+		}
 	}
 }
 
@@ -0,0 +1,147 @@
+package reader
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGuideLLMHTMLData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		expectError  string
+		wantWarnings []string
+		validateFn   func(t *testing.T, g *GuideLLMHTMLData)
+	}{
+		{
+			name: "per-benchmark strategy and tokens",
+			data: readTestFile(t, "testdata/guidellm-report.html"),
+			validateFn: func(t *testing.T, g *GuideLLMHTMLData) {
+				wantStrategies := []string{"throughput", "synchronous", "constant@1.50"}
+				if len(g.Benchmarks) != len(wantStrategies) {
+					t.Fatalf("got %d benchmarks, want %d", len(g.Benchmarks), len(wantStrategies))
+				}
+				for i, b := range g.Benchmarks {
+					if b.Strategy != wantStrategies[i] {
+						t.Errorf("benchmark %d: strategy %q, want %q", i, b.Strategy, wantStrategies[i])
+					}
+				}
+				b := g.Benchmarks[1]
+				if b.InputTokens != 980 || b.OutputTokens != 480 || b.InputStdDev != 190 || b.OutputStdDev != 110 {
+					t.Errorf("token counts of benchmark not read: %+v", b)
+				}
+				g.SetOptions(&Options{Exclude: []string{StrategyThroughput}})
+				ds := g.CreateDataSet()
+				if ds.Size() != 2 || ds.Data[0].RequestRate != 0.1 || ds.Metadata.Model != "Qwen/Qwen2.5-14B" {
+					t.Errorf("unexpected data set %+v", ds)
+				}
+			},
+		},
+		{
+			name: "missing strategy and tokens",
+			data: []byte(`<script>window.workloadDetails = {"prompts": {"tokenDistributions": {"statistics": {"mean": 100, "median": 90}}},
+				"generations": {"tokenDistributions": {"statistics": {"mean": 50, "median": 40}}}};
+				window.benchmarks = [{"requestsPerSecond": 0.5, "ttft": {"median": 50}, "itl": {"mean": 10}},
+				{"requestsPerSecond": 5, "ttft": {"median": 500}, "itl": {"mean": 20}, "strategy": "throughput",
+				"promptTokens": {"mean": 120}, "outputTokens": {"mean": 60}}];</script>`),
+			wantWarnings: []string{"1 of 2 benchmarks without strategy", "1 of 2 benchmarks without token counts in report, using the overall"},
+			validateFn: func(t *testing.T, g *GuideLLMHTMLData) {
+				if g.Benchmarks[0].Strategy != StrategySynchronous || g.Benchmarks[0].InputTokens != 100 {
+					t.Errorf("unexpected fallback values %+v", g.Benchmarks[0])
+				}
+				if g.Benchmarks[1].InputTokens != 120 || g.Benchmarks[1].OutputTokens != 60 {
+					t.Errorf("token counts of benchmark not read: %+v", g.Benchmarks[1])
+				}
+			},
+		},
+		{
+			name:         "no token counts at all",
+			data:         []byte(`<script>window.benchmarks = [{"strategy": "constant@2", "ttft": {"median": 50}, "itl": {"mean": 10}}];</script>`),
+			wantWarnings: []string{"1 of 1 benchmarks without request rate", "1 of 1 benchmarks without token counts in report, assuming 64 tokens"},
+			validateFn: func(t *testing.T, g *GuideLLMHTMLData) {
+				if g.Benchmarks[0].Strategy != "constant@2.00" || g.Benchmarks[0].InputTokens != defaultHTMLTokens {
+					t.Errorf("unexpected benchmark %+v", g.Benchmarks[0])
+				}
+			},
+		},
+		{
+			name: "inter-token latency as TPOT",
+			data: []byte(`<script>window.benchmarks = [
+				{"strategy": "constant@2", "requestsPerSecond": 2, "promptTokens": {"mean": 100}, "outputTokens": {"mean": 50},
+				"ttft": {"median": 50}, "itl": {"mean": 10}, "tpot": {"mean": 12, "median": 11}},
+				{"strategy": "constant@4", "requestsPerSecond": 4, "promptTokens": {"mean": 100}, "outputTokens": {"mean": 50},
+				"ttft": {"median": 60}, "itl": {"mean": 14}}];</script>`),
+			validateFn: func(t *testing.T, g *GuideLLMHTMLData) {
+				if g.Benchmarks[0].TPOT != 12 {
+					t.Errorf("TPOT not read: %+v", g.Benchmarks[0])
+				}
+				if g.CreateDataSet(); len(g.Warnings()) != 0 {
+					t.Errorf("unexpected warnings %q", g.Warnings())
+				}
+				g.SetOptions(&Options{ITLMetric: MetricTPOT, TPOTStat: StatMedian})
+				ds := g.CreateDataSet()
+				if ds.Data[0].AvgITLTime != 11 || ds.Data[1].AvgITLTime != 0 {
+					t.Errorf("unexpected inter-token latencies %v, %v", ds.Data[0].AvgITLTime, ds.Data[1].AvgITLTime)
+				}
+				if w := g.Warnings(); len(w) != 1 || w[0] != "1 of 2 benchmarks without tpot median in input, read as zero" {
+					t.Errorf("unexpected warnings %q", w)
+				}
+			},
+		},
+		{
+			name:        "invalid strategy",
+			data:        []byte(`<script>window.benchmarks = [{"strategy": 42}];</script>`),
+			expectError: "invalid strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGuideLLMHTMLData()
+			err := g.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(g.Warnings()) != len(tt.wantWarnings) {
+				t.Fatalf("got warnings %q, want %q", g.Warnings(), tt.wantWarnings)
+			}
+			for i, w := range tt.wantWarnings {
+				if !strings.Contains(g.Warnings()[i], w) {
+					t.Errorf("warning %q does not contain %q", g.Warnings()[i], w)
+				}
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, g)
+			}
+		})
+	}
+}
+
+func TestReadScripts(t *testing.T) {
+	html := `<!DOCTYPE html><html><head><style>p { color: red }</style>
+<SCRIPT type="text/javascript">window.runInfo = {"model": {"name": "m"}};</SCRIPT></head>
+<body><p>if a <b>scripted</b> report</p><script>window.benchmarks = [{"x": 1 < 2}];</script><script>unterminated`
+	scripts, err := readScripts(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "window.runInfo = {\"model\": {\"name\": \"m\"}};\nwindow.benchmarks = [{\"x\": 1 < 2}];\nunterminated\n"
+	if string(scripts) != want {
+		t.Errorf("got scripts %q, want %q", scripts, want)
+	}
+
+	g := NewGuideLLMHTMLData()
+	if err := g.ReadFromStream(bytes.NewReader(readTestFile(t, "testdata/guidellm-report.html"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Benchmarks) == 0 || g.Model != "Qwen/Qwen2.5-14B" {
+		t.Errorf("unexpected report read from a stream: %d benchmarks of model %q", len(g.Benchmarks), g.Model)
+	}
+}
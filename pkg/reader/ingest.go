@@ -0,0 +1,300 @@
+package reader
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// report of the ingestion of a file
+type FileReport struct {
+	Path     string   `json:"path"`              // path of the file, or of the file in an archive
+	Archive  string   `json:"archive,omitempty"` // path of the archive holding the file
+	Format   string   `json:"format,omitempty"`  // name of the detected format
+	Points   int      `json:"points"`            // number of data points read
+	Skipped  int      `json:"skipped"`           // number of benchmarks left out by the selection options
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"` // why the file could not be read
+}
+
+// report of the ingestion of files, with totals over all files
+type IngestReport struct {
+	Files   []FileReport `json:"files"`
+	Points  int          `json:"points"`
+	Skipped int          `json:"skipped"`
+	Errors  int          `json:"errors"` // number of files not read
+}
+
+// extensions of archives and compressed files, walked in directories besides those of the registered formats
+var archiveExtensions = []string{".zip", ".tar", ".tgz", ".gz"}
+
+// ReadFile reads a file in any registered format (see DetectReader), possibly compressed or an archive;
+// the server configuration in companion files of the file (vllm-args.txt, server.json) overrides the one
+// of the options and of benchmark metadata
+func ReadFile(path string, opts *Options) ([]*Input, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if opts, err = withServerConfig(path, opts); err != nil {
+		return nil, err
+	}
+	return DetectReader(file, path, opts)
+}
+
+// overlay the server configuration in companion files of a file on the options
+func withServerConfig(path string, opts *Options) (*Options, error) {
+	server, err := LoadServerConfig(path)
+	if err != nil || server == nil {
+		return opts, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	withServer := *opts
+	withServer.Server = server.Overlay(opts.Server)
+	return &withServer, nil
+}
+
+// Ingest reads the files given by paths of files or directories, or glob patterns, each in the format
+// detected for it, and merges their data sets. Directories are walked, reading files with extensions of
+// registered formats or of archives, and skipping hidden files and companion files of sweeps. The data
+// points of each file are grouped by their source label: the path of the file, or of the archive followed
+// by the path of the file in it. Scrapes of vLLM metrics in several files of a directory are read together
+// (see PrometheusData.ReadFiles), their data points having the directory as source.
+// An error is returned if a path matches no file, or if no data point is read.
+func Ingest(paths []string, opts *Options) (*core.DataSet, *IngestReport, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	scrapeFiles := groupScrapeFiles(files)
+	report := &IngestReport{Files: []FileReport{}}
+	var dataSet *core.DataSet
+	var firstErr error
+	numRead := 0
+	for _, path := range files {
+		var inputs []*Input
+		if group := scrapeFiles[path]; len(group) > 1 {
+			if path != group[0] {
+				continue
+			}
+			path = filepath.Dir(path)
+			inputs, err = readScrapeFiles(path, group, opts)
+		} else {
+			inputs, err = ReadFile(path, opts)
+		}
+		if err != nil {
+			report.Files = append(report.Files, FileReport{Path: path, Error: err.Error()})
+			report.Errors++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, input := range inputs {
+			fileReport := FileReport{Path: input.Path, Archive: input.Archive}
+			if input.Err != nil {
+				fileReport.Error = input.Err.Error()
+				report.Files = append(report.Files, fileReport)
+				report.Errors++
+				continue
+			}
+			if fileReport.Archive != "" {
+				input.DataSet.SetSource(input.Archive + "/" + input.Path)
+			}
+			fileReport.Format, fileReport.Points = input.Format, input.DataSet.Size()
+			if s, ok := input.Reader.(Skipper); ok {
+				fileReport.Skipped = s.NumSkipped()
+			}
+			if w, ok := input.Reader.(Warner); ok {
+				fileReport.Warnings = w.Warnings()
+			}
+			report.Files = append(report.Files, fileReport)
+			report.Points += fileReport.Points
+			report.Skipped += fileReport.Skipped
+
+			numRead++
+			if dataSet == nil {
+				dataSet = input.DataSet
+			} else if err := dataSet.Merge(input.DataSet); err != nil {
+				return nil, report, err
+			}
+		}
+	}
+	if dataSet == nil {
+		if len(files) == 1 && firstErr != nil {
+			return nil, report, firstErr
+		}
+		return nil, report, fmt.Errorf("no data points in %d files", len(report.Files))
+	}
+	if numRead > 1 {
+		dataSet.Name = fmt.Sprintf("data set of %d files", numRead)
+	}
+	return dataSet, report, nil
+}
+
+// group the files holding scrapes of vLLM metrics by directory, in order, returning the group of each file
+func groupScrapeFiles(files []string) map[string][]string {
+	format, ok := Lookup("vllm-metrics")
+	if !ok {
+		return nil
+	}
+	groups := map[string][]string{}
+	for _, path := range files {
+		if !hasExtension(format, strings.ToLower(filepath.Ext(path))) {
+			continue
+		}
+		dataBytes, err := os.ReadFile(path)
+		if err != nil || format.Sniff(dataBytes, path) != nil {
+			continue
+		}
+		dir := filepath.Dir(path)
+		groups[dir] = append(groups[dir], path)
+	}
+	groupOf := map[string][]string{}
+	for _, group := range groups {
+		for _, path := range group {
+			groupOf[path] = group
+		}
+	}
+	return groupOf
+}
+
+// read the scrapes of vLLM metrics in files of a directory into an input with the directory as path
+func readScrapeFiles(dir string, paths []string, opts *Options) ([]*Input, error) {
+	opts, err := withServerConfig(paths[0], opts)
+	if err != nil {
+		return nil, err
+	}
+	p := NewPrometheusData()
+	if opts != nil {
+		p.SetOptions(opts)
+	}
+	if err := p.ReadFiles(paths...); err != nil {
+		return nil, err
+	}
+	dataSet := p.CreateDataSet()
+	if dataSet.Size() == 0 {
+		return nil, fmt.Errorf("no data points in scrapes of %d files in %s", len(paths), dir)
+	}
+	dataSet.SetSource(dir)
+	return []*Input{{Path: dir, Format: "vllm-metrics", Reader: p, DataSet: dataSet}}, nil
+}
+
+// expand paths of files or directories, and glob patterns, into the files to read, in order, without duplicates
+func expandPaths(paths []string) ([]string, error) {
+	files := []string{}
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, pattern := range paths {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", pattern)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			dirFiles, err := walkDataFiles(match)
+			if err != nil {
+				return nil, err
+			}
+			if len(dirFiles) == 0 && len(matches) == 1 {
+				return nil, fmt.Errorf("no data files in %s", match)
+			}
+			for _, f := range dirFiles {
+				add(f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// walk a directory tree for data files, in lexical order
+func walkDataFiles(dir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if path != dir && strings.HasPrefix(name, ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || name == ServerConfigFileName || name == VLLMArgsFileName || !isDataFileName(name) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// check whether a file name has the extension of a registered format, or of an archive
+func isDataFileName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range archiveExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for _, f := range formats {
+		if hasExtension(f, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize the report in a table, a row per file
+func (r *IngestReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tFORMAT\tPOINTS\tSKIPPED\tERROR")
+	for _, f := range r.Files {
+		path := f.Path
+		if f.Archive != "" {
+			path = f.Archive + "/" + f.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", path, f.Format, f.Points, f.Skipped, firstLine(f.Error))
+	}
+	fmt.Fprintf(w, "total: %d files\t\t%d\t%d\t%d errors\n", len(r.Files), r.Points, r.Skipped, r.Errors)
+	w.Flush()
+	return b.String()
+}
+
+// get the first line of a message
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
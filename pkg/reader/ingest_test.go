@@ -0,0 +1,168 @@
+package reader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIngest(t *testing.T) {
+	guideLLM := readTestFile(t, "../../samples/guidellm.json")
+	csv := readTestFile(t, "testdata/guidellm-v2.csv")
+	vllmBench := readTestFile(t, "testdata/vllm-bench.jsonl")
+
+	// a tree of sweeps, with files not to be read
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"raw/sweep-i64-o64/benchmarks.json":  guideLLM,
+		"raw/sweep-i64-o256/benchmarks.csv":  csv,
+		"raw/sweep-i64-o256/server.json":     []byte(`{"model": "m"}`),
+		"raw/.cache/benchmarks.json":         guideLLM,
+		"raw/notes.md":                       []byte("sweeps of May\n"),
+		"vllm/bench.jsonl":                   vllmBench,
+		"vllm/broken.json":                   []byte("hello, world\n"),
+		"archives/sweeps.tar.gz":             gzipData(t, tarData(t, []archiveFile{{name: "a/benchmarks.json", data: guideLLM}})),
+		"empty/README.md":                    []byte("nothing here\n"),
+		"raw/sweep-i64-o64/.benchmarks.json": guideLLM,
+		"metrics/a.prom":                     []byte("vllm:time_to_first_token_seconds_sum 1 1700000000000\nvllm:time_to_first_token_seconds_count 10 1700000000000\n"),
+		"metrics/b.prom":                     []byte("vllm:time_to_first_token_seconds_sum 4 1700000030000\nvllm:time_to_first_token_seconds_count 40 1700000030000\n"),
+		"lone/scrape.prom":                   []byte("vllm:time_to_first_token_seconds_sum 1\nvllm:time_to_first_token_seconds_count 10\n"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	in := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name        string
+		paths       []string
+		opts        *Options
+		expectError string
+		validateFn  func(t *testing.T, report *IngestReport, sources map[string]int)
+	}{
+		{
+			name:  "directory",
+			paths: []string{in("raw")},
+			validateFn: func(t *testing.T, report *IngestReport, sources map[string]int) {
+				if len(report.Files) != 2 || report.Errors != 0 || report.Points != 12 || report.Skipped != 2 {
+					t.Fatalf("unexpected report %+v", report)
+				}
+				if report.Files[0].Format != "guidellm-csv2" || report.Files[1].Format != "guidellm-json" {
+					t.Errorf("files not read in lexical order: %+v", report.Files)
+				}
+				if sources[in("raw/sweep-i64-o64/benchmarks.json")] != 9 || sources[in("raw/sweep-i64-o256/benchmarks.csv")] != 3 {
+					t.Errorf("unexpected points per source %v", sources)
+				}
+			},
+		},
+		{
+			name:  "glob, file and archive",
+			paths: []string{in("raw/sweep-*/benchmarks.json"), in("archives/sweeps.tar.gz"), in("vllm/bench.jsonl")},
+			validateFn: func(t *testing.T, report *IngestReport, sources map[string]int) {
+				if len(report.Files) != 3 || report.Points != 19 {
+					t.Fatalf("unexpected report %+v", report)
+				}
+				if f := report.Files[1]; f.Path != "a/benchmarks.json" || f.Archive != in("archives/sweeps.tar.gz") {
+					t.Errorf("unexpected report of archive file %+v", f)
+				}
+				if sources[in("archives/sweeps.tar.gz")+"/a/benchmarks.json"] != 9 || sources[in("vllm/bench.jsonl")] != 1 {
+					t.Errorf("unexpected points per source %v", sources)
+				}
+			},
+		},
+		{
+			name:  "unrecognized file",
+			paths: []string{in("vllm")},
+			validateFn: func(t *testing.T, report *IngestReport, sources map[string]int) {
+				if len(report.Files) != 2 || report.Errors != 1 || report.Points != 1 {
+					t.Fatalf("unexpected report %+v", report)
+				}
+				if f := report.Files[1]; f.Path != in("vllm/broken.json") || !strings.Contains(f.Error, "unrecognized input format") {
+					t.Errorf("unexpected report of unrecognized file %+v", f)
+				}
+			},
+		},
+		{
+			name:  "scrapes in files of a directory",
+			paths: []string{in("metrics")},
+			validateFn: func(t *testing.T, report *IngestReport, sources map[string]int) {
+				if len(report.Files) != 1 || report.Errors != 0 || report.Points != 1 {
+					t.Fatalf("unexpected report %+v", report)
+				}
+				if f := report.Files[0]; f.Path != in("metrics") || f.Format != "vllm-metrics" || sources[in("metrics")] != 1 {
+					t.Errorf("unexpected report of scrapes %+v, points per source %v", f, sources)
+				}
+			},
+		},
+		{
+			name:  "selection options",
+			paths: []string{in("raw/sweep-i64-o64")},
+			opts:  &Options{MaxPoints: 2, Selection: SelectFirst},
+			validateFn: func(t *testing.T, report *IngestReport, sources map[string]int) {
+				if report.Points != 2 || report.Skipped != 8 {
+					t.Errorf("unexpected report %+v", report)
+				}
+			},
+		},
+		{
+			name:        "single unrecognized file",
+			paths:       []string{in("vllm/broken.json")},
+			expectError: "unrecognized input format",
+		},
+		{
+			name:        "single scrape",
+			paths:       []string{in("lone")},
+			expectError: "vllm-metrics data of " + in("lone/scrape.prom") + ": needs at least two scrapes",
+		},
+		{
+			name:        "no data points",
+			paths:       []string{in("vllm/broken.json"), in("raw/notes.md")},
+			expectError: "no data points in 2 files",
+		},
+		{
+			name:        "directory without data files",
+			paths:       []string{in("empty")},
+			expectError: "no data files in",
+		},
+		{
+			name:        "pattern without matches",
+			paths:       []string{in("raw/*.yaml")},
+			expectError: "no files match",
+		},
+		{
+			name:        "missing file",
+			paths:       []string{in("missing.json")},
+			expectError: "no such file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataSet, report, err := Ingest(tt.paths, tt.opts)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dataSet.Size() != report.Points {
+				t.Errorf("got %d points, report has %d", dataSet.Size(), report.Points)
+			}
+			sources := map[string]int{}
+			for i := range dataSet.Data {
+				sources[dataSet.LabelsOf(i).Source]++
+			}
+			tt.validateFn(t, report, sources)
+		})
+	}
+}
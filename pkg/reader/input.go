@@ -0,0 +1,225 @@
+package reader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// an input of a stream: the stream itself, or a file in an archive
+type Input struct {
+	Path    string        // file name of the stream, or path of the file in the archive
+	Archive string        // file name of the archive holding the file, empty if the stream is not an archive
+	Format  string        // name of the detected format
+	Reader  Reader        // reader of the detected format, nil if not recognized
+	DataSet *core.DataSet // data set read, with the path of the input as source
+	Err     error         // why the input could not be read
+}
+
+// magic numbers of compressed data and archives
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+const (
+	tarMagicOffset    = 257       // offset of "ustar" in the header of a tar archive
+	sniffLength       = 512       // length of data peeked to recognize compression and archives
+	streamSniffLength = 256 << 10 // length of data peeked to recognize formats of streams; shorter data is read whole
+)
+
+// WalkInputs calls fn with the data of each input in a stream from a file with the given name (possibly empty):
+// the stream itself, decompressed if gzipped, or each regular file in a zip or tar archive (possibly
+// gzipped, e.g. .tar.gz), with its path in the archive; gzipped files are decompressed and their .gz
+// extension dropped. Files of tar archives are read one at a time, while zip archives are read whole,
+// unless the stream is a file.
+func WalkInputs(in io.Reader, filename string, fn func(path string, dataBytes []byte) error) error {
+	_, err := walkInputs(in, filename, func(path string, input *bufio.Reader) error {
+		dataBytes, err := io.ReadAll(input)
+		if err != nil {
+			return err
+		}
+		return fn(path, dataBytes)
+	})
+	return err
+}
+
+// walk the inputs of a stream, each a stream peekable up to streamSniffLength, reporting whether it is an archive
+func walkInputs(in io.Reader, filename string, fn func(path string, input *bufio.Reader) error) (bool, error) {
+	in, filename, err := decompress(in, filename)
+	if err != nil {
+		return false, err
+	}
+	head, in := peek(in, sniffLength)
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		return true, walkZip(in, fn)
+	case len(head) >= tarMagicOffset+5 && string(head[tarMagicOffset:tarMagicOffset+5]) == "ustar":
+		return true, walkTar(in, fn)
+	}
+	return false, fn(filename, bufio.NewReaderSize(in, streamSniffLength))
+}
+
+// peek at the start of a stream, without consuming it from files
+func peek(in io.Reader, n int) ([]byte, io.Reader) {
+	if file, ok := in.(*os.File); ok {
+		if offset, err := file.Seek(0, io.SeekCurrent); err == nil {
+			head := make([]byte, n)
+			k, _ := file.ReadAt(head, offset)
+			return head[:k], file
+		}
+	}
+	buffered := bufio.NewReaderSize(in, n)
+	head, _ := buffered.Peek(n)
+	return head, buffered
+}
+
+// decompress a stream if gzipped, dropping the .gz extension of its file name (.tgz becoming .tar)
+func decompress(in io.Reader, filename string) (io.Reader, string, error) {
+	head, in := peek(in, sniffLength)
+	if !bytes.HasPrefix(head, gzipMagic) {
+		return in, filename, nil
+	}
+	gzipReader, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid gzip data: %w", err)
+	}
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".tgz"):
+		filename = filename[:len(filename)-len(".tgz")] + ".tar"
+	case strings.HasSuffix(lower, ".gz"):
+		filename = filename[:len(filename)-len(".gz")]
+	}
+	return gzipReader, filename, nil
+}
+
+// walk the regular files of a tar archive
+func walkTar(in io.Reader, fn func(path string, input *bufio.Reader) error) error {
+	tarReader := tar.NewReader(in)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || isHiddenPath(header.Name) {
+			continue
+		}
+		if err := walkMember(tarReader, header.Name, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// walk the regular files of a zip archive, read from a file directly or buffered whole, as its index is at its end
+func walkZip(in io.Reader, fn func(path string, input *bufio.Reader) error) error {
+	var zipReader *zip.Reader
+	var err error
+	if file, ok := in.(*os.File); ok {
+		offset, seekErr := file.Seek(0, io.SeekCurrent)
+		info, statErr := file.Stat()
+		if err := errors.Join(seekErr, statErr); err != nil {
+			return err
+		}
+		zipReader, err = zip.NewReader(io.NewSectionReader(file, offset, info.Size()-offset), info.Size()-offset)
+	} else {
+		dataBytes, readErr := io.ReadAll(in)
+		if readErr != nil {
+			return readErr
+		}
+		zipReader, err = zip.NewReader(bytes.NewReader(dataBytes), int64(len(dataBytes)))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid zip archive: %w", err)
+	}
+	for _, file := range zipReader.File {
+		if !file.Mode().IsRegular() || isHiddenPath(file.Name) {
+			continue
+		}
+		member, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		err = walkMember(member, file.Name, fn)
+		member.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk a file of an archive, decompressed if gzipped
+func walkMember(member io.Reader, name string, fn func(path string, input *bufio.Reader) error) error {
+	in, memberPath, err := decompress(member, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return fn(memberPath, bufio.NewReaderSize(in, streamSniffLength))
+}
+
+// check for files of operating systems and tools in archives (e.g. __MACOSX/, .DS_Store)
+func isHiddenPath(name string) bool {
+	for _, part := range strings.Split(path.Clean(name), "/") {
+		if (strings.HasPrefix(part, ".") && part != "." && part != "..") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectReader is DetectWith reading from a stream (see WalkInputs), with an input per file of an archive.
+// The data set of each recognized input has its path as source; inputs not recognized have an error.
+// Long inputs whose format is recognized from their start are read by its reader as streams, if it is a
+// StreamReader (see Format.SniffPrefix), without holding them whole in memory; others are read whole.
+// An error is returned if the stream cannot be read, or if no input is recognized.
+func DetectReader(in io.Reader, filename string, opts *Options) ([]*Input, error) {
+	inputs := []*Input{}
+	recognized := 0
+	isArchive, err := walkInputs(in, filename, func(path string, stream *bufio.Reader) error {
+		input := &Input{Path: path}
+		format, r, err := detectStream(stream, path, opts)
+		if err == nil {
+			input.Format, input.Reader = format.Name, r
+			input.DataSet = r.CreateDataSet()
+			input.DataSet.SetSource(path)
+			recognized++
+		} else {
+			input.Err = err
+		}
+		inputs = append(inputs, input)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isArchive {
+		for _, input := range inputs {
+			input.Archive = filename
+		}
+	}
+	if recognized == 0 {
+		if !isArchive {
+			return nil, inputs[0].Err
+		}
+		name := filename
+		if name == "" {
+			name = "archive"
+		}
+		return nil, fmt.Errorf("no recognized files in %s (%d files)", name, len(inputs))
+	}
+	return inputs, nil
+}
@@ -0,0 +1,302 @@
+package reader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// a file of a test archive
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func tarData(t *testing.T, files []archiveFile) []byte {
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	for _, f := range files {
+		if err := w.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func zipData(t *testing.T, files []archiveFile) []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, f := range files {
+		fw, err := w.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// long inputs of the formats read from streams, padded past the start peeked to recognize them
+func longInputs(t *testing.T) (guideLLM, html, trace []byte) {
+	padding := strings.Repeat("x", streamSniffLength)
+	guideLLM = bytes.TrimSpace(readTestFile(t, "../../samples/guidellm.json"))
+	guideLLM = append(guideLLM[:len(guideLLM)-1], []byte(`, "padding": "`+padding+`"}`)...)
+	report := readTestFile(t, "testdata/guidellm-report.html")
+	html = []byte("<!DOCTYPE html>\n<style>/* " + padding + " */</style>\n" + strings.TrimPrefix(string(report), "<!DOCTYPE html>"))
+	trace = bytes.Repeat(readTestFile(t, "testdata/trace.jsonl"), streamSniffLength/len(readTestFile(t, "testdata/trace.jsonl"))+1)
+	return guideLLM, html, trace
+}
+
+func TestDetectReader(t *testing.T) {
+	guideLLM := readTestFile(t, "../../samples/guidellm.json")
+	csv := readTestFile(t, "testdata/guidellm-v2.csv")
+	longGuideLLM, longHTML, longTrace := longInputs(t)
+	sweep := []archiveFile{
+		{name: "raw/sweep-i64-o64/benchmarks.json", data: guideLLM},
+		{name: "raw/sweep-i64-o256/benchmarks.csv", data: csv},
+		{name: "raw/README.txt", data: []byte("sweeps of May\n")},
+		{name: "raw/.benchmarks.json.swp", data: guideLLM},
+	}
+
+	tests := []struct {
+		name        string
+		input       func(t *testing.T) (io.Reader, string)
+		expectError string
+		wantPaths   []string // paths of the inputs, recognized or not
+		wantErrors  int      // number of inputs not recognized
+	}{
+		{
+			name: "plain file",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(guideLLM), "benchmarks.json"
+			},
+			wantPaths: []string{"benchmarks.json"},
+		},
+		{
+			name: "gzipped file",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(gzipData(t, csv)), "benchmarks.csv.gz"
+			},
+			wantPaths: []string{"benchmarks.csv"},
+		},
+		{
+			name: "gzipped tar archive",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(gzipData(t, tarData(t, sweep))), "sweeps.tar.gz"
+			},
+			wantPaths:  []string{"raw/sweep-i64-o64/benchmarks.json", "raw/sweep-i64-o256/benchmarks.csv", "raw/README.txt"},
+			wantErrors: 1,
+		},
+		{
+			name: "zip archive with a gzipped file",
+			input: func(t *testing.T) (io.Reader, string) {
+				files := []archiveFile{{name: "a/benchmarks.json.gz", data: gzipData(t, guideLLM)}, {name: "__MACOSX/a/._benchmarks.json.gz"}}
+				return bytes.NewReader(zipData(t, files)), "sweeps.zip"
+			},
+			wantPaths: []string{"a/benchmarks.json"},
+		},
+		{
+			name: "zip archive file",
+			input: func(t *testing.T) (io.Reader, string) {
+				path := filepath.Join(t.TempDir(), "sweeps.zip")
+				if err := os.WriteFile(path, zipData(t, sweep), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				file, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { file.Close() })
+				return file, path
+			},
+			wantPaths:  []string{"raw/sweep-i64-o64/benchmarks.json", "raw/sweep-i64-o256/benchmarks.csv", "raw/README.txt"},
+			wantErrors: 1,
+		},
+		{
+			name: "long GuideLLM results",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(longGuideLLM), "benchmarks.json"
+			},
+			wantPaths: []string{"benchmarks.json"},
+		},
+		{
+			name: "long gzipped GuideLLM report in a tar archive",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(gzipData(t, tarData(t, []archiveFile{{name: "report.html.gz", data: gzipData(t, longHTML)}}))), "sweeps.tar"
+			},
+			wantPaths: []string{"report.html"},
+		},
+		{
+			name: "long trace",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(longTrace), ""
+			},
+			wantPaths: []string{""},
+		},
+		{
+			name: "archive without recognized files",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(tarData(t, sweep[2:])), "notes.tar"
+			},
+			expectError: "no recognized files in notes.tar (1 files)",
+		},
+		{
+			name: "unrecognized file",
+			input: func(t *testing.T) (io.Reader, string) {
+				return strings.NewReader("hello, world\n"), "notes.txt"
+			},
+			expectError: "unrecognized input format of notes.txt",
+		},
+		{
+			name: "corrupt gzip data",
+			input: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewReader(gzipData(t, guideLLM)[:20]), "benchmarks.json.gz"
+			},
+			expectError: "unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, filename := tt.input(t)
+			inputs, err := DetectReader(in, filename, nil)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(inputs) != len(tt.wantPaths) {
+				t.Fatalf("got %d inputs, want %d", len(inputs), len(tt.wantPaths))
+			}
+			numErrors := 0
+			for i, input := range inputs {
+				if input.Path != tt.wantPaths[i] {
+					t.Errorf("input %d: path %q, want %q", i, input.Path, tt.wantPaths[i])
+				}
+				if input.Err != nil {
+					var detectErr *DetectError
+					if !errors.As(input.Err, &detectErr) || input.DataSet != nil {
+						t.Errorf("input %d: unexpected error %v", i, input.Err)
+					}
+					numErrors++
+					continue
+				}
+				if input.DataSet.Size() == 0 || input.DataSet.Metadata.Source != input.Path {
+					t.Errorf("input %d: got %d points from source %q", i, input.DataSet.Size(), input.DataSet.Metadata.Source)
+				}
+			}
+			if numErrors != tt.wantErrors {
+				t.Errorf("got %d inputs not recognized, want %d", numErrors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestDetectPrefix(t *testing.T) {
+	longGuideLLM, longHTML, longTrace := longInputs(t)
+	tests := []struct {
+		name     string
+		data     []byte
+		filename string
+		want     string // name of the format read from streams, empty if read whole
+	}{
+		{name: "GuideLLM results", data: longGuideLLM, filename: "benchmarks.json", want: "guidellm-json"},
+		{name: "GuideLLM report", data: longHTML, filename: "report.html", want: "guidellm-html"},
+		{name: "GuideLLM report without extension", data: longHTML, want: "guidellm-html"},
+		{name: "trace", data: longTrace, filename: "trace.jsonl", want: "request-trace"},
+		{name: "data set", data: readTestFile(t, "../../samples/qm_train_s4.json"), filename: "data.json"},
+		{name: "vLLM results", data: readTestFile(t, "testdata/vllm-bench.jsonl"), filename: "bench.jsonl"},
+		{name: "CSV results", data: readTestFile(t, "testdata/guidellm-v2.csv"), filename: "benchmarks.csv"},
+		{name: "truncated object", data: []byte(`{"padding": "xxx`), filename: "benchmarks.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := tt.data[:min(len(tt.data), streamSniffLength)]
+			got := ""
+			if f := detectPrefix(prefix, tt.filename); f != nil {
+				got = f.Name
+			}
+			if got != tt.want {
+				t.Errorf("got format %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// reader of a third-party format read from streams, recording whether it was
+type mockStreamReader struct {
+	mockLineReader
+	streamed bool
+}
+
+func (m *mockStreamReader) ReadFromStream(in io.Reader) error {
+	dataBytes, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	m.streamed = true
+	return m.ReadFrom(dataBytes)
+}
+
+func TestDetectReader_Stream(t *testing.T) {
+	Register(&Format{
+		Name:        "mock-stream",
+		Extensions:  []string{".mocks"},
+		Sniff:       sniffMarker("#mock"),
+		SniffPrefix: sniffMarker("#mock"),
+		New:         func() Reader { return &mockStreamReader{} },
+	})
+	long := "#mock\n" + strings.Repeat("1\n", streamSniffLength)
+	for _, tt := range []struct {
+		data         string
+		wantStreamed bool
+	}{
+		{data: "#mock\n1\n2\n", wantStreamed: false},
+		{data: long, wantStreamed: true},
+		{data: strings.Repeat(" ", streamSniffLength) + long, wantStreamed: false},
+	} {
+		inputs, err := DetectReader(strings.NewReader(tt.data), "rates.mocks", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		r, ok := inputs[0].Reader.(*mockStreamReader)
+		if !ok || r.streamed != tt.wantStreamed || inputs[0].DataSet.Size() != len(r.lines) {
+			t.Errorf("unexpected reader %T of %d bytes, streamed %v", inputs[0].Reader, len(tt.data), ok && r.streamed)
+		}
+	}
+}
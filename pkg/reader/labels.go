@@ -0,0 +1,13 @@
+package reader
+
+import "encoding/json"
+
+// get the model name under a given key in a json string describing the backend (empty if unavailable)
+func backendModel(backendJSON string, key string) string {
+	var backend map[string]any
+	if err := json.Unmarshal([]byte(backendJSON), &backend); err != nil {
+		return ""
+	}
+	model, _ := backend[key].(string)
+	return model
+}
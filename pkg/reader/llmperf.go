@@ -0,0 +1,257 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// data in Ray LLMPerf summary files (<model>_<input>_<output>_summary.json), one run per summary
+type LLMPerfData struct {
+	Runs []RunLLMPerf
+	readerOptions
+}
+
+// summary of a token_benchmark_ray run
+type RunLLMPerf struct {
+	Name                  string  `json:"name"`
+	Model                 string  `json:"model"`
+	Timestamp             float64 `json:"timestamp"`
+	NumConcurrentRequests int     `json:"num_concurrent_requests"`
+
+	// requested token counts
+	MeanInputTokens    float64 `json:"mean_input_tokens"`
+	StddevInputTokens  float64 `json:"stddev_input_tokens"`
+	MeanOutputTokens   float64 `json:"mean_output_tokens"`
+	StddevOutputTokens float64 `json:"stddev_output_tokens"`
+
+	NumCompletedRequests    int     `json:"results_num_completed_requests"`
+	CompletedRequestsPerMin float64 `json:"results_num_completed_requests_per_min"`
+	NumErrors               int     `json:"results_number_errors"`
+
+	// statistics of per-request metrics (e.g. ttft_s), keyed like mean, stddev or quantiles_p99,
+	// flattened into keys such as results_ttft_s_quantiles_p99, hence not given as struct tags
+	Results map[string]map[string]float64 `json:"-"`
+}
+
+// keys of statistics in LLMPerf summaries: results_<metric>_<statistic>
+var llmPerfResultKey = regexp.MustCompile(`^results_(.+)_(mean|min|max|stddev|quantiles_p[0-9.]+)$`)
+
+// per-request metrics in LLMPerf summaries
+const (
+	llmPerfTTFT         = "ttft_s"
+	llmPerfITL          = "inter_token_latency_s" // time over output tokens, including the first
+	llmPerfLatency      = "end_to_end_latency_s"
+	llmPerfInputTokens  = "number_input_tokens"
+	llmPerfOutputTokens = "number_output_tokens"
+)
+
+func (r *RunLLMPerf) UnmarshalJSON(data []byte) error {
+	type plainRunLLMPerf RunLLMPerf
+	if err := json.Unmarshal(data, (*plainRunLLMPerf)(r)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Results = map[string]map[string]float64{}
+	for key, value := range raw {
+		m := llmPerfResultKey.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		var v float64
+		if err := json.Unmarshal(value, &v); err != nil {
+			// statistics of runs without successful requests are null
+			continue
+		}
+		if r.Results[m[1]] == nil {
+			r.Results[m[1]] = map[string]float64{}
+		}
+		r.Results[m[1]][strings.TrimPrefix(m[2], "quantiles_")] = v
+	}
+	return nil
+}
+
+// get the statistics of a per-request metric, scaled by a factor
+func (r *RunLLMPerf) stats(metric string, scale float64) metricStats {
+	scaled := map[string]float64{}
+	for key, v := range r.Results[metric] {
+		scaled[key] = v * scale
+	}
+	return metricStats{Mean: scaled["mean"], Median: scaled["p50"], Percentiles: percentilesFromMap(scaled)}
+}
+
+// get the statistics of token counts of requests, falling back to the requested counts
+func (r *RunLLMPerf) tokenStats(metric string, requestedMean, requestedStdDev float64) (metricStats, float64) {
+	stats := r.stats(metric, 1)
+	stdDev, ok := r.Results[metric]["stddev"]
+	if stats.Mean == 0 {
+		stats = metricStats{Mean: requestedMean, Median: requestedMean}
+	}
+	if !ok {
+		stdDev = requestedStdDev
+	}
+	return stats, stdDev
+}
+
+// get the inter-token latency (msec), excluding the first token, approximated from the mean (or median)
+// end-to-end latency, time to first token and output token count
+func (r *RunLLMPerf) itlStats(outputTokens metricStats) metricStats {
+	latency := r.stats(llmPerfLatency, 1000)
+	ttft := r.stats(llmPerfTTFT, 1000)
+	itl := func(latency, ttft, tokens float64) float64 {
+		if tokens <= 1 || latency < ttft {
+			return 0
+		}
+		return (latency - ttft) / (tokens - 1)
+	}
+	return metricStats{
+		Mean:   itl(latency.Mean, ttft.Mean, outputTokens.Mean),
+		Median: itl(latency.Median, ttft.Median, outputTokens.Median),
+	}
+}
+
+// get the strategy of a run, named as GuideLLM strategies
+func (r *RunLLMPerf) strategy() string {
+	if r.NumConcurrentRequests > 0 {
+		return fmt.Sprintf("%s@%d", StrategyConcurrent, r.NumConcurrentRequests)
+	}
+	return ""
+}
+
+func NewLLMPerfData() *LLMPerfData {
+	return &LLMPerfData{}
+}
+
+// read LLMPerf summaries: a single summary or a json array of summaries
+func (l *LLMPerfData) ReadFrom(dataBytes []byte) error {
+	runs, err := decodeLLMPerf(dataBytes)
+	if err != nil {
+		return err
+	}
+	l.Runs = runs
+	return nil
+}
+
+// ReadDir reads the LLMPerf summaries (files ending with _summary.json) of a directory, typically one
+// per number of concurrent requests; runs are ordered by concurrency
+func (l *LLMPerfData) ReadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	runs := []RunLLMPerf{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), "_summary.json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		dataBytes, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		summaries, err := decodeLLMPerf(dataBytes)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		runs = append(runs, summaries...)
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no LLMPerf summaries in %s", dir)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].NumConcurrentRequests < runs[j].NumConcurrentRequests })
+	l.Runs = runs
+	return nil
+}
+
+// decode a summary or an array of summaries, each with time to first token statistics
+func decodeLLMPerf(dataBytes []byte) ([]RunLLMPerf, error) {
+	runs := []RunLLMPerf{}
+	if trimmed := bytes.TrimSpace(dataBytes); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &runs); err != nil {
+			return nil, fmt.Errorf("invalid LLMPerf summaries: %w", err)
+		}
+	} else {
+		var run RunLLMPerf
+		if err := json.Unmarshal(dataBytes, &run); err != nil {
+			return nil, fmt.Errorf("invalid LLMPerf summary: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no LLMPerf summaries")
+	}
+	for i := range runs {
+		if _, ok := runs[i].Results[llmPerfTTFT]["mean"]; !ok {
+			return nil, fmt.Errorf("LLMPerf summary %d: no time to first token statistics", i+1)
+		}
+	}
+	return runs, nil
+}
+
+// create a data set object from the runs, selected by the reader options; latencies in seconds
+// are converted to msec, and the LLMPerf inter-token latency is the time per output token
+func (l *LLMPerfData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
+	for i := range l.Runs {
+		run := &l.Runs[i]
+		inputTokens, inputStdDev := run.tokenStats(llmPerfInputTokens, run.MeanInputTokens, run.StddevInputTokens)
+		outputTokens, outputStdDev := run.tokenStats(llmPerfOutputTokens, run.MeanOutputTokens, run.StddevOutputTokens)
+		ttftStats := run.stats(llmPerfTTFT, 1000)
+		itlStats := run.itlStats(outputTokens)
+		ttft, itl, input, output := l.values(&benchmarkStats{
+			TTFT:         ttftStats,
+			ITL:          itlStats,
+			TPOT:         run.stats(llmPerfITL, 1000),
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+		})
+		points = append(points, &core.DataPoint{
+			RequestRate:        run.CompletedRequestsPerMin / 60,
+			InputTokens:        input,
+			OutputTokens:       output,
+			InputTokensStdDev:  inputStdDev,
+			OutputTokensStdDev: outputStdDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    ttftStats.Percentiles,
+			MaxBatchSize:       config.DefaultMaxBatchSize,
+			MaxNumTokens:       config.DefaultMaxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: run.Name,
+				Strategy:    run.strategy(),
+				Model:       run.Model,
+			},
+		})
+	}
+	return l.createDataSet("LLMPerf data", points)
+}
+
+func (l *LLMPerfData) Print() {
+	for _, run := range l.Runs {
+		fmt.Printf("Run: %s\n", run.Name)
+		fmt.Printf("  Model: %s\n", run.Model)
+		fmt.Printf("  Strategy: %s\n", run.strategy())
+		fmt.Printf("  Completed: %d (%d errors)\n", run.NumCompletedRequests, run.NumErrors)
+		fmt.Printf("  RPS: %.2f\n", run.CompletedRequestsPerMin/60)
+		fmt.Printf("  TTFT: Mean=%.3f, Median=%.3f (sec)\n", run.Results[llmPerfTTFT]["mean"], run.Results[llmPerfTTFT]["p50"])
+		fmt.Printf("  ITL: Mean=%.3f, Median=%.3f (sec)\n", run.Results[llmPerfITL]["mean"], run.Results[llmPerfITL]["p50"])
+	}
+}
+
+func (l *LLMPerfData) Dump() string {
+	if jsonStr, err := json.Marshal(l); err == nil {
+		return fmt.Sprintf("LLMPerf data: %v\n", string(jsonStr))
+	}
+	return "LLMPerf data: <unavailable>"
+}
@@ -0,0 +1,111 @@
+package reader
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLLMPerfData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, l *LLMPerfData)
+	}{
+		{
+			name: "summary",
+			data: readTestFile(t, "testdata/llmperf/Qwen-Qwen2-5-0-5B_512_128_c1_summary.json"),
+			validateFn: func(t *testing.T, l *LLMPerfData) {
+				dp := l.CreateDataSet().Data[0]
+				// 57.6 completed requests per minute
+				if math.Abs(dp.RequestRate-0.96) > 1e-9 || dp.InputTokens != 512 || dp.OutputTokens != 127 || dp.OutputTokensStdDev != 3.5 {
+					t.Errorf("unexpected rate or token counts %+v", dp)
+				}
+				// latencies in seconds; ITL from end-to-end latency without TTFT, over the tokens after the first
+				if math.Abs(dp.AvgTTFTTime-24) > 1e-9 || math.Abs(dp.AvgITLTime-1016.0/126) > 1e-9 || math.Abs(dp.TTFTPercentiles.P99-39) > 1e-9 {
+					t.Errorf("unexpected latencies %+v", dp)
+				}
+				if dp.Labels.Strategy != "concurrent@1" || dp.Labels.Model != "Qwen/Qwen2.5-0.5B" {
+					t.Errorf("unexpected labels %+v", dp.Labels)
+				}
+			},
+		},
+		{
+			name: "inter-token latency as TPOT",
+			data: readTestFile(t, "testdata/llmperf/Qwen-Qwen2-5-0-5B_512_128_c1_summary.json"),
+			validateFn: func(t *testing.T, l *LLMPerfData) {
+				l.SetOptions(&Options{ITLMetric: MetricTPOT, TPOTStat: StatP90})
+				if dp := l.CreateDataSet().Data[0]; math.Abs(dp.AvgITLTime-8.6) > 1e-9 {
+					t.Errorf("got TPOT %v, want 8.6", dp.AvgITLTime)
+				}
+				if len(l.Warnings()) != 0 {
+					t.Errorf("unexpected warnings %q", l.Warnings())
+				}
+			},
+		},
+		{
+			name: "statistic missing in summary",
+			data: readTestFile(t, "testdata/llmperf/Qwen-Qwen2-5-0-5B_512_128_c1_summary.json"),
+			validateFn: func(t *testing.T, l *LLMPerfData) {
+				l.SetOptions(&Options{ITLStat: StatP90})
+				ds := l.CreateDataSet()
+				if w := l.Warnings(); ds.Data[0].AvgITLTime != 0 || len(w) != 1 || w[0] != "1 of 1 benchmarks without itl p90 in input, read as zero" {
+					t.Errorf("got ITL %v, warnings %q", ds.Data[0].AvgITLTime, w)
+				}
+			},
+		},
+		{
+			name: "array without result token counts",
+			data: []byte(`[{"name": "a", "num_concurrent_requests": 2, "mean_input_tokens": 300, "stddev_input_tokens": 20,
+				"mean_output_tokens": 50, "results_ttft_s_mean": 0.1, "results_ttft_s_quantiles_p50": 0.09,
+				"results_inter_token_latency_s_mean": null, "results_num_completed_requests_per_min": 30}]`),
+			validateFn: func(t *testing.T, l *LLMPerfData) {
+				dp := l.CreateDataSet().Data[0]
+				if dp.RequestRate != 0.5 || dp.InputTokens != 300 || dp.InputTokensStdDev != 20 || dp.OutputTokens != 50 || math.Abs(dp.AvgTTFTTime-90) > 1e-9 {
+					t.Errorf("requested token counts not used %+v", dp)
+				}
+			},
+		},
+		{
+			name:        "no time to first token",
+			data:        []byte(`{"name": "a", "results_num_completed_requests_per_min": 30}`),
+			expectError: "summary 1: no time to first token statistics",
+		},
+		{
+			name:        "invalid json",
+			data:        []byte(`{"name": `),
+			expectError: "invalid LLMPerf summary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLLMPerfData()
+			err := l.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, l)
+		})
+	}
+}
+
+func TestLLMPerfData_ReadDir(t *testing.T) {
+	l := NewLLMPerfData()
+	if err := l.ReadDir("testdata/llmperf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(l.Runs) != 2 || l.Runs[0].NumConcurrentRequests != 1 || l.Runs[1].NumConcurrentRequests != 4 {
+		t.Fatalf("runs not read in order of concurrency: %+v", l.Runs)
+	}
+	if err := NewLLMPerfData().ReadDir(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without summaries")
+	}
+}
@@ -0,0 +1,423 @@
+package reader
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// method of selecting data points when a benchmark file holds more than the maximum
+type Selection string
+
+const (
+	SelectFirst      Selection = "first"      // the first points, in file order
+	SelectEven       Selection = "even"       // points evenly spaced by request rate, including the lowest and highest
+	SelectSaturation Selection = "saturation" // points below a fraction of the saturation rate, evenly spaced by request rate
+)
+
+// kinds of GuideLLM benchmark strategies
+const (
+	StrategySynchronous = "synchronous"
+	StrategyThroughput  = "throughput"
+	StrategyConstant    = "constant"
+	StrategyPoisson     = "poisson"
+	StrategyConcurrent  = "concurrent"
+	StrategyGamma       = "gamma" // gamma-distributed arrivals of vLLM benchmark runs with a burstiness factor other than 1
+)
+
+// options of readers, selecting the benchmarks of a file that become data points
+type Options struct {
+	MaxPoints int       `json:"maxPoints"` // maximum number of data points (no limit if zero)
+	Selection Selection `json:"selection"` // method of selecting data points
+
+	// fraction of the saturation rate (the highest request rate of any benchmark, throughput
+	// included) below which points are selected by the saturation method
+	SaturationThreshold float64 `json:"saturationThreshold,omitempty"`
+
+	Include []string `json:"include,omitempty"` // strategy kinds to include (all if empty)
+	Exclude []string `json:"exclude,omitempty"` // strategy kinds to exclude (DefaultExclude if nil, none if empty)
+
+	// statistics extracted per metric (defaults if empty)
+	TTFTStat   Stat `json:"ttftStat,omitempty"`   // time to first token (default median, as TTFT has a long tail)
+	ITLStat    Stat `json:"itlStat,omitempty"`    // inter-token latency (default mean)
+	TPOTStat   Stat `json:"tpotStat,omitempty"`   // time per output token (default mean)
+	TokensStat Stat `json:"tokensStat,omitempty"` // input and output token counts, mean or median (default mean)
+
+	// metric giving the inter-token latency of data points, itl or tpot (default itl)
+	ITLMetric string `json:"itlMetric,omitempty"`
+
+	// server configuration of all benchmarks, overriding the one found in their metadata
+	Server *ServerConfig `json:"server,omitempty"`
+
+	// aggregation of per-request traces into data points (defaults of the trace reader if nil)
+	Trace *TraceOptions `json:"trace,omitempty"`
+}
+
+// options of the aggregation of per-request traces into data points, applied as given
+type TraceOptions struct {
+	GroupBy     TraceGrouping `json:"groupBy,omitempty"` // grouping of requests into data points (automatic if empty)
+	Window      float64       `json:"window"`            // length of time windows (sec), a single window if zero
+	WarmUp      float64       `json:"warmUp"`            // period discarded at the start of the trace, or of each run (sec)
+	CoolDown    float64       `json:"coolDown"`          // period discarded at the end of the trace, or of each run (sec)
+	MinRequests int           `json:"minRequests"`       // minimum number of successful requests of a data point
+}
+
+// get the default options of the aggregation of per-request traces
+func DefaultTraceOptions() *TraceOptions {
+	return &TraceOptions{
+		Window:      DefaultTraceWindow,
+		WarmUp:      DefaultTraceWarmUp,
+		CoolDown:    DefaultTraceCoolDown,
+		MinRequests: DefaultTraceMinRequests,
+	}
+}
+
+// statistic of a metric extracted by readers
+type Stat string
+
+const (
+	StatMean   Stat = "mean"
+	StatMedian Stat = "median"
+	StatP90    Stat = "p90"
+	StatP95    Stat = "p95"
+	StatP99    Stat = "p99"
+)
+
+// metrics giving the inter-token latency of data points
+const (
+	MetricITL  = "itl"
+	MetricTPOT = "tpot"
+)
+
+// default statistics extracted per metric
+const (
+	DefaultTTFTStat   = StatMedian
+	DefaultITLStat    = StatMean
+	DefaultTPOTStat   = StatMean
+	DefaultTokensStat = StatMean
+)
+
+// strategy kinds excluded unless options list the kinds to exclude: the throughput benchmark runs
+// at the capacity limit of the server, where the model does not apply
+var DefaultExclude = []string{StrategyThroughput}
+
+// get the options limiting benchmark files to their first points, excluding the throughput benchmark;
+// readers apply them only if given, nil or zero options reading every benchmark but the throughput one
+func DefaultOptions() *Options {
+	return &Options{
+		MaxPoints:           DefaultLimitNumDataPoints,
+		Selection:           SelectFirst,
+		SaturationThreshold: DefaultSaturationThreshold,
+		Exclude:             []string{StrategyThroughput},
+	}
+}
+
+// check the validity of options
+func (o *Options) Check() error {
+	switch o.Selection {
+	case "", SelectFirst, SelectEven, SelectSaturation:
+	default:
+		return fmt.Errorf("unknown selection %q", o.Selection)
+	}
+	if o.MaxPoints < 0 {
+		return fmt.Errorf("negative maximum number of points %d", o.MaxPoints)
+	}
+	if o.Selection == SelectSaturation && (o.SaturationThreshold <= 0 || o.SaturationThreshold > 1) {
+		return fmt.Errorf("saturation threshold %v not in (0, 1]", o.SaturationThreshold)
+	}
+	for _, stat := range []Stat{o.TTFTStat, o.ITLStat, o.TPOTStat} {
+		switch stat {
+		case "", StatMean, StatMedian, StatP90, StatP95, StatP99:
+		default:
+			return fmt.Errorf("unknown statistic %q", stat)
+		}
+	}
+	switch o.TokensStat {
+	case "", StatMean, StatMedian:
+	default:
+		return fmt.Errorf("statistic %q not available for token counts, expected mean or median", o.TokensStat)
+	}
+	if o.Server != nil && (o.Server.MaxBatchSize < 0 || o.Server.MaxNumTokens < 0) {
+		return fmt.Errorf("negative server limits %+v", *o.Server)
+	}
+	if t := o.Trace; t != nil {
+		switch t.GroupBy {
+		case GroupAuto, GroupWindow, GroupRun:
+		default:
+			return fmt.Errorf("unknown trace grouping %q, expected window or run", t.GroupBy)
+		}
+		if t.Window < 0 || t.WarmUp < 0 || t.CoolDown < 0 || t.MinRequests < 0 {
+			return fmt.Errorf("negative trace aggregation options %+v", *t)
+		}
+	}
+	switch o.ITLMetric {
+	case "", MetricITL, MetricTPOT:
+	default:
+		return fmt.Errorf("unknown inter-token latency metric %q, expected itl or tpot", o.ITLMetric)
+	}
+	known := []string{StrategySynchronous, StrategyThroughput, StrategyConstant, StrategyPoisson, StrategyConcurrent, StrategyGamma}
+	for _, kind := range append(append([]string{}, o.Include...), o.Exclude...) {
+		if !containsFold(known, kind) {
+			return fmt.Errorf("unknown strategy %q, expected one of %v", kind, known)
+		}
+	}
+	return nil
+}
+
+// get the kind of a benchmark strategy, e.g. constant for constant@10.00
+func StrategyKind(strategy string) string {
+	kind, _, _ := strings.Cut(strings.TrimSpace(strategy), "@")
+	return strings.ToLower(kind)
+}
+
+// Select returns the data points selected by the options, out of the data points of all
+// benchmarks in a file (labeled with their strategy): points of included strategy kinds,
+// then at most the maximum number of points by the selection method. Strategy kinds of
+// DefaultExclude are left out unless excluded kinds are given (possibly none) or they are
+// included explicitly; nil options select all other points.
+func (o *Options) Select(points []*core.DataPoint) []*core.DataPoint {
+	if o == nil {
+		o = &Options{}
+	}
+	exclude := o.Exclude
+	if exclude == nil {
+		for _, kind := range DefaultExclude {
+			if !containsFold(o.Include, kind) {
+				exclude = append(exclude, kind)
+			}
+		}
+	}
+	saturationRate := 0.0
+	candidates := []*core.DataPoint{}
+	for _, dp := range points {
+		saturationRate = math.Max(saturationRate, dp.RequestRate)
+		kind := ""
+		if dp.Labels != nil {
+			kind = StrategyKind(dp.Labels.Strategy)
+		}
+		if (len(o.Include) > 0 && !containsFold(o.Include, kind)) || containsFold(exclude, kind) {
+			continue
+		}
+		candidates = append(candidates, dp)
+	}
+
+	switch o.Selection {
+	case SelectEven:
+		return evenlySpaced(candidates, o.MaxPoints)
+	case SelectSaturation:
+		below := []*core.DataPoint{}
+		for _, dp := range candidates {
+			if dp.RequestRate <= o.SaturationThreshold*saturationRate {
+				below = append(below, dp)
+			}
+		}
+		return evenlySpaced(below, o.MaxPoints)
+	default:
+		if o.MaxPoints > 0 && len(candidates) > o.MaxPoints {
+			return candidates[:o.MaxPoints]
+		}
+		return candidates
+	}
+}
+
+// select at most n points evenly spaced by request rate, including the lowest and highest,
+// keeping the order of the points
+func evenlySpaced(points []*core.DataPoint, n int) []*core.DataPoint {
+	if n <= 0 || len(points) <= n {
+		return points
+	}
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return points[order[a]].RequestRate < points[order[b]].RequestRate })
+	chosen := map[int]bool{}
+	for i := 0; i < n; i++ {
+		k := 0
+		if n > 1 {
+			k = int(math.Round(float64(i) * float64(len(points)-1) / float64(n-1)))
+		}
+		chosen[order[k]] = true
+	}
+	selected := []*core.DataPoint{}
+	for i, dp := range points {
+		if chosen[i] {
+			selected = append(selected, dp)
+		}
+	}
+	return selected
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// get the statistics extracted per metric, with defaults for missing ones
+func (o *Options) stats() (ttft, itl, tpot, tokens Stat, itlMetric string) {
+	if o == nil {
+		o = &Options{}
+	}
+	orDefault := func(stat, def Stat) Stat {
+		if stat == "" {
+			return def
+		}
+		return stat
+	}
+	itlMetric = o.ITLMetric
+	if itlMetric == "" {
+		itlMetric = MetricITL
+	}
+	return orDefault(o.TTFTStat, DefaultTTFTStat), orDefault(o.ITLStat, DefaultITLStat),
+		orDefault(o.TPOTStat, DefaultTPOTStat), orDefault(o.TokensStat, DefaultTokensStat), itlMetric
+}
+
+// get the tags recording the statistics extracted per metric, for the metadata of data sets
+func (o *Options) statTags() map[string]string {
+	ttft, itl, tpot, tokens, itlMetric := o.stats()
+	tags := map[string]string{
+		"ttftStat":   string(ttft),
+		"tokensStat": string(tokens),
+		"itlMetric":  itlMetric,
+	}
+	if itlMetric == MetricTPOT {
+		tags["tpotStat"] = string(tpot)
+	} else {
+		tags["itlStat"] = string(itl)
+	}
+	return tags
+}
+
+// statistics of a metric in a benchmark
+type metricStats struct {
+	Mean        float64
+	Median      float64
+	Percentiles *config.Percentiles
+}
+
+// get a statistic of the metric (zero if unavailable); the median falls back to the 50th percentile
+func (m *metricStats) value(stat Stat) float64 {
+	switch stat {
+	case StatMean:
+		return m.Mean
+	case StatMedian:
+		if m.Median > 0 {
+			return m.Median
+		}
+		v, _ := m.Percentiles.Get(config.StatisticP50)
+		return v
+	default:
+		v, _ := m.Percentiles.Get(config.Statistic(stat))
+		return v
+	}
+}
+
+// statistics of the metrics of a benchmark
+type benchmarkStats struct {
+	TTFT, ITL, TPOT           metricStats
+	InputTokens, OutputTokens metricStats
+}
+
+// get the TTFT, ITL and token counts of a data point, by the statistics chosen per metric
+func (o *Options) values(b *benchmarkStats) (ttft, itl, inputTokens, outputTokens float64) {
+	ttftStat, itlStat, tpotStat, tokensStat, itlMetric := o.stats()
+	itl = b.ITL.value(itlStat)
+	if itlMetric == MetricTPOT {
+		itl = b.TPOT.value(tpotStat)
+	}
+	return b.TTFT.value(ttftStat), itl, b.InputTokens.value(tokensStat), b.OutputTokens.value(tokensStat)
+}
+
+// get the statistics chosen per metric that a benchmark lacks (read as zero), e.g. "tpot mean"
+func (o *Options) missing(b *benchmarkStats) []string {
+	ttftStat, itlStat, tpotStat, _, itlMetric := o.stats()
+	missing := []string{}
+	if b.TTFT.value(ttftStat) == 0 {
+		missing = append(missing, "ttft "+string(ttftStat))
+	}
+	if itlMetric == MetricTPOT && b.TPOT.value(tpotStat) == 0 {
+		missing = append(missing, "tpot "+string(tpotStat))
+	} else if itlMetric == MetricITL && b.ITL.value(itlStat) == 0 {
+		missing = append(missing, "itl "+string(itlStat))
+	}
+	return missing
+}
+
+// readers with options selecting the benchmarks that become data points
+type Configurable interface {
+	SetOptions(opts *Options)
+}
+
+// options of a reader, embedded in readers
+type readerOptions struct {
+	Options *Options `json:"-"`
+	skipped int      // number of benchmarks left out of the last data set created
+
+	numValues     int            // number of benchmarks whose values were extracted since the last data set created
+	missing       map[string]int // number of these benchmarks lacking each chosen statistic, e.g. "tpot mean"
+	missingOrder  []string       // chosen statistics lacking, in the order found
+	missingWarned []string       // warnings about chosen statistics lacking in the last data set created
+}
+
+// set the options of the reader (every benchmark but the throughput one read if nil)
+func (r *readerOptions) SetOptions(opts *Options) {
+	r.Options = opts
+}
+
+// get the number of benchmarks left out of the last data set created, by the selection options
+func (r *readerOptions) NumSkipped() int {
+	return r.skipped
+}
+
+// get the warnings about statistics chosen by the options that benchmarks of the last data set created lack
+func (r *readerOptions) Warnings() []string {
+	return r.missingWarned
+}
+
+// get the TTFT, ITL and token counts of a benchmark by the statistics chosen per metric, counting the
+// statistics it lacks (read as zero)
+func (r *readerOptions) values(b *benchmarkStats) (ttft, itl, inputTokens, outputTokens float64) {
+	if r.missing == nil {
+		r.missing = map[string]int{}
+	}
+	r.numValues++
+	for _, name := range r.Options.missing(b) {
+		if r.missing[name] == 0 {
+			r.missingOrder = append(r.missingOrder, name)
+		}
+		r.missing[name]++
+	}
+	return r.Options.values(b)
+}
+
+// create a data set from the data points of all benchmarks, selected by the options of the reader,
+// with the server configuration of the options, recording the statistics extracted per metric in the metadata;
+// the statistics lacking in the benchmarks whose values were extracted are reported as warnings
+func (r *readerOptions) createDataSet(name string, points []*core.DataPoint) *core.DataSet {
+	r.missingWarned = nil
+	for _, stat := range r.missingOrder {
+		r.missingWarned = append(r.missingWarned, fmt.Sprintf("%d of %d benchmarks without %s in input, read as zero",
+			r.missing[stat], r.numValues, stat))
+	}
+	r.numValues, r.missing, r.missingOrder = 0, nil, nil
+
+	dataSet := core.NewDataSet(name)
+	selected := r.Options.Select(points)
+	r.skipped = len(points) - len(selected)
+	for _, dp := range selected {
+		if r.Options != nil {
+			r.Options.Server.apply(dp)
+		}
+		dataSet.AppendDataPoint(dp)
+	}
+	dataSet.Metadata = &core.Labels{Tags: r.Options.statTags()}
+	return dataSet
+}
@@ -0,0 +1,217 @@
+package reader
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// points of a sweep: synchronous, throughput, then constant rates
+func newSweepPoints() []*core.DataPoint {
+	points := []*core.DataPoint{
+		{RequestRate: 1, Labels: &core.Labels{Strategy: "synchronous"}},
+		{RequestRate: 100, Labels: &core.Labels{Strategy: "throughput"}},
+	}
+	for _, rate := range []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 95} {
+		points = append(points, &core.DataPoint{RequestRate: rate, Labels: &core.Labels{Strategy: fmt.Sprintf("constant@%.2f", rate)}})
+	}
+	return points
+}
+
+func selectedRates(points []*core.DataPoint) []float64 {
+	rates := []float64{}
+	for _, dp := range points {
+		rates = append(rates, dp.RequestRate)
+	}
+	return rates
+}
+
+func TestOptions_Select(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *Options
+		want []float64
+	}{
+		{name: "nil", opts: nil, want: []float64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95}},
+		{name: "default", opts: DefaultOptions(), want: []float64{1, 10, 20, 30, 40, 50, 60}},
+		{name: "no limit", opts: &Options{Exclude: []string{StrategyThroughput}}, want: []float64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95}},
+		{name: "zero", opts: &Options{}, want: []float64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95}},
+		{name: "throughput included", opts: &Options{Include: []string{StrategyThroughput}}, want: []float64{100}},
+		{name: "everything", opts: &Options{Exclude: []string{}}, want: []float64{1, 100, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95}},
+		{name: "include constant only", opts: &Options{MaxPoints: 3, Include: []string{"Constant"}}, want: []float64{10, 20, 30}},
+		{name: "evenly spaced", opts: &Options{MaxPoints: 4, Selection: SelectEven, Exclude: []string{StrategyThroughput}},
+			want: []float64{1, 30, 70, 95}},
+		{name: "below saturation", opts: &Options{MaxPoints: 3, Selection: SelectSaturation, SaturationThreshold: 0.5,
+			Exclude: []string{StrategyThroughput, StrategySynchronous}}, want: []float64{10, 30, 50}},
+		{name: "below saturation without limit", opts: &Options{Selection: SelectSaturation, SaturationThreshold: 0.9},
+			want: []float64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectedRates(tt.opts.Select(newSweepPoints()))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got rates %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got rates %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOptions_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *Options
+		expectError bool
+	}{
+		{name: "default", opts: DefaultOptions()},
+		{name: "unknown selection", opts: &Options{Selection: "best"}, expectError: true},
+		{name: "negative maximum", opts: &Options{MaxPoints: -1}, expectError: true},
+		{name: "saturation without threshold", opts: &Options{Selection: SelectSaturation}, expectError: true},
+		{name: "unknown strategy", opts: &Options{Include: []string{"burst"}}, expectError: true},
+		{name: "strategy of bursty vLLM runs", opts: &Options{Exclude: []string{"gamma"}}},
+		{name: "trace options", opts: &Options{Trace: &TraceOptions{GroupBy: GroupRun, Window: 0, MinRequests: 1}}},
+		{name: "unknown trace grouping", opts: &Options{Trace: &TraceOptions{GroupBy: "model"}}, expectError: true},
+		{name: "negative trace window", opts: &Options{Trace: &TraceOptions{Window: -60}}, expectError: true},
+		{name: "percentile statistic", opts: &Options{TTFTStat: StatP99, ITLStat: StatMedian, ITLMetric: MetricTPOT}},
+		{name: "unknown statistic", opts: &Options{ITLStat: "p42"}, expectError: true},
+		{name: "percentile of token counts", opts: &Options{TokensStat: StatP90}, expectError: true},
+		{name: "unknown metric", opts: &Options{ITLMetric: "e2e"}, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Check(); (err != nil) != tt.expectError {
+				t.Errorf("Check() = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+// the same options apply to all GuideLLM readers
+func TestReaders_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "json", file: "../../samples/guidellm.json"},
+		{name: "csv", file: "testdata/guidellm.csv"},
+		{name: "csv v2", file: "testdata/guidellm-v2.csv"},
+		{name: "html", file: "../../samples/benchmarks.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readTestFile(t, tt.file)
+			all, err := DetectWith(data, tt.file, &Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			numAll := all.CreateDataSet().Size()
+
+			r, err := DetectWith(data, tt.file, &Options{MaxPoints: 2, Exclude: []string{StrategyThroughput}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ds := r.CreateDataSet()
+			if ds.Size() != min(2, numAll-1) {
+				t.Errorf("got %d points out of %d", ds.Size(), numAll)
+			}
+			for _, dp := range ds.Data {
+				if StrategyKind(dp.Labels.Strategy) == StrategyThroughput {
+					t.Error("throughput benchmark not excluded")
+				}
+			}
+		})
+	}
+}
+
+// the statistic extracted per metric follows the options, and is recorded in the metadata
+func TestReaders_Statistics(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "json", file: "../../samples/guidellm.json"},
+		{name: "csv", file: "testdata/guidellm.csv"},
+		{name: "csv v2", file: "testdata/guidellm-v2.csv"},
+		{name: "html", file: "../../samples/benchmarks.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readTestFile(t, tt.file)
+			r, err := DetectWith(data, tt.file, &Options{TTFTStat: StatP90, ITLStat: StatP99})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ds := r.CreateDataSet()
+			for _, dp := range ds.Data {
+				if dp.TTFTPercentiles == nil || dp.AvgTTFTTime != dp.TTFTPercentiles.P90 {
+					t.Errorf("TTFT %v is not the 90th percentile of %v", dp.AvgTTFTTime, dp.TTFTPercentiles)
+				}
+				if dp.ITLPercentiles == nil || dp.AvgITLTime != dp.ITLPercentiles.P99 {
+					t.Errorf("ITL %v is not the 99th percentile of %v", dp.AvgITLTime, dp.ITLPercentiles)
+				}
+			}
+			tags := ds.Metadata.Tags
+			if tags["ttftStat"] != "p90" || tags["itlStat"] != "p99" || tags["itlMetric"] != "itl" || tags["tokensStat"] != "mean" {
+				t.Errorf("unexpected metadata tags %v", tags)
+			}
+		})
+	}
+}
+
+func TestGuideLLMData_Statistics(t *testing.T) {
+	g := NewGuideLLMData()
+	if err := g.ReadFrom(readTestFile(t, "../../samples/guidellm.json")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metrics := g.Benchmarks[0].Metrics
+
+	tests := []struct {
+		name       string
+		opts       *Options
+		validateFn func(t *testing.T, dp *core.DataPoint)
+	}{
+		{
+			name: "default",
+			opts: &Options{},
+			validateFn: func(t *testing.T, dp *core.DataPoint) {
+				if dp.AvgTTFTTime != metrics.TTFT.Successful.Median || dp.AvgITLTime != metrics.ITL.Successful.Mean ||
+					dp.InputTokens != metrics.InputTokens.Successful.Mean {
+					t.Errorf("unexpected default statistics in %+v", dp)
+				}
+			},
+		},
+		{
+			name: "mean TTFT and median tokens",
+			opts: &Options{TTFTStat: StatMean, TokensStat: StatMedian},
+			validateFn: func(t *testing.T, dp *core.DataPoint) {
+				if dp.AvgTTFTTime != metrics.TTFT.Successful.Mean {
+					t.Errorf("TTFT %v, want mean %v", dp.AvgTTFTTime, metrics.TTFT.Successful.Mean)
+				}
+				if dp.InputTokens != metrics.InputTokens.Successful.Median || dp.OutputTokens != metrics.OutputTokens.Successful.Median {
+					t.Errorf("token counts %v, %v not medians", dp.InputTokens, dp.OutputTokens)
+				}
+			},
+		},
+		{
+			name: "TPOT as inter-token latency",
+			opts: &Options{ITLMetric: MetricTPOT, TPOTStat: StatMedian},
+			validateFn: func(t *testing.T, dp *core.DataPoint) {
+				if dp.AvgITLTime != metrics.TPOT.Successful.Median {
+					t.Errorf("ITL %v, want TPOT median %v", dp.AvgITLTime, metrics.TPOT.Successful.Median)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.SetOptions(tt.opts)
+			tt.validateFn(t, &g.CreateDataSet().Data[0])
+		})
+	}
+}
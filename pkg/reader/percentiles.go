@@ -0,0 +1,69 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// list of percentile values in GuideLLM CSV results, at levels [min, 0.1, 1, 5, 10, 25, 75, 90, 95, 99, max];
+// given either as a json array or as a string holding the array
+type PercentileList []float64
+
+// number of values and indexes of percentiles in a percentile list
+const (
+	percentileListLen     = 11
+	percentileListIndex90 = 7
+	percentileListIndex95 = 8
+	percentileListIndex99 = 9
+)
+
+func (l *PercentileList) UnmarshalJSON(data []byte) error {
+	var values []float64
+	if err := json.Unmarshal(data, &values); err == nil {
+		*l = values
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("invalid percentile list: %s", string(data))
+	}
+	if str = strings.TrimSpace(str); str == "" {
+		*l = nil
+		return nil
+	}
+	if err := json.Unmarshal([]byte(str), &values); err != nil {
+		return fmt.Errorf("invalid percentile list %q: %w", str, err)
+	}
+	*l = values
+	return nil
+}
+
+// get the percentiles in the list, together with the given median; nil if unavailable
+func (l PercentileList) Percentiles(median float64) *config.Percentiles {
+	if len(l) != percentileListLen {
+		return nil
+	}
+	return &config.Percentiles{
+		P50: median,
+		P90: l[percentileListIndex90],
+		P95: l[percentileListIndex95],
+		P99: l[percentileListIndex99],
+	}
+}
+
+// get the percentiles in a map keyed by percentile name (e.g. p50, p90); nil if unavailable
+func percentilesFromMap(m map[string]float64) *config.Percentiles {
+	p := &config.Percentiles{
+		P50: m["p50"],
+		P90: m["p90"],
+		P95: m["p95"],
+		P99: m["p99"],
+	}
+	if *p == (config.Percentiles{}) {
+		return nil
+	}
+	return p
+}
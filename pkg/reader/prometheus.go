@@ -0,0 +1,389 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// saved scrapes of the Prometheus metrics of a vLLM server (text format), diffed into data points
+type PrometheusData struct {
+	Scrapes []*PromScrape
+	readerOptions
+}
+
+// a scrape of Prometheus metrics: the sum of the samples of each metric over all label sets
+type PromScrape struct {
+	Time    float64                        `json:"time"`  // unix time of the scrape (sec)
+	Sums    map[string]float64             `json:"sums"`  // sums of counters, gauges, and histogram sums and counts, by metric name
+	Buckets map[string]map[float64]float64 `json:"-"`     // cumulative counts of histogram buckets, by metric name and upper bound
+	Model   string                         `json:"model"` // name of the served model, if labeled
+}
+
+// comment line starting a scrape in a file holding several, followed by unix seconds or RFC 3339 time
+const promScrapeTimeComment = "# scrape_time"
+
+// names of vLLM metrics
+const (
+	promRequestSuccess   = "vllm:request_success_total"
+	promTTFT             = "vllm:time_to_first_token_seconds"
+	promITL              = "vllm:inter_token_latency_seconds"   // recent vLLM versions
+	promTimePerOutput    = "vllm:time_per_output_token_seconds" // inter-token latency in older vLLM versions
+	promRequestTPOT      = "vllm:request_time_per_output_token_seconds"
+	promPromptTokens     = "vllm:request_prompt_tokens"
+	promGenerationTokens = "vllm:request_generation_tokens"
+	promModelNameLabel   = "model_name"
+	promBucketUpperBound = "le"
+)
+
+func NewPrometheusData() *PrometheusData {
+	return &PrometheusData{}
+}
+
+// read scrapes in text format; several scrapes in the data each start with a comment line
+// "# scrape_time <time>", otherwise the time of the single scrape is the latest sample timestamp
+func (p *PrometheusData) ReadFrom(dataBytes []byte) error {
+	scrapes, err := parsePromScrapes(dataBytes)
+	if err != nil {
+		return err
+	}
+	if err := checkScrapes(scrapes); err != nil {
+		return err
+	}
+	p.Scrapes = scrapes
+	return nil
+}
+
+// ReadFiles reads one or more scrapes per file, a scrape without time taking the modification time of its file;
+// scrapes are ordered by time
+func (p *PrometheusData) ReadFiles(paths ...string) error {
+	scrapes := []*PromScrape{}
+	for _, path := range paths {
+		dataBytes, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fileScrapes, err := parsePromScrapes(dataBytes)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, s := range fileScrapes {
+			if s.Time == 0 {
+				info, err := os.Stat(path)
+				if err != nil {
+					return err
+				}
+				s.Time = float64(info.ModTime().UnixNano()) / 1e9
+			}
+		}
+		scrapes = append(scrapes, fileScrapes...)
+	}
+	if err := checkScrapes(scrapes); err != nil {
+		return err
+	}
+	sort.SliceStable(scrapes, func(i, j int) bool { return scrapes[i].Time < scrapes[j].Time })
+	p.Scrapes = scrapes
+	return nil
+}
+
+// check that there are scrapes to diff into a data point
+func checkScrapes(scrapes []*PromScrape) error {
+	if len(scrapes) < 2 {
+		return fmt.Errorf("needs at least two scrapes, got %d", len(scrapes))
+	}
+	return nil
+}
+
+// parse scrapes in text format, with times from scrape_time comments or sample timestamps
+func parsePromScrapes(dataBytes []byte) ([]*PromScrape, error) {
+	scrapes := []*PromScrape{}
+	var current *PromScrape
+	latestTimestamp := 0.0
+	newScrape := func(t float64) {
+		current = &PromScrape{Time: t, Sums: map[string]float64{}, Buckets: map[string]map[float64]float64{}}
+		scrapes = append(scrapes, current)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(dataBytes))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, promScrapeTimeComment) {
+			t, err := parseScrapeTime(strings.TrimSpace(strings.TrimPrefix(text, promScrapeTimeComment)))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			newScrape(t)
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, labels, value, timestamp, err := parsePromSample(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if current == nil {
+			newScrape(0)
+		}
+		current.add(name, labels, value)
+		if current.Model == "" {
+			current.Model = labels[promModelNameLabel]
+		}
+		latestTimestamp = math.Max(latestTimestamp, timestamp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(scrapes) == 0 {
+		return nil, fmt.Errorf("no metrics in scrape")
+	}
+	if len(scrapes) == 1 && scrapes[0].Time == 0 {
+		scrapes[0].Time = latestTimestamp / 1000
+	}
+	for i, s := range scrapes {
+		if _, ok := s.Sums[promTTFT+"_count"]; !ok {
+			return nil, fmt.Errorf("scrape %d: no %s metric", i+1, promTTFT)
+		}
+	}
+	return scrapes, nil
+}
+
+// parse the time of a scrape, as unix seconds or RFC 3339
+func parseScrapeTime(s string) (float64, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		return t, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scrape time %q, expected unix seconds or RFC 3339", s)
+	}
+	return float64(parsed.UnixNano()) / 1e9, nil
+}
+
+// parse a sample line: name{label="value",...} value [timestamp in msec]
+func parsePromSample(text string) (name string, labels map[string]string, value float64, timestamp float64, err error) {
+	labels = map[string]string{}
+	i := strings.IndexAny(text, "{ \t")
+	if i <= 0 {
+		return "", nil, 0, 0, fmt.Errorf("invalid sample %q", text)
+	}
+	name, rest := text[:i], text[i:]
+	if strings.HasPrefix(rest, "{") {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " ,")
+			if strings.HasPrefix(rest, "}") {
+				rest = rest[1:]
+				break
+			}
+			key, after, found := strings.Cut(rest, "=")
+			if !found || !strings.HasPrefix(after, `"`) {
+				return "", nil, 0, 0, fmt.Errorf("invalid labels in %q", text)
+			}
+			quoted, remaining, err := cutQuoted(after)
+			if err != nil {
+				return "", nil, 0, 0, fmt.Errorf("invalid labels in %q", text)
+			}
+			labels[strings.TrimSpace(key)] = quoted
+			rest = remaining
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", nil, 0, 0, fmt.Errorf("invalid sample %q", text)
+	}
+	if value, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return "", nil, 0, 0, fmt.Errorf("invalid value in %q", text)
+	}
+	if len(fields) == 2 {
+		if timestamp, err = strconv.ParseFloat(fields[1], 64); err != nil {
+			return "", nil, 0, 0, fmt.Errorf("invalid timestamp in %q", text)
+		}
+	}
+	return name, labels, value, timestamp, nil
+}
+
+// cut a quoted string with escapes at the start of s, returning its value and the rest of s
+func cutQuoted(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte(s[i])
+				}
+			}
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// add a sample to the sums of its metric, or to the counts of its histogram bucket
+func (s *PromScrape) add(name string, labels map[string]string, value float64) {
+	if metric, ok := strings.CutSuffix(name, "_bucket"); ok {
+		le, err := strconv.ParseFloat(labels[promBucketUpperBound], 64)
+		if err != nil {
+			return
+		}
+		if s.Buckets[metric] == nil {
+			s.Buckets[metric] = map[float64]float64{}
+		}
+		s.Buckets[metric][le] += value
+		return
+	}
+	s.Sums[name] += value
+}
+
+// get the statistics of a histogram over the period between two scrapes, false if no observations
+func histogramStats(prev, next *PromScrape, metric string, scale float64) (metricStats, float64, bool) {
+	count := next.Sums[metric+"_count"] - prev.Sums[metric+"_count"]
+	sum := next.Sums[metric+"_sum"] - prev.Sums[metric+"_sum"]
+	if count <= 0 || sum < 0 {
+		return metricStats{}, 0, false
+	}
+
+	bounds := []float64{}
+	for le := range next.Buckets[metric] {
+		bounds = append(bounds, le)
+	}
+	sort.Float64s(bounds)
+	counts := make([]float64, len(bounds))
+	for i, le := range bounds {
+		counts[i] = next.Buckets[metric][le] - prev.Buckets[metric][le]
+	}
+	quantile := func(q float64) float64 {
+		return bucketQuantile(q, bounds, counts) * scale
+	}
+	// without buckets, the mean stands in for the median
+	stats := metricStats{Mean: sum / count * scale, Median: sum / count * scale}
+	if len(bounds) > 0 {
+		stats.Median = quantile(0.5)
+		stats.Percentiles = &config.Percentiles{P50: stats.Median, P90: quantile(0.90), P95: quantile(0.95), P99: quantile(0.99)}
+	}
+	return stats, count, true
+}
+
+// estimate a quantile from cumulative bucket counts, interpolating linearly within a bucket
+// (as histogram_quantile in Prometheus)
+func bucketQuantile(q float64, bounds []float64, counts []float64) float64 {
+	total := counts[len(counts)-1]
+	if total <= 0 {
+		return 0
+	}
+	rank := q * total
+	for i, c := range counts {
+		if c < rank {
+			continue
+		}
+		if math.IsInf(bounds[i], 1) {
+			if i == 0 {
+				return 0
+			}
+			return bounds[i-1]
+		}
+		lower, below := 0.0, 0.0
+		if i > 0 {
+			lower, below = bounds[i-1], counts[i-1]
+		}
+		if c == below {
+			return bounds[i]
+		}
+		return lower + (bounds[i]-lower)*(rank-below)/(c-below)
+	}
+	return bounds[len(bounds)-1]
+}
+
+// aggregate the period between two scrapes into a data point, nil if no request completed
+func (p *PrometheusData) aggregate(prev, next *PromScrape) *core.DataPoint {
+	duration := next.Time - prev.Time
+	ttft, numRequests, ok := histogramStats(prev, next, promTTFT, 1000)
+	if duration <= 0 || !ok {
+		return nil
+	}
+	itl, _, ok := histogramStats(prev, next, promITL, 1000)
+	if !ok {
+		itl, _, _ = histogramStats(prev, next, promTimePerOutput, 1000)
+	}
+	tpot, _, _ := histogramStats(prev, next, promRequestTPOT, 1000)
+	inputTokens, _, _ := histogramStats(prev, next, promPromptTokens, 1)
+	outputTokens, numCompleted, ok := histogramStats(prev, next, promGenerationTokens, 1)
+	if succeeded := next.Sums[promRequestSuccess] - prev.Sums[promRequestSuccess]; succeeded > 0 {
+		numCompleted = succeeded
+	} else if !ok {
+		numCompleted = numRequests
+	}
+
+	ttftValue, itlValue, input, output := p.values(&benchmarkStats{
+		TTFT:         ttft,
+		ITL:          itl,
+		TPOT:         tpot,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+	return &core.DataPoint{
+		RequestRate:     numCompleted / duration,
+		InputTokens:     input,
+		OutputTokens:    output,
+		AvgTTFTTime:     ttftValue,
+		AvgITLTime:      itlValue,
+		TTFTPercentiles: ttft.Percentiles,
+		ITLPercentiles:  itl.Percentiles,
+		MaxBatchSize:    config.DefaultMaxBatchSize,
+		MaxNumTokens:    config.DefaultMaxNumTokens,
+		Labels: &core.Labels{
+			BenchmarkID: fmt.Sprintf("scrapes@%s", time.Unix(0, int64(prev.Time*1e9)).UTC().Format(time.RFC3339)),
+			Model:       next.Model,
+		},
+	}
+}
+
+// create a data set object with a data point per period between consecutive scrapes,
+// skipping periods without requests or with counter resets, selected by the reader options
+func (p *PrometheusData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
+	for i := 1; i < len(p.Scrapes); i++ {
+		if dp := p.aggregate(p.Scrapes[i-1], p.Scrapes[i]); dp != nil {
+			points = append(points, dp)
+		}
+	}
+	return p.createDataSet("Prometheus metrics data", points)
+}
+
+func (p *PrometheusData) Print() {
+	for i, s := range p.Scrapes {
+		fmt.Printf("Scrape %d: %s\n", i+1, time.Unix(0, int64(s.Time*1e9)).UTC().Format(time.RFC3339))
+		fmt.Printf("  Model: %s\n", s.Model)
+		fmt.Printf("  Requests: %.0f\n", s.Sums[promTTFT+"_count"])
+		fmt.Printf("  Tokens: Prompt=%.0f, Generation=%.0f\n", s.Sums[promPromptTokens+"_sum"], s.Sums[promGenerationTokens+"_sum"])
+	}
+}
+
+func (p *PrometheusData) Dump() string {
+	scrapes := []map[string]any{}
+	for _, s := range p.Scrapes {
+		scrapes = append(scrapes, map[string]any{"time": s.Time, "model": s.Model, "sums": s.Sums})
+	}
+	if jsonStr, err := json.Marshal(scrapes); err == nil {
+		return fmt.Sprintf("Prometheus metrics data: %v\n", string(jsonStr))
+	}
+	return "Prometheus metrics data: <unavailable>"
+}
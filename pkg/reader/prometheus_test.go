@@ -0,0 +1,152 @@
+package reader
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, p *PrometheusData)
+	}{
+		{
+			name: "consecutive scrapes",
+			data: readTestFile(t, "testdata/vllm-metrics.prom"),
+			validateFn: func(t *testing.T, p *PrometheusData) {
+				if len(p.Scrapes) != 3 {
+					t.Fatalf("got %d scrapes, want 3", len(p.Scrapes))
+				}
+				ds := p.CreateDataSet()
+				if ds.Size() != 2 {
+					t.Fatalf("got %d points, want 2", ds.Size())
+				}
+				// 120 requests over 60 sec, summed over finish reasons
+				dp := ds.Data[0]
+				if math.Abs(dp.RequestRate-2) > 1e-9 || dp.InputTokens != 200 || dp.OutputTokens != 101 {
+					t.Errorf("unexpected rate or token counts %+v", dp)
+				}
+				// median TTFT interpolated in the bucket (40, 60] msec
+				if math.Abs(dp.AvgTTFTTime-50) > 1e-6 || math.Abs(dp.AvgITLTime-10) > 1e-6 {
+					t.Errorf("unexpected latencies %+v", dp)
+				}
+				if dp.Labels.Model != "Qwen/Qwen2.5-0.5B" || dp.Labels.BenchmarkID != "scrapes@1970-01-01T00:16:40Z" {
+					t.Errorf("unexpected labels %+v", dp.Labels)
+				}
+				dp = ds.Data[1]
+				if math.Abs(dp.RequestRate-4) > 1e-9 || math.Abs(dp.AvgTTFTTime-220.0/3) > 1e-6 || math.Abs(dp.AvgITLTime-20) > 1e-6 {
+					t.Errorf("unexpected point %+v", dp)
+				}
+				p.SetOptions(&Options{TTFTStat: StatMean})
+				if dp := p.CreateDataSet().Data[1]; math.Abs(dp.AvgTTFTTime-60) > 1e-6 {
+					t.Errorf("got mean TTFT %v, want 60", dp.AvgTTFTTime)
+				}
+			},
+		},
+		{
+			name:        "single scrape",
+			data:        []byte("vllm:time_to_first_token_seconds_sum 1\nvllm:time_to_first_token_seconds_count 10\n"),
+			expectError: "needs at least two scrapes, got 1",
+		},
+		{
+			name: "counter reset",
+			data: []byte(`# scrape_time 2025-06-01T10:00:00Z
+vllm:time_to_first_token_seconds_sum 10
+vllm:time_to_first_token_seconds_count 100
+# scrape_time 2025-06-01T10:01:00Z
+vllm:time_to_first_token_seconds_sum 1
+vllm:time_to_first_token_seconds_count 20
+`),
+			validateFn: func(t *testing.T, p *PrometheusData) {
+				if p.Scrapes[1].Time-p.Scrapes[0].Time != 60 {
+					t.Errorf("unexpected scrape times %v, %v", p.Scrapes[0].Time, p.Scrapes[1].Time)
+				}
+				if ds := p.CreateDataSet(); ds.Size() != 0 {
+					t.Errorf("got %d points across a restart", ds.Size())
+				}
+			},
+		},
+		{
+			name:        "invalid label",
+			data:        []byte(`vllm:time_to_first_token_seconds_count{model_name="m} 1`),
+			expectError: "line 1: invalid labels",
+		},
+		{
+			name:        "invalid scrape time",
+			data:        []byte("# scrape_time noon\n"),
+			expectError: "invalid scrape time",
+		},
+		{
+			name:        "not vllm metrics",
+			data:        []byte("process_cpu_seconds_total 12.5\n"),
+			expectError: "no vllm:time_to_first_token_seconds metric",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrometheusData()
+			err := p.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, p)
+		})
+	}
+}
+
+func TestParsePromScrapes(t *testing.T) {
+	scrapes, err := parsePromScrapes([]byte(`# TYPE vllm:time_to_first_token_seconds histogram
+vllm:time_to_first_token_seconds_sum{model_name="m",engine="0"} 1.5 1700000000000
+vllm:time_to_first_token_seconds_count{model_name="m",engine="0"} 30 1700000000000
+vllm:time_to_first_token_seconds_count{model_name="m",engine="1"} 10 1700000001000
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := scrapes[0]; len(scrapes) != 1 || s.Time != 1700000001 || s.Sums[promTTFT+"_count"] != 40 || s.Model != "m" {
+		t.Errorf("unexpected scrape with sample timestamps %+v", s)
+	}
+}
+
+func TestPrometheusData_ReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	scrapes := []string{
+		"vllm:time_to_first_token_seconds_sum 1\nvllm:time_to_first_token_seconds_count 10\n",
+		"vllm:time_to_first_token_seconds_sum 4\nvllm:time_to_first_token_seconds_count 40\n",
+	}
+	paths := []string{}
+	start := time.Unix(1700000000, 0)
+	for i, scrape := range scrapes {
+		path := filepath.Join(dir, []string{"b.prom", "a.prom"}[i])
+		if err := os.WriteFile(path, []byte(scrape), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := start.Add(time.Duration(i*30) * time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	p := NewPrometheusData()
+	if err := p.ReadFiles(paths[1], paths[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds := p.CreateDataSet()
+	if ds.Size() != 1 || math.Abs(ds.Data[0].RequestRate-1) > 1e-9 || math.Abs(ds.Data[0].AvgTTFTTime-100) > 1e-6 {
+		t.Errorf("scrapes not ordered by modification time: %+v", ds.Data)
+	}
+}
@@ -1,6 +1,10 @@
 package reader
 
-import "github.com/llm-inferno/model-trainer/pkg/core"
+import (
+	"io"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
 
 // Converts from a given data format to the Data Set format
 type Reader interface {
@@ -9,3 +13,18 @@ type Reader interface {
 	Print()
 	Dump() string
 }
+
+// readers reading their input from a stream, without holding it whole in memory
+type StreamReader interface {
+	ReadFromStream(in io.Reader) error
+}
+
+// readers reporting data missing in their input, replaced by guesses
+type Warner interface {
+	Warnings() []string
+}
+
+// readers leaving benchmarks out of their data sets, as selected by their options
+type Skipper interface {
+	NumSkipped() int
+}
@@ -0,0 +1,430 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// an input format, with a function to recognize it and a constructor of its reader
+type Format struct {
+	Name       string   // unique name of the format
+	Extensions []string // file extensions of the format, including the dot (e.g. ".csv")
+
+	// check whether data (from a file with the given name, possibly empty) is in the format;
+	// nil if it is, otherwise an error explaining why not
+	Sniff func(dataBytes []byte, filename string) error
+
+	// check whether the start of data too long to be sniffed whole is in the format, as Sniff does;
+	// nil if the format is recognized from whole data only. Streams are read by the reader of the
+	// first format recognizing their start, if it is a StreamReader (see DetectReader).
+	SniffPrefix func(prefix []byte, filename string) error
+
+	New func() Reader
+}
+
+// registered formats, in order of detection
+var (
+	registryMutex sync.RWMutex
+	formats       = []*Format{}
+)
+
+// Register adds an input format to the registry, tried by Detect after the formats registered before.
+// It panics if the format is incomplete or its name is already registered.
+func Register(format *Format) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if format == nil || format.Name == "" || format.Sniff == nil || format.New == nil {
+		panic("reader: Register of an incomplete format")
+	}
+	for _, f := range formats {
+		if f.Name == format.Name {
+			panic("reader: Register called twice for format " + format.Name)
+		}
+	}
+	formats = append(formats, format)
+}
+
+// get the names of the registered formats, in order of detection
+func Formats() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// get a registered format by name
+func Lookup(name string) (*Format, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// an attempt to read data in a format
+type DetectAttempt struct {
+	Format string `json:"format"`
+	Reason string `json:"reason"` // why the format did not match, or could not be read
+}
+
+// error of format detection, listing the formats tried
+type DetectError struct {
+	Filename string
+	Attempts []DetectAttempt
+}
+
+func (e *DetectError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unrecognized input format of %s", e.name())
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "\n  %s: %s", a.Format, a.Reason)
+	}
+	return b.String()
+}
+
+// name of the data in messages
+func (e *DetectError) name() string {
+	if e.Filename == "" {
+		return "data"
+	}
+	return e.Filename
+}
+
+// Detect finds the format of data, from a file with the given name (possibly empty),
+// and returns a reader that has read it. Formats are tried in order of registration: a
+// format is skipped if the file extension is claimed by other formats only, or if its sniff
+// function does not recognize the data; the first format reading the data into a non-empty
+// data set is returned. Data with an extension of a single format, recognized by it but
+// not read, gets the error of its reader rather than a DetectError.
+func Detect(dataBytes []byte, filename string) (Reader, error) {
+	return DetectWith(dataBytes, filename, nil)
+}
+
+// DetectWith is Detect with options set on configurable readers (default options if nil)
+func DetectWith(dataBytes []byte, filename string, opts *Options) (Reader, error) {
+	_, r, err := detect(dataBytes, filename, opts)
+	return r, err
+}
+
+// detect the format of data, returning it with a reader that has read the data
+func detect(dataBytes []byte, filename string, opts *Options) (*Format, Reader, error) {
+	candidates, ext, numOwners := candidateFormats(filename)
+	knownExt := numOwners > 0
+
+	detectErr := &DetectError{Filename: filename}
+	fail := func(f *Format, reason string) {
+		detectErr.Attempts = append(detectErr.Attempts, DetectAttempt{Format: f.Name, Reason: reason})
+	}
+	for _, f := range candidates {
+		if knownExt && !hasExtension(f, ext) {
+			fail(f, fmt.Sprintf("extension %s not one of %v", ext, f.Extensions))
+			continue
+		}
+		if err := f.Sniff(dataBytes, filename); err != nil {
+			fail(f, err.Error())
+			continue
+		}
+		r := newReader(f, opts)
+		if err := r.ReadFrom(dataBytes); err != nil {
+			if numOwners == 1 {
+				// recognized in the only format of its extension, so why it cannot be read tells more
+				return nil, nil, fmt.Errorf("%s data of %s: %w", f.Name, detectErr.name(), err)
+			}
+			fail(f, err.Error())
+			continue
+		}
+		if r.CreateDataSet().Size() == 0 {
+			fail(f, "no data points")
+			continue
+		}
+		return f, r, nil
+	}
+	return nil, nil, detectErr
+}
+
+// detect the format of a stream: data longer than streamSniffLength is read by the reader of the format
+// recognizing its start if that reader reads streams and no format tried before needs whole data, and
+// is read whole and detected otherwise
+func detectStream(in *bufio.Reader, filename string, opts *Options) (*Format, Reader, error) {
+	prefix, err := in.Peek(streamSniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	if err == nil {
+		if f := detectPrefix(prefix, filename); f != nil {
+			name := (&DetectError{Filename: filename}).name()
+			r := newReader(f, opts)
+			if err := r.(StreamReader).ReadFromStream(in); err != nil {
+				return nil, nil, fmt.Errorf("%s data of %s: %w", f.Name, name, err)
+			}
+			if r.CreateDataSet().Size() == 0 {
+				return nil, nil, fmt.Errorf("%s data of %s: no data points", f.Name, name)
+			}
+			return f, r, nil
+		}
+	}
+	dataBytes, err := io.ReadAll(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	return detect(dataBytes, filename, opts)
+}
+
+// find the format recognizing the start of data, nil if none does, if it needs the whole data, or
+// if its reader does not read streams
+func detectPrefix(prefix []byte, filename string) *Format {
+	candidates, ext, numOwners := candidateFormats(filename)
+	for _, f := range candidates {
+		if numOwners > 0 && !hasExtension(f, ext) {
+			continue
+		}
+		if f.SniffPrefix == nil {
+			return nil
+		}
+		if f.SniffPrefix(prefix, filename) != nil {
+			continue
+		}
+		if _, ok := f.New().(StreamReader); ok {
+			return f
+		}
+		return nil
+	}
+	return nil
+}
+
+// get the registered formats, with the extension of a file name and the number of formats having it
+func candidateFormats(filename string) ([]*Format, string, int) {
+	registryMutex.RLock()
+	candidates := append([]*Format{}, formats...)
+	registryMutex.RUnlock()
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	numOwners := 0
+	for _, f := range candidates {
+		if hasExtension(f, ext) {
+			numOwners++
+		}
+	}
+	return candidates, ext, numOwners
+}
+
+// create a reader of a format, with options set if configurable (default options if nil)
+func newReader(f *Format, opts *Options) Reader {
+	r := f.New()
+	if c, ok := r.(Configurable); ok && opts != nil {
+		c.SetOptions(opts)
+	}
+	return r
+}
+
+func hasExtension(f *Format, ext string) bool {
+	for _, e := range f.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniff json data holding an object with a given key
+func sniffJSONObject(key string) func(dataBytes []byte, filename string) error {
+	return func(dataBytes []byte, filename string) error {
+		if !isJSONData(dataBytes) {
+			return fmt.Errorf("not json")
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(dataBytes, &object); err != nil {
+			return fmt.Errorf("not a json object")
+		}
+		if _, ok := object[key]; !ok {
+			return fmt.Errorf("no %q key", key)
+		}
+		return nil
+	}
+}
+
+// sniff the start of a json object holding a given key among its keys before the end of the data
+func sniffJSONObjectPrefix(key string) func(prefix []byte, filename string) error {
+	return func(prefix []byte, filename string) error {
+		if !isJSONData(prefix) {
+			return fmt.Errorf("not json")
+		}
+		decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(bytes.TrimSpace(prefix), []byte("\xef\xbb\xbf"))))
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return fmt.Errorf("not a json object")
+		}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			if token == key {
+				return nil
+			}
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				break
+			}
+		}
+		return fmt.Errorf("no %q key", key)
+	}
+}
+
+// sniff the start of an html document, or data containing a marker
+func sniffHTMLPrefix(marker string) func(prefix []byte, filename string) error {
+	return func(prefix []byte, filename string) error {
+		start := bytes.ToLower(bytes.TrimSpace(bytes.TrimPrefix(prefix, []byte("\xef\xbb\xbf"))))
+		if bytes.HasPrefix(start, []byte("<!doctype html")) || bytes.HasPrefix(start, []byte("<html")) {
+			return nil
+		}
+		return sniffMarker(marker)(prefix, filename)
+	}
+}
+
+// sniff CSV data, or a json array of rows, holding the required columns
+func sniffCSV(required []string, maxHeaderRows int) func(dataBytes []byte, filename string) error {
+	return func(dataBytes []byte, filename string) error {
+		if isJSONData(dataBytes) {
+			var rows []map[string]json.RawMessage
+			if err := json.Unmarshal(dataBytes, &rows); err != nil || len(rows) == 0 {
+				return fmt.Errorf("not a json array of rows")
+			}
+			header := []string{}
+			for key := range rows[0] {
+				header = append(header, key)
+			}
+			if missing := missingColumns(header, required); len(missing) > 0 {
+				return fmt.Errorf("missing columns %q", missing)
+			}
+			return nil
+		}
+		r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(dataBytes, []byte("\xef\xbb\xbf"))))
+		r.FieldsPerRecord = -1
+		rows := [][]string{}
+		for len(rows) < maxHeaderRows {
+			row, err := r.Read()
+			if err != nil {
+				break
+			}
+			rows = append(rows, row)
+		}
+		if len(rows) == 0 {
+			return fmt.Errorf("not CSV")
+		}
+		var missing []string
+		for k := 1; k <= len(rows); k++ {
+			if m := missingColumns(joinHeaderRows(rows[:k]), required); missing == nil || len(m) < len(missing) {
+				missing = m
+			}
+		}
+		if len(missing) == len(required) {
+			return fmt.Errorf("no %q column", required[0])
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing columns %q", missing)
+		}
+		return nil
+	}
+}
+
+// sniff data containing a marker
+func sniffMarker(marker string) func(dataBytes []byte, filename string) error {
+	return func(dataBytes []byte, filename string) error {
+		if !bytes.Contains(dataBytes, []byte(marker)) {
+			return fmt.Errorf("no %s", marker)
+		}
+		return nil
+	}
+}
+
+// built-in formats, most specific first
+func init() {
+	Register(&Format{
+		Name:        "dataset",
+		Extensions:  []string{".json"},
+		Sniff:       sniffJSONObject("data"),
+		SniffPrefix: sniffJSONObjectPrefix("data"),
+		New:         func() Reader { return NewDataSetData() },
+	})
+	Register(&Format{
+		Name:        "dataset-csv",
+		Extensions:  []string{".csv"},
+		Sniff:       sniffDataSetCSV,
+		SniffPrefix: sniffDataSetCSV,
+		New:         func() Reader { return NewDataSetCSVData() },
+	})
+	Register(&Format{
+		Name:        "guidellm-json",
+		Extensions:  []string{".json"},
+		Sniff:       sniffJSONObject("benchmarks"),
+		SniffPrefix: sniffJSONObjectPrefix("benchmarks"),
+		New:         func() Reader { return NewGuideLLMData() },
+	})
+	Register(&Format{
+		Name:        "guidellm-csv",
+		Extensions:  []string{".csv", ".json"},
+		Sniff:       sniffCSV(csvRequiredColumns, 1),
+		SniffPrefix: sniffCSV(csvRequiredColumns, 1),
+		New:         func() Reader { return NewGuideLLMCSVData() },
+	})
+	Register(&Format{
+		Name:        "guidellm-csv2",
+		Extensions:  []string{".csv", ".json"},
+		Sniff:       sniffCSV(csv2RequiredColumns, csvMaxHeaderRows),
+		SniffPrefix: sniffCSV(csv2RequiredColumns, csvMaxHeaderRows),
+		New:         func() Reader { return NewGuideLLMCSV2Data() },
+	})
+	Register(&Format{
+		Name:        "guidellm-html",
+		Extensions:  []string{".html", ".htm"},
+		Sniff:       sniffMarker("window.benchmarks"),
+		SniffPrefix: sniffHTMLPrefix("window.benchmarks"),
+		New:         func() Reader { return NewGuideLLMHTMLData() },
+	})
+	Register(&Format{
+		Name:        "vllm-bench",
+		Extensions:  []string{".json", ".jsonl"},
+		Sniff:       sniffVLLMBench,
+		SniffPrefix: sniffVLLMBench,
+		New:         func() Reader { return NewVLLMBenchData() },
+	})
+	Register(&Format{
+		Name:        "request-trace",
+		Extensions:  []string{".jsonl"},
+		Sniff:       sniffTrace,
+		SniffPrefix: sniffTrace,
+		New:         func() Reader { return NewTraceData() },
+	})
+	Register(&Format{
+		Name:       "vllm-metrics",
+		Extensions: []string{".prom", ".metrics"},
+		Sniff:      sniffMarker(promTTFT + "_count"),
+		New:        func() Reader { return NewPrometheusData() },
+	})
+	Register(&Format{
+		Name:       "genai-perf",
+		Extensions: []string{".json", ".csv"},
+		Sniff:      sniffGenAIPerf,
+		New:        func() Reader { return NewGenAIPerfData() },
+	})
+	Register(&Format{
+		Name:       "llmperf",
+		Extensions: []string{".json"},
+		Sniff:      sniffJSONObject("results_ttft_s_mean"),
+		New:        func() Reader { return NewLLMPerfData() },
+	})
+}
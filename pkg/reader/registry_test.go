@@ -0,0 +1,150 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		data        []byte
+		filename    string
+		wantType    Reader
+		expectError []string
+	}{
+		{name: "native data set", file: "../../samples/data.json", wantType: &DataSetData{}},
+		{name: "native data set csv", file: "testdata/dataset.csv", wantType: &DataSetCSVData{}},
+		{name: "guidellm json", file: "../../samples/guidellm.json", wantType: &GuideLLMData{}},
+		{name: "guidellm csv as json rows", file: "../../samples/guidellm-csv.json", wantType: &GuideLLMCSVData{}},
+		{name: "guidellm csv", file: "testdata/guidellm.csv", wantType: &GuideLLMCSVData{}},
+		{name: "guidellm csv v2", file: "testdata/guidellm-v2.csv", wantType: &GuideLLMCSV2Data{}},
+		{name: "guidellm html", file: "../../samples/benchmarks.html", wantType: &GuideLLMHTMLData{}},
+		{name: "vllm benchmark_serving", file: "testdata/vllm-bench/vllm-qps-2.json", wantType: &VLLMBenchData{}},
+		{name: "vllm benchmark_serving appended", file: "testdata/vllm-bench.jsonl", wantType: &VLLMBenchData{}},
+		{name: "request trace", file: "testdata/trace.jsonl", wantType: &TraceData{}},
+		{name: "vllm metrics scrapes", file: "testdata/vllm-metrics.prom", wantType: &PrometheusData{}},
+		{name: "genai-perf json", file: "testdata/genai-perf/qwen-openai-chat-concurrency4/profile_export_genai_perf.json", wantType: &GenAIPerfData{}},
+		{name: "genai-perf csv", file: "testdata/genai-perf/qwen-openai-chat-concurrency1/profile_export_genai_perf.csv", wantType: &GenAIPerfData{}},
+		{name: "llmperf summary", file: "testdata/llmperf/Qwen-Qwen2-5-0-5B_512_128_c4_summary.json", wantType: &LLMPerfData{}},
+		{name: "csv without file name", file: "testdata/guidellm-v2.csv", filename: "-", wantType: &GuideLLMCSV2Data{}},
+		{
+			name:        "unknown content",
+			data:        []byte("hello, world\n"),
+			filename:    "notes.txt",
+			expectError: []string{"notes.txt", "dataset: not json", "guidellm-html: no window.benchmarks"},
+		},
+		{
+			name:        "extension claimed by other formats",
+			file:        "../../samples/guidellm.json",
+			filename:    "results.html",
+			expectError: []string{"guidellm-json: extension .html not one of"},
+		},
+		{
+			name:        "recognized but empty",
+			data:        []byte(`{"benchmarks": []}`),
+			expectError: []string{"guidellm-json: no data points"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, filename := tt.data, tt.filename
+			if tt.file != "" {
+				data = readTestFile(t, tt.file)
+				if filename == "" {
+					filename = tt.file
+				}
+			}
+			if filename == "-" {
+				filename = ""
+			}
+			r, err := Detect(data, filename)
+			if len(tt.expectError) > 0 {
+				var detectErr *DetectError
+				if !errors.As(err, &detectErr) {
+					t.Fatalf("expected DetectError, got %v", err)
+				}
+				for _, s := range tt.expectError {
+					if !strings.Contains(err.Error(), s) {
+						t.Errorf("error %q does not contain %q", err.Error(), s)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := fmt.Sprintf("%T", r), fmt.Sprintf("%T", tt.wantType); got != want {
+				t.Errorf("detected %s, want %s", got, want)
+			}
+			if r.CreateDataSet().Size() == 0 {
+				t.Error("empty data set")
+			}
+		})
+	}
+}
+
+// reader of a third-party format: one data point per line holding a request rate
+type mockLineReader struct {
+	lines []string
+}
+
+func (m *mockLineReader) ReadFrom(dataBytes []byte) error {
+	m.lines = strings.Fields(strings.TrimPrefix(string(dataBytes), "#mock"))
+	return nil
+}
+
+func (m *mockLineReader) CreateDataSet() *core.DataSet {
+	ds := core.NewDataSet("mock")
+	for range m.lines {
+		ds.AppendDataPoint(&core.DataPoint{RequestRate: 1})
+	}
+	return ds
+}
+
+func (m *mockLineReader) Print()       {}
+func (m *mockLineReader) Dump() string { return "" }
+
+func TestRegister(t *testing.T) {
+	Register(&Format{
+		Name:       "mock-lines",
+		Extensions: []string{".mock"},
+		Sniff:      sniffMarker("#mock"),
+		New:        func() Reader { return &mockLineReader{} },
+	})
+	if _, ok := Lookup("mock-lines"); !ok {
+		t.Fatal("registered format not found")
+	}
+	names := Formats()
+	if names[len(names)-1] != "mock-lines" {
+		t.Errorf("registered format not last in %v", names)
+	}
+
+	r, err := Detect([]byte("#mock\n1\n2\n"), "rates.mock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := r.(*mockLineReader); !ok || r.CreateDataSet().Size() != 2 {
+		t.Errorf("unexpected reader %T", r)
+	}
+
+	for _, f := range []*Format{
+		{Name: "mock-lines", Sniff: sniffMarker("x"), New: func() Reader { return &mockLineReader{} }},
+		{Name: "incomplete"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%s) did not panic", f.Name)
+				}
+			}()
+			Register(f)
+		}()
+	}
+}
@@ -0,0 +1,223 @@
+package reader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// configuration of the inference server of benchmarks (zero fields are unknown)
+type ServerConfig struct {
+	MaxBatchSize int `json:"maxBatchSize,omitempty"` // maximum number of sequences in a batch (vLLM --max-num-seqs)
+	MaxNumTokens int `json:"maxNumTokens,omitempty"` // maximum number of tokens in a batch (vLLM --max-num-batched-tokens)
+}
+
+// names of companion files of benchmark files, in the directory of a sweep
+const (
+	ServerConfigFileName = "server.json"   // server configuration overriding all other sources
+	VLLMArgsFileName     = "vllm-args.txt" // vLLM launch arguments
+)
+
+// names of vLLM arguments, and of the corresponding metadata keys (with underscores)
+const (
+	vllmMaxNumSeqs           = "max-num-seqs"
+	vllmMaxNumBatchedTokens  = "max-num-batched-tokens"
+	metadataMaxNumSeqs       = "max_num_seqs"
+	metadataMaxBatchedTokens = "max_num_batched_tokens"
+)
+
+// check if no field is set
+func (s *ServerConfig) IsEmpty() bool {
+	return s == nil || (s.MaxBatchSize == 0 && s.MaxNumTokens == 0)
+}
+
+// get a copy of the configuration with the set fields of another configuration overriding them
+func (s *ServerConfig) Overlay(other *ServerConfig) *ServerConfig {
+	result := &ServerConfig{}
+	if s != nil {
+		*result = *s
+	}
+	if other != nil {
+		if other.MaxBatchSize > 0 {
+			result.MaxBatchSize = other.MaxBatchSize
+		}
+		if other.MaxNumTokens > 0 {
+			result.MaxNumTokens = other.MaxNumTokens
+		}
+	}
+	return result
+}
+
+// set the server configuration of a data point, keeping its values for unknown fields
+func (s *ServerConfig) apply(dp *core.DataPoint) {
+	if s == nil {
+		return
+	}
+	if s.MaxBatchSize > 0 {
+		dp.MaxBatchSize = s.MaxBatchSize
+	}
+	if s.MaxNumTokens > 0 {
+		dp.MaxNumTokens = s.MaxNumTokens
+	}
+}
+
+// get the server limits of a data point from its configuration, defaults for unknown fields
+func (s *ServerConfig) limits() (maxBatchSize, maxNumTokens int) {
+	dp := &core.DataPoint{MaxBatchSize: config.DefaultMaxBatchSize, MaxNumTokens: config.DefaultMaxNumTokens}
+	s.apply(dp)
+	return dp.MaxBatchSize, dp.MaxNumTokens
+}
+
+// ParseVLLMArgs gets the server configuration from vLLM launch arguments, either a command line
+// (--max-num-seqs 256 or --max-num-seqs=256, possibly split over lines) or a vLLM config file
+// (max-num-seqs: 256). Other arguments are ignored.
+func ParseVLLMArgs(text string) (*ServerConfig, error) {
+	text = strings.ReplaceAll(text, "\\\n", " ")
+	tokens := []string{}
+	for _, field := range strings.Fields(text) {
+		field = strings.Trim(field, `"',[]`)
+		name, value, found := strings.Cut(field, "=")
+		if !found && strings.HasSuffix(field, ":") {
+			name, value, found = strings.TrimSuffix(field, ":"), "", true
+		}
+		tokens = append(tokens, name)
+		if found && value != "" {
+			tokens = append(tokens, value)
+		}
+	}
+
+	server := &ServerConfig{}
+	for i, token := range tokens {
+		target := (*int)(nil)
+		switch strings.ReplaceAll(strings.TrimLeft(token, "-"), "_", "-") {
+		case vllmMaxNumSeqs:
+			target = &server.MaxBatchSize
+		case vllmMaxNumBatchedTokens:
+			target = &server.MaxNumTokens
+		default:
+			continue
+		}
+		if i+1 >= len(tokens) {
+			return nil, fmt.Errorf("missing value of vLLM argument %s", token)
+		}
+		v, err := strconv.Atoi(tokens[i+1])
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid value %q of vLLM argument %s", tokens[i+1], token)
+		}
+		*target = v
+	}
+	return server, nil
+}
+
+// find the server configuration in benchmark metadata (e.g. the backend section of GuideLLM results),
+// given as keys max_num_seqs and max_num_batched_tokens (or with dashes) at any depth, or as vLLM
+// launch arguments in strings or lists of strings; nil if not found
+func serverFromMetadata(metadata any) *ServerConfig {
+	server := &ServerConfig{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				switch strings.ReplaceAll(strings.ToLower(key), "-", "_") {
+				case metadataMaxNumSeqs:
+					if n, ok := positiveInt(value); ok {
+						server.MaxBatchSize = n
+					}
+				case metadataMaxBatchedTokens:
+					if n, ok := positiveInt(value); ok {
+						server.MaxNumTokens = n
+					}
+				default:
+					walk(value)
+				}
+			}
+		case []any:
+			args := []string{}
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					args = append(args, s)
+				} else {
+					walk(item)
+				}
+			}
+			walk(strings.Join(args, " "))
+		case string:
+			if strings.Contains(v, "--"+vllmMaxNumSeqs) || strings.Contains(v, "--"+vllmMaxNumBatchedTokens) {
+				if s, err := ParseVLLMArgs(v); err == nil {
+					*server = *server.Overlay(s)
+				}
+			}
+		}
+	}
+	walk(metadata)
+	if server.IsEmpty() {
+		return nil
+	}
+	return server
+}
+
+// find the server configuration in metadata given as a json string (e.g. a column of CSV results)
+func serverFromJSON(metadataJSON string) *ServerConfig {
+	var metadata any
+	if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+		return nil
+	}
+	return serverFromMetadata(metadata)
+}
+
+// get a positive integer from a json number or a string
+func positiveInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case float64:
+		return int(v), v >= 1 && v == float64(int(v))
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
+
+// LoadServerConfig reads the server configuration of the sweep of a benchmark file from companion
+// files in its directory: vLLM launch arguments (vllm-args.txt), overridden by a sidecar server.json
+// holding a ServerConfig; nil if neither exists
+func LoadServerConfig(path string) (*ServerConfig, error) {
+	dir := filepath.Dir(path)
+	var server *ServerConfig
+
+	argsPath := filepath.Join(dir, VLLMArgsFileName)
+	argsBytes, err := os.ReadFile(argsPath)
+	switch {
+	case err == nil:
+		if server, err = ParseVLLMArgs(string(argsBytes)); err != nil {
+			return nil, fmt.Errorf("%s: %w", argsPath, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	sidecarPath := filepath.Join(dir, ServerConfigFileName)
+	sidecarBytes, err := os.ReadFile(sidecarPath)
+	switch {
+	case err == nil:
+		sidecar := &ServerConfig{}
+		if err := json.Unmarshal(sidecarBytes, sidecar); err != nil {
+			return nil, fmt.Errorf("%s: %w", sidecarPath, err)
+		}
+		if sidecar.MaxBatchSize < 0 || sidecar.MaxNumTokens < 0 {
+			return nil, fmt.Errorf("%s: negative server limits", sidecarPath)
+		}
+		server = server.Overlay(sidecar)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+	return server, nil
+}
@@ -0,0 +1,133 @@
+package reader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+func TestParseVLLMArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		want        ServerConfig
+		expectError bool
+	}{
+		{name: "command line", text: "vllm serve Qwen/Qwen2.5-14B --max-num-seqs 128 --max-num-batched-tokens 4096 --tensor-parallel-size 2",
+			want: ServerConfig{MaxBatchSize: 128, MaxNumTokens: 4096}},
+		{name: "continued lines and equal signs", text: "python -m vllm.entrypoints.openai.api_server \\\n  --max-num-seqs=64 \\\n  --port 8000\n",
+			want: ServerConfig{MaxBatchSize: 64}},
+		{name: "config file", text: "model: Qwen/Qwen2.5-14B\nmax-num-seqs: 256\nmax_num_batched_tokens: 8192\n",
+			want: ServerConfig{MaxBatchSize: 256, MaxNumTokens: 8192}},
+		{name: "no server limits", text: "vllm serve Qwen/Qwen2.5-14B --port 8000"},
+		{name: "missing value", text: "vllm serve --max-num-seqs", expectError: true},
+		{name: "invalid value", text: "vllm serve --max-num-batched-tokens lots", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVLLMArgs(tt.text)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseVLLMArgs() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerFromMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     *ServerConfig
+	}{
+		{name: "nested keys", metadata: `{"backend_info": {"server": {"max_num_seqs": 128, "max-num-batched-tokens": "4096"}}}`,
+			want: &ServerConfig{MaxBatchSize: 128, MaxNumTokens: 4096}},
+		{name: "launch arguments", metadata: `{"backend_args": ["--max-num-seqs", "32", "--max-num-batched-tokens", "2048"]}`,
+			want: &ServerConfig{MaxBatchSize: 32, MaxNumTokens: 2048}},
+		{name: "command string", metadata: `{"command": "vllm serve m --max-num-seqs=16"}`, want: &ServerConfig{MaxBatchSize: 16}},
+		{name: "invalid values ignored", metadata: `{"max_num_seqs": -1, "max_num_batched_tokens": 1.5}`},
+		{name: "not json", metadata: `backend`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serverFromJSON(tt.metadata)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// data points take the server configuration of benchmark metadata, overridden by the options
+func TestReaders_ServerConfig(t *testing.T) {
+	rows := `[{"Name": "synchronous", "Successful Requests per second mean": 1, "Successful Prompt token count mean": 100,
+		"Successful Output token count mean": 50, "Successful Time to first token ms median": 20,
+		"Successful Inter token latency ms mean": 5, "Worker": "{\"backend_info\": {\"max_num_seqs\": 64}}"}]`
+	tests := []struct {
+		name             string
+		opts             *Options
+		wantMaxBatchSize int
+		wantMaxNumTokens int
+	}{
+		{name: "metadata", opts: &Options{}, wantMaxBatchSize: 64, wantMaxNumTokens: config.DefaultMaxNumTokens},
+		{name: "override", opts: &Options{Server: &ServerConfig{MaxNumTokens: 4096}}, wantMaxBatchSize: 64, wantMaxNumTokens: 4096},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := DetectWith([]byte(rows), "rows.json", tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			dp := r.CreateDataSet().Data[0]
+			if dp.MaxBatchSize != tt.wantMaxBatchSize || dp.MaxNumTokens != tt.wantMaxNumTokens {
+				t.Errorf("got limits %d, %d, want %d, %d", dp.MaxBatchSize, dp.MaxNumTokens, tt.wantMaxBatchSize, tt.wantMaxNumTokens)
+			}
+		})
+	}
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		want        *ServerConfig
+		expectError string
+	}{
+		{name: "no companion files"},
+		{name: "vllm arguments", files: map[string]string{VLLMArgsFileName: "vllm serve m --max-num-seqs 128 --max-num-batched-tokens 4096"},
+			want: &ServerConfig{MaxBatchSize: 128, MaxNumTokens: 4096}},
+		{name: "sidecar overrides arguments", files: map[string]string{
+			VLLMArgsFileName:     "vllm serve m --max-num-seqs 128 --max-num-batched-tokens 4096",
+			ServerConfigFileName: `{"maxNumTokens": 2048}`,
+		}, want: &ServerConfig{MaxBatchSize: 128, MaxNumTokens: 2048}},
+		{name: "invalid sidecar", files: map[string]string{ServerConfigFileName: `{"maxBatchSize": "many"}`}, expectError: ServerConfigFileName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := LoadServerConfig(filepath.Join(dir, "benchmarks.json"))
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
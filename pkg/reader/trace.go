@@ -0,0 +1,381 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// grouping of traced requests into data points
+type TraceGrouping string
+
+const (
+	GroupAuto   TraceGrouping = ""       // by run if all requests have a run label, by time window otherwise
+	GroupWindow TraceGrouping = "window" // by fixed time windows
+	GroupRun    TraceGrouping = "run"    // by run label
+)
+
+// per-request traces (one json object per line), aggregated into data points
+type TraceData struct {
+	Requests []TraceRequest
+
+	GroupBy     TraceGrouping // grouping of requests into data points
+	Window      float64       // length of time windows (sec)
+	WarmUp      float64       // period discarded at the start of the trace, or of each run (sec)
+	CoolDown    float64       // period discarded at the end of the trace, or of each run (sec)
+	MinRequests int           // minimum number of successful requests of a data point
+
+	readerOptions
+}
+
+// a traced request; times are unix times in seconds, or RFC 3339 strings
+type TraceRequest struct {
+	Arrival      TraceTime `json:"arrival"`      // arrival of the request (required)
+	FirstToken   TraceTime `json:"first_token"`  // arrival of the first output token (zero if failed)
+	Completion   TraceTime `json:"completion"`   // completion of the request (zero if failed)
+	InputTokens  int       `json:"input_tokens"` // number of prompt tokens
+	OutputTokens int       `json:"output_tokens"`
+	Run          string    `json:"run,omitempty"`   // label of the run the request belongs to
+	Model        string    `json:"model,omitempty"` // name of the served model
+	Error        string    `json:"error,omitempty"` // error of a failed request
+}
+
+// time in a trace, as unix time in seconds
+type TraceTime float64
+
+func (t *TraceTime) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*t = TraceTime(seconds)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("invalid time: %s", string(data))
+	}
+	if str == "" {
+		*t = 0
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return fmt.Errorf("invalid time %q, expected unix seconds or RFC 3339", str)
+	}
+	*t = TraceTime(float64(parsed.UnixNano()) / 1e9)
+	return nil
+}
+
+// check if the request completed successfully
+func (r *TraceRequest) succeeded() bool {
+	return r.Error == "" && r.FirstToken >= r.Arrival && r.Completion >= r.FirstToken && r.FirstToken > 0 && r.OutputTokens > 0
+}
+
+// get the time to first token (msec)
+func (r *TraceRequest) ttft() float64 {
+	return float64(r.FirstToken-r.Arrival) * 1000
+}
+
+// get the average inter-token latency (msec), false if a single token was generated
+func (r *TraceRequest) itl() (float64, bool) {
+	if r.OutputTokens < 2 {
+		return 0, false
+	}
+	return float64(r.Completion-r.FirstToken) * 1000 / float64(r.OutputTokens-1), true
+}
+
+// get the time per output token (msec), including the first token
+func (r *TraceRequest) tpot() float64 {
+	return float64(r.Completion-r.Arrival) * 1000 / float64(r.OutputTokens)
+}
+
+func NewTraceData() *TraceData {
+	d := &TraceData{}
+	d.setTraceOptions(DefaultTraceOptions())
+	return d
+}
+
+// set the reader options, with their aggregation options of traces if any
+func (d *TraceData) SetOptions(opts *Options) {
+	d.readerOptions.SetOptions(opts)
+	if opts != nil && opts.Trace != nil {
+		d.setTraceOptions(opts.Trace)
+	}
+}
+
+// set the aggregation options of traces
+func (d *TraceData) setTraceOptions(t *TraceOptions) {
+	d.GroupBy, d.Window, d.WarmUp, d.CoolDown, d.MinRequests = t.GroupBy, t.Window, t.WarmUp, t.CoolDown, t.MinRequests
+}
+
+// read traced requests, one json object per line, ordered by arrival
+func (d *TraceData) ReadFrom(dataBytes []byte) error {
+	return d.ReadFromStream(bytes.NewReader(dataBytes))
+}
+
+// read requests a line at a time, holding only the requests in memory
+func (d *TraceData) ReadFromStream(in io.Reader) error {
+	requests := []TraceRequest{}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var request TraceRequest
+		if err := json.Unmarshal(text, &request); err != nil {
+			return fmt.Errorf("trace line %d: %w", line, err)
+		}
+		var arrival struct {
+			Arrival *TraceTime `json:"arrival"`
+		}
+		if json.Unmarshal(text, &arrival); arrival.Arrival == nil {
+			return fmt.Errorf("trace line %d: missing arrival time", line)
+		}
+		requests = append(requests, request)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return fmt.Errorf("no requests in trace")
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].Arrival < requests[j].Arrival })
+	d.Requests = requests
+	return nil
+}
+
+// a group of requests aggregated into a data point, over a period of time
+type traceGroup struct {
+	label      string
+	start, end float64
+	requests   []*TraceRequest
+}
+
+// get the grouping of requests, resolving the automatic one
+func (d *TraceData) grouping() TraceGrouping {
+	if d.GroupBy != GroupAuto {
+		return d.GroupBy
+	}
+	for i := range d.Requests {
+		if d.Requests[i].Run == "" {
+			return GroupWindow
+		}
+	}
+	return GroupRun
+}
+
+// group requests, by run or by time window, without the warm-up and cool-down periods
+func (d *TraceData) groups() []*traceGroup {
+	if len(d.Requests) == 0 {
+		return nil
+	}
+	groups := []*traceGroup{}
+	if d.grouping() == GroupRun {
+		byRun := map[string]*traceGroup{}
+		for i := range d.Requests {
+			r := &d.Requests[i]
+			g, ok := byRun[r.Run]
+			if !ok {
+				g = &traceGroup{label: r.Run, start: float64(r.Arrival)}
+				byRun[r.Run] = g
+				groups = append(groups, g)
+			}
+			g.end = float64(r.Arrival)
+			g.requests = append(g.requests, r)
+		}
+		for _, g := range groups {
+			g.trim(g.start+d.WarmUp, g.end-d.CoolDown)
+		}
+		return groups
+	}
+
+	first := float64(d.Requests[0].Arrival)
+	start := first + d.WarmUp
+	end := float64(d.Requests[len(d.Requests)-1].Arrival) - d.CoolDown
+	window := d.Window
+	if window <= 0 {
+		window = end - start
+	}
+	for k := 0; start+float64(k)*window < end; k++ {
+		t := start + float64(k)*window
+		groups = append(groups, &traceGroup{
+			label:    fmt.Sprintf("window@%gs", t-first), // offset from the first arrival
+			start:    t,
+			end:      math.Min(t+window, end),
+			requests: d.arrivals(t, math.Min(t+window, end)),
+		})
+	}
+	return groups
+}
+
+// keep the requests of a group arriving in a period
+func (g *traceGroup) trim(start, end float64) {
+	kept := []*TraceRequest{}
+	for _, r := range g.requests {
+		if float64(r.Arrival) >= start && float64(r.Arrival) < end {
+			kept = append(kept, r)
+		}
+	}
+	g.start, g.end, g.requests = start, end, kept
+}
+
+// get the requests arriving in a period
+func (d *TraceData) arrivals(start, end float64) []*TraceRequest {
+	requests := []*TraceRequest{}
+	for i := range d.Requests {
+		if t := float64(d.Requests[i].Arrival); t >= start && t < end {
+			requests = append(requests, &d.Requests[i])
+		}
+	}
+	return requests
+}
+
+// get the mean, median and percentiles of samples
+func sampleStats(samples []float64) metricStats {
+	if len(samples) == 0 {
+		return metricStats{}
+	}
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+	quantile := func(q float64) float64 {
+		// nearest rank
+		k := int(math.Ceil(q*float64(len(sorted)))) - 1
+		return sorted[max(k, 0)]
+	}
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return metricStats{
+		Mean:        sum / float64(n),
+		Median:      median,
+		Percentiles: &config.Percentiles{P50: median, P90: quantile(0.90), P95: quantile(0.95), P99: quantile(0.99)},
+	}
+}
+
+// get the standard deviation of samples
+func stdDev(samples []float64, mean float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sumSquares := 0.0
+	for _, v := range samples {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(samples)))
+}
+
+// aggregate a group of requests into a data point, nil if too few requests succeeded
+func (d *TraceData) aggregate(g *traceGroup) *core.DataPoint {
+	var ttfts, itls, tpots, inputs, outputs []float64
+	model := ""
+	for _, r := range g.requests {
+		if !r.succeeded() {
+			continue
+		}
+		ttfts = append(ttfts, r.ttft())
+		if itl, ok := r.itl(); ok {
+			itls = append(itls, itl)
+		}
+		tpots = append(tpots, r.tpot())
+		inputs = append(inputs, float64(r.InputTokens))
+		outputs = append(outputs, float64(r.OutputTokens))
+		if model == "" {
+			model = r.Model
+		}
+	}
+	if len(ttfts) == 0 || len(ttfts) < d.MinRequests || g.end <= g.start {
+		return nil
+	}
+
+	stats := &benchmarkStats{
+		TTFT:         sampleStats(ttfts),
+		ITL:          sampleStats(itls),
+		TPOT:         sampleStats(tpots),
+		InputTokens:  sampleStats(inputs),
+		OutputTokens: sampleStats(outputs),
+	}
+	ttft, itl, inputTokens, outputTokens := d.values(stats)
+	return &core.DataPoint{
+		RequestRate:        float64(len(ttfts)) / (g.end - g.start),
+		InputTokens:        inputTokens,
+		OutputTokens:       outputTokens,
+		InputTokensStdDev:  stdDev(inputs, stats.InputTokens.Mean),
+		OutputTokensStdDev: stdDev(outputs, stats.OutputTokens.Mean),
+		AvgTTFTTime:        ttft,
+		AvgITLTime:         itl,
+		TTFTPercentiles:    stats.TTFT.Percentiles,
+		ITLPercentiles:     stats.ITL.Percentiles,
+		MaxBatchSize:       config.DefaultMaxBatchSize,
+		MaxNumTokens:       config.DefaultMaxNumTokens,
+		Labels: &core.Labels{
+			BenchmarkID: g.label,
+			Model:       model,
+		},
+	}
+}
+
+// create a data set object with a data point per group of requests, selected by the reader options
+func (d *TraceData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
+	for _, g := range d.groups() {
+		if dp := d.aggregate(g); dp != nil {
+			points = append(points, dp)
+		}
+	}
+	return d.createDataSet("request trace data", points)
+}
+
+func (d *TraceData) Print() {
+	fmt.Printf("Requests: %d, grouped by %s\n", len(d.Requests), d.grouping())
+	for _, g := range d.groups() {
+		succeeded := 0
+		for _, r := range g.requests {
+			if r.succeeded() {
+				succeeded++
+			}
+		}
+		fmt.Printf("  %s: %d requests (%d successful) over %.2f sec\n", g.label, len(g.requests), succeeded, g.end-g.start)
+	}
+}
+
+func (d *TraceData) Dump() string {
+	if jsonStr, err := json.Marshal(d); err == nil {
+		return fmt.Sprintf("Request trace data: %v\n", string(jsonStr))
+	}
+	return "Request trace data: <unavailable>"
+}
+
+// sniff per-request traces, checking the first line
+func sniffTrace(dataBytes []byte, filename string) error {
+	first, _, _ := bytes.Cut(bytes.TrimSpace(dataBytes), []byte("\n"))
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(first, &request); err != nil {
+		return fmt.Errorf("not json lines")
+	}
+	missing := []string{}
+	for _, key := range []string{"arrival", "first_token", "completion"} {
+		if _, ok := request[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no %s keys", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package reader
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTraceData_CreateDataSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		configure  func(d *TraceData)
+		validateFn func(t *testing.T, d *TraceData)
+	}{
+		{
+			name: "by run",
+			configure: func(d *TraceData) {
+				d.WarmUp, d.CoolDown = 5, 5
+			},
+			validateFn: func(t *testing.T, d *TraceData) {
+				ds := d.CreateDataSet()
+				if ds.Size() != 2 {
+					t.Fatalf("got %d points, want 2", ds.Size())
+				}
+				// run rate-2: 39 requests in [5, 24.5), one of them failed
+				dp := ds.Data[0]
+				if dp.Labels.BenchmarkID != "rate-2" || math.Abs(dp.RequestRate-38/19.5) > 1e-9 {
+					t.Errorf("unexpected point %+v", dp)
+				}
+				if math.Abs(dp.AvgTTFTTime-50) > 1e-6 || math.Abs(dp.AvgITLTime-10) > 1e-6 || dp.InputTokens != 200 || dp.OutputTokens != 101 {
+					t.Errorf("unexpected latencies or token counts %+v", dp)
+				}
+				dp = ds.Data[1]
+				if dp.Labels.BenchmarkID != "rate-4" || math.Abs(dp.RequestRate-4) > 1e-9 || math.Abs(dp.AvgITLTime-20) > 1e-6 {
+					t.Errorf("unexpected point %+v", dp)
+				}
+				if dp.Labels.Model != "Qwen/Qwen2.5-0.5B" || dp.TTFTPercentiles == nil {
+					t.Errorf("missing labels or percentiles %+v", dp)
+				}
+			},
+		},
+		{
+			name: "by time window",
+			configure: func(d *TraceData) {
+				d.GroupBy, d.Window, d.WarmUp, d.CoolDown = GroupWindow, 60, 5, 5
+			},
+			validateFn: func(t *testing.T, d *TraceData) {
+				ds := d.CreateDataSet()
+				if ds.Size() != 2 || ds.Data[0].Labels.BenchmarkID != "window@5s" || ds.Data[1].Labels.BenchmarkID != "window@65s" {
+					t.Fatalf("unexpected windows %+v", ds.Data)
+				}
+				// second window [65, 124.75) holds the requests of run rate-4 from 100 on
+				if math.Abs(ds.Data[1].RequestRate-99/59.75) > 1e-9 {
+					t.Errorf("got rate %v, want %v", ds.Data[1].RequestRate, 99/59.75)
+				}
+			},
+		},
+		{
+			name: "by time window, set by options",
+			configure: func(d *TraceData) {
+				trace := &TraceOptions{GroupBy: GroupWindow, Window: 60, WarmUp: 5, CoolDown: 5}
+				d.SetOptions(&Options{Trace: trace})
+			},
+			validateFn: func(t *testing.T, d *TraceData) {
+				ds := d.CreateDataSet()
+				if ds.Size() != 2 || ds.Data[0].Labels.BenchmarkID != "window@5s" || d.MinRequests != 0 {
+					t.Fatalf("trace options not applied: %+v", ds.Data)
+				}
+				d.SetOptions(&Options{})
+				if d.GroupBy != GroupWindow || d.Window != 60 {
+					t.Errorf("trace options reset by options without them")
+				}
+			},
+		},
+		{
+			name: "too few requests",
+			configure: func(d *TraceData) {
+				d.MinRequests = 30
+			},
+			validateFn: func(t *testing.T, d *TraceData) {
+				if ds := d.CreateDataSet(); ds.Size() != 1 || ds.Data[0].Labels.BenchmarkID != "rate-4" {
+					t.Errorf("unexpected data set %+v", ds.Data)
+				}
+			},
+		},
+		{
+			name: "p99 TTFT and median TPOT",
+			configure: func(d *TraceData) {
+				d.SetOptions(&Options{TTFTStat: StatP99, ITLMetric: MetricTPOT, TPOTStat: StatMedian})
+			},
+			validateFn: func(t *testing.T, d *TraceData) {
+				dp := d.CreateDataSet().Data[0]
+				// time per output token including the first: 1050 msec over 101 tokens
+				if math.Abs(dp.AvgTTFTTime-50) > 1e-6 || math.Abs(dp.AvgITLTime-1050.0/101) > 1e-6 {
+					t.Errorf("unexpected latencies %+v", dp)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTraceData()
+			if err := d.ReadFrom(readTestFile(t, "testdata/trace.jsonl")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.configure(d)
+			tt.validateFn(t, d)
+		})
+	}
+}
+
+func TestTraceData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectError string
+		wantArrival []TraceTime
+	}{
+		{name: "rfc 3339 times, out of order", data: `{"arrival": "2025-06-01T10:00:01Z", "first_token": "2025-06-01T10:00:01.1Z", "completion": "2025-06-01T10:00:02Z"}
+
+{"arrival": "2025-06-01T10:00:00Z", "first_token": "2025-06-01T10:00:00.1Z", "completion": "2025-06-01T10:00:01Z"}`,
+			wantArrival: []TraceTime{1748772000, 1748772001}},
+		{name: "invalid time", data: `{"arrival": "yesterday"}`, expectError: "trace line 1"},
+		{name: "missing arrival", data: `{"arrival": 1}` + "\n" + `{"first_token": 2}`, expectError: "trace line 2: missing arrival"},
+		{name: "empty", data: "\n", expectError: "no requests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTraceData()
+			err := d.ReadFrom([]byte(tt.data))
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, want := range tt.wantArrival {
+				if d.Requests[i].Arrival != want {
+					t.Errorf("request %d: arrival %v, want %v", i, d.Requests[i].Arrival, want)
+				}
+			}
+		})
+	}
+}
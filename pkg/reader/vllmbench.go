@@ -0,0 +1,348 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// data in vLLM benchmark_serving result files, one benchmark per run
+type VLLMBenchData struct {
+	Benchmarks []BenchmarkVLLM
+	readerOptions
+}
+
+// result of a benchmark_serving run
+type BenchmarkVLLM struct {
+	Date              string      `json:"date"`
+	Backend           string      `json:"backend"`
+	ModelID           string      `json:"model_id"`
+	RequestRate       RequestRate `json:"request_rate"`    // target request rate, infinite if all requests are sent at once
+	Burstiness        float64     `json:"burstiness"`      // shape of the gamma distribution of arrivals (1 for Poisson)
+	MaxConcurrency    int         `json:"max_concurrency"` // maximum number of concurrent requests (no limit if zero)
+	Duration          float64     `json:"duration"`        // duration of the run (sec)
+	Completed         int         `json:"completed"`       // number of successful requests
+	TotalInputTokens  float64     `json:"total_input_tokens"`
+	TotalOutputTokens float64     `json:"total_output_tokens"`
+	RequestThroughput float64     `json:"request_throughput"`
+
+	MeanTTFT   float64 `json:"mean_ttft_ms"`
+	MedianTTFT float64 `json:"median_ttft_ms"`
+	StdTTFT    float64 `json:"std_ttft_ms"`
+	MeanTPOT   float64 `json:"mean_tpot_ms"`
+	MedianTPOT float64 `json:"median_tpot_ms"`
+	StdTPOT    float64 `json:"std_tpot_ms"`
+	MeanITL    float64 `json:"mean_itl_ms"`
+	MedianITL  float64 `json:"median_itl_ms"`
+	StdITL     float64 `json:"std_itl_ms"`
+
+	// token counts of requests (failed requests have no output tokens)
+	InputLens  []int `json:"input_lens"`
+	OutputLens []int `json:"output_lens"`
+
+	// keys of percentiles depend on the --metric-percentiles option (e.g. p99_ttft_ms), hence cannot be given as struct tags
+	TTFTPercentiles map[string]float64 `json:"-"`
+	TPOTPercentiles map[string]float64 `json:"-"`
+	ITLPercentiles  map[string]float64 `json:"-"`
+
+	// server configuration in metadata given with the --metadata option
+	Server *ServerConfig `json:"-"`
+}
+
+// request rate of a run, given as a number or as "inf"
+type RequestRate float64
+
+func (r *RequestRate) UnmarshalJSON(data []byte) error {
+	var rate float64
+	if err := json.Unmarshal(data, &rate); err == nil {
+		*r = RequestRate(rate)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || !strings.HasPrefix(strings.ToLower(str), "inf") {
+		return fmt.Errorf("invalid request rate: %s", string(data))
+	}
+	*r = RequestRate(math.Inf(1))
+	return nil
+}
+
+func (r RequestRate) MarshalJSON() ([]byte, error) {
+	if math.IsInf(float64(r), 1) {
+		return []byte(`"inf"`), nil
+	}
+	return json.Marshal(float64(r))
+}
+
+// keys of percentiles in benchmark_serving results, e.g. p99_ttft_ms or p99.9_itl_ms
+var vllmPercentileKey = regexp.MustCompile(`^(p[0-9.]+)_(ttft|tpot|itl)_ms$`)
+
+func (b *BenchmarkVLLM) UnmarshalJSON(data []byte) error {
+	type plainBenchmarkVLLM BenchmarkVLLM
+	if err := json.Unmarshal(data, (*plainBenchmarkVLLM)(b)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	b.TTFTPercentiles, b.TPOTPercentiles, b.ITLPercentiles = map[string]float64{}, map[string]float64{}, map[string]float64{}
+	metadata := map[string]any{}
+	for key, value := range raw {
+		if m := vllmPercentileKey.FindStringSubmatch(key); m != nil {
+			var v float64
+			if err := json.Unmarshal(value, &v); err != nil {
+				return fmt.Errorf("invalid %s: %s", key, string(value))
+			}
+			switch m[2] {
+			case "ttft":
+				b.TTFTPercentiles[m[1]] = v
+			case "tpot":
+				b.TPOTPercentiles[m[1]] = v
+			case "itl":
+				b.ITLPercentiles[m[1]] = v
+			}
+			continue
+		}
+		// metadata is merged into results; skip per-request arrays, which may hold arbitrary text
+		var v any
+		if err := json.Unmarshal(value, &v); err == nil {
+			if _, isArray := v.([]any); !isArray {
+				metadata[key] = v
+			}
+		}
+	}
+	b.Server = serverFromMetadata(metadata)
+	return nil
+}
+
+// check whether a json object holds a benchmark_serving result
+func isVLLMResult(raw map[string]json.RawMessage) bool {
+	_, hasTTFT := raw["mean_ttft_ms"]
+	_, hasCompleted := raw["completed"]
+	return hasTTFT && hasCompleted
+}
+
+func NewVLLMBenchData() *VLLMBenchData {
+	return &VLLMBenchData{}
+}
+
+// read benchmark_serving results: a single run, a json array of runs, or one run per line
+// (as written with the --append-result option)
+func (v *VLLMBenchData) ReadFrom(dataBytes []byte) error {
+	benchmarks, err := decodeVLLMResults(dataBytes)
+	if err != nil {
+		return err
+	}
+	v.Benchmarks = benchmarks
+	return nil
+}
+
+// ReadDir reads the benchmark_serving results in the json files of a directory, typically one file
+// per request rate, skipping files of other formats; runs are ordered by request rate
+func (v *VLLMBenchData) ReadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	benchmarks := []BenchmarkVLLM{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".jsonl") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		dataBytes, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if sniffVLLMBench(dataBytes, path) != nil {
+			continue
+		}
+		runs, err := decodeVLLMResults(dataBytes)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		benchmarks = append(benchmarks, runs...)
+	}
+	if len(benchmarks) == 0 {
+		return fmt.Errorf("no vLLM benchmark results in %s", dir)
+	}
+	sort.SliceStable(benchmarks, func(i, j int) bool { return benchmarks[i].RequestRate < benchmarks[j].RequestRate })
+	v.Benchmarks = benchmarks
+	return nil
+}
+
+// decode a sequence of json values, each a run or an array of runs
+func decodeVLLMResults(dataBytes []byte) ([]BenchmarkVLLM, error) {
+	decoder := json.NewDecoder(bytes.NewReader(dataBytes))
+	benchmarks := []BenchmarkVLLM{}
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid vLLM benchmark results: %w", err)
+		}
+		if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '[' {
+			var runs []BenchmarkVLLM
+			if err := json.Unmarshal(value, &runs); err != nil {
+				return nil, err
+			}
+			benchmarks = append(benchmarks, runs...)
+			continue
+		}
+		var run BenchmarkVLLM
+		if err := json.Unmarshal(value, &run); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, run)
+	}
+	if len(benchmarks) == 0 {
+		return nil, fmt.Errorf("no vLLM benchmark results")
+	}
+	return benchmarks, nil
+}
+
+// get the strategy of a run, named as GuideLLM strategies
+func (b *BenchmarkVLLM) strategy() string {
+	switch {
+	case math.IsInf(float64(b.RequestRate), 1) && b.MaxConcurrency > 0:
+		return fmt.Sprintf("%s@%d", StrategyConcurrent, b.MaxConcurrency)
+	case math.IsInf(float64(b.RequestRate), 1):
+		return StrategyThroughput
+	case b.Burstiness == 0 || b.Burstiness == 1:
+		return fmt.Sprintf("%s@%.2f", StrategyPoisson, float64(b.RequestRate))
+	default:
+		return fmt.Sprintf("%s@%.2f", StrategyGamma, float64(b.RequestRate))
+	}
+}
+
+// get the successful request throughput of a run (requests/sec)
+func (b *BenchmarkVLLM) throughput() float64 {
+	if b.Duration > 0 {
+		return float64(b.Completed) / b.Duration
+	}
+	return b.RequestThroughput
+}
+
+// get the statistics of the token counts of successful requests, the mean derived from the total
+func tokenCountStats(total float64, completed int, lens []int, outputLens []int) (metricStats, float64) {
+	stats := metricStats{}
+	if completed > 0 {
+		stats.Mean = total / float64(completed)
+	}
+	counts := []float64{}
+	for i, n := range lens {
+		if len(outputLens) == len(lens) && outputLens[i] == 0 {
+			continue
+		}
+		counts = append(counts, float64(n))
+	}
+	if len(counts) == 0 {
+		stats.Median = stats.Mean
+		return stats, 0
+	}
+	sort.Float64s(counts)
+	stats.Median = counts[len(counts)/2]
+	if len(counts)%2 == 0 {
+		stats.Median = (counts[len(counts)/2-1] + counts[len(counts)/2]) / 2
+	}
+	sumSquares := 0.0
+	for _, n := range counts {
+		sumSquares += (n - stats.Mean) * (n - stats.Mean)
+	}
+	return stats, math.Sqrt(sumSquares / float64(len(counts)))
+}
+
+// create a data set object from benchmark data, selected by the reader options
+func (v *VLLMBenchData) CreateDataSet() *core.DataSet {
+	points := []*core.DataPoint{}
+	for _, benchmark := range v.Benchmarks {
+		inputTokens, inputStdDev := tokenCountStats(benchmark.TotalInputTokens, benchmark.Completed, benchmark.InputLens, benchmark.OutputLens)
+		outputTokens, outputStdDev := tokenCountStats(benchmark.TotalOutputTokens, benchmark.Completed, benchmark.OutputLens, benchmark.OutputLens)
+		ttftPercentiles := percentilesFromMap(benchmark.TTFTPercentiles)
+		itlPercentiles := percentilesFromMap(benchmark.ITLPercentiles)
+		ttft, itl, input, output := v.values(&benchmarkStats{
+			TTFT:         metricStats{Mean: benchmark.MeanTTFT, Median: benchmark.MedianTTFT, Percentiles: ttftPercentiles},
+			ITL:          metricStats{Mean: benchmark.MeanITL, Median: benchmark.MedianITL, Percentiles: itlPercentiles},
+			TPOT:         metricStats{Mean: benchmark.MeanTPOT, Median: benchmark.MedianTPOT, Percentiles: percentilesFromMap(benchmark.TPOTPercentiles)},
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+		})
+		maxBatchSize, maxNumTokens := benchmark.Server.limits()
+		dataPoint := &core.DataPoint{
+			RequestRate:        benchmark.throughput(),
+			InputTokens:        input,
+			OutputTokens:       output,
+			InputTokensStdDev:  inputStdDev,
+			OutputTokensStdDev: outputStdDev,
+			AvgTTFTTime:        ttft,
+			AvgITLTime:         itl,
+			TTFTPercentiles:    ttftPercentiles,
+			ITLPercentiles:     itlPercentiles,
+			MaxBatchSize:       maxBatchSize,
+			MaxNumTokens:       maxNumTokens,
+			Labels: &core.Labels{
+				BenchmarkID: benchmark.Date,
+				Strategy:    benchmark.strategy(),
+				Model:       benchmark.ModelID,
+			},
+		}
+		points = append(points, dataPoint)
+	}
+	return v.createDataSet("vLLM benchmark data", points)
+}
+
+func (v *VLLMBenchData) Print() {
+	for _, benchmark := range v.Benchmarks {
+		fmt.Printf("Benchmark: %s\n", benchmark.Date)
+		fmt.Printf("  Model: %s\n", benchmark.ModelID)
+		fmt.Printf("  Strategy: %s\n", benchmark.strategy())
+		fmt.Printf("  Completed: %d in %.2f sec\n", benchmark.Completed, benchmark.Duration)
+		fmt.Printf("  RPS: %.2f\n", benchmark.throughput())
+		fmt.Printf("  Total Tokens: Input=%.0f, Output=%.0f\n", benchmark.TotalInputTokens, benchmark.TotalOutputTokens)
+		fmt.Printf("  TTFT: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", benchmark.MeanTTFT, benchmark.MedianTTFT, benchmark.StdTTFT)
+		fmt.Printf("  TPOT: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", benchmark.MeanTPOT, benchmark.MedianTPOT, benchmark.StdTPOT)
+		fmt.Printf("  ITL: Mean=%.2f, Median=%.2f, StdDev=%.2f\n", benchmark.MeanITL, benchmark.MedianITL, benchmark.StdITL)
+	}
+}
+
+func (v *VLLMBenchData) Dump() string {
+	if jsonStr, err := json.Marshal(v); err == nil {
+		return fmt.Sprintf("vLLM Benchmarks data: %v\n", string(jsonStr))
+	}
+	return "vLLM Benchmarks data: <unavailable>"
+}
+
+// sniff benchmark_serving results, checking the first run
+func sniffVLLMBench(dataBytes []byte, filename string) error {
+	if !isJSONData(dataBytes) {
+		return fmt.Errorf("not json")
+	}
+	var first json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(dataBytes)).Decode(&first); err != nil {
+		return fmt.Errorf("not json")
+	}
+	var runs []map[string]json.RawMessage
+	if err := json.Unmarshal(first, &runs); err != nil {
+		var run map[string]json.RawMessage
+		if err := json.Unmarshal(first, &run); err != nil {
+			return fmt.Errorf("not a json object")
+		}
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 || !isVLLMResult(runs[0]) {
+		return fmt.Errorf("no \"mean_ttft_ms\" and \"completed\" keys")
+	}
+	return nil
+}
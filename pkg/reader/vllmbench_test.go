@@ -0,0 +1,113 @@
+package reader
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestVLLMBenchData_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		expectError string
+		validateFn  func(t *testing.T, v *VLLMBenchData)
+	}{
+		{
+			name: "single run",
+			data: readTestFile(t, "testdata/vllm-bench/vllm-qps-1.json"),
+			validateFn: func(t *testing.T, v *VLLMBenchData) {
+				if len(v.Benchmarks) != 1 {
+					t.Fatalf("got %d benchmarks, want 1", len(v.Benchmarks))
+				}
+				ds := v.CreateDataSet()
+				if ds.Size() != 1 {
+					t.Fatalf("got %d points, want 1", ds.Size())
+				}
+				dp := ds.Data[0]
+				// one of 4 requests failed: averages are over the 3 successful ones
+				if math.Abs(dp.RequestRate-0.75) > 1e-9 || math.Abs(dp.InputTokens-1492.0/3) > 1e-9 || math.Abs(dp.OutputTokens-370.0/3) > 1e-9 {
+					t.Errorf("unexpected rate or token counts: %+v", dp)
+				}
+				if dp.AvgTTFTTime != 19 || dp.AvgITLTime != 5.5 || dp.TTFTPercentiles == nil || dp.TTFTPercentiles.P99 != 32 {
+					t.Errorf("unexpected latencies: %+v", dp)
+				}
+				if dp.MaxBatchSize != 128 || dp.MaxNumTokens != 4096 {
+					t.Errorf("server configuration not read from metadata: %d, %d", dp.MaxBatchSize, dp.MaxNumTokens)
+				}
+				if dp.Labels.Strategy != "poisson@1.00" || dp.Labels.Model != "Qwen/Qwen2.5-0.5B" {
+					t.Errorf("unexpected labels %+v", dp.Labels)
+				}
+			},
+		},
+		{
+			name: "appended runs",
+			data: readTestFile(t, "testdata/vllm-bench.jsonl"),
+			validateFn: func(t *testing.T, v *VLLMBenchData) {
+				if len(v.Benchmarks) != 2 || !math.IsInf(float64(v.Benchmarks[1].RequestRate), 1) {
+					t.Fatalf("unexpected benchmarks %+v", v.Benchmarks)
+				}
+				// the throughput run is excluded by default
+				if ds := v.CreateDataSet(); ds.Size() != 1 {
+					t.Errorf("got %d points, want 1", ds.Size())
+				}
+				v.SetOptions(&Options{ITLMetric: MetricTPOT, Exclude: []string{}})
+				ds := v.CreateDataSet()
+				if ds.Size() != 2 || ds.Data[1].Labels.Strategy != StrategyThroughput || ds.Data[0].AvgITLTime != 5.6 {
+					t.Errorf("unexpected data set %+v", ds.Data)
+				}
+			},
+		},
+		{
+			name: "array of runs",
+			data: []byte(`[{"request_rate": 4, "duration": 10, "completed": 20, "total_input_tokens": 2000, "total_output_tokens": 1000,
+				"mean_ttft_ms": 30, "median_ttft_ms": 28, "mean_itl_ms": 7, "median_itl_ms": 6.9}]`),
+			validateFn: func(t *testing.T, v *VLLMBenchData) {
+				dp := v.CreateDataSet().Data[0]
+				if dp.RequestRate != 2 || dp.InputTokens != 100 || dp.OutputTokens != 50 || dp.AvgTTFTTime != 28 {
+					t.Errorf("unexpected data point %+v", dp)
+				}
+			},
+		},
+		{
+			name:        "invalid request rate",
+			data:        []byte(`{"request_rate": "fast", "completed": 1, "mean_ttft_ms": 1}`),
+			expectError: "invalid request rate",
+		},
+		{
+			name:        "empty",
+			data:        []byte(" \n"),
+			expectError: "no vLLM benchmark results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVLLMBenchData()
+			err := v.ReadFrom(tt.data)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.validateFn(t, v)
+		})
+	}
+}
+
+func TestVLLMBenchData_ReadDir(t *testing.T) {
+	v := NewVLLMBenchData()
+	if err := v.ReadDir("testdata/vllm-bench"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(v.Benchmarks) != 2 || v.Benchmarks[0].RequestRate != 1 || v.Benchmarks[1].RequestRate != 2 {
+		t.Fatalf("runs not read in order of request rate: %+v", v.Benchmarks)
+	}
+	if err := NewVLLMBenchData().ReadDir(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without results")
+	}
+}
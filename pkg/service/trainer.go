@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -22,9 +23,17 @@ func NewTrainer() *Trainer {
 	return trainer
 }
 
+// default address the service listens on
+const DefaultAddress = ":8080"
+
 // start service
 func (trainer *Trainer) Run() {
-	trainer.router.Run(":8080")
+	trainer.RunOn(DefaultAddress)
+}
+
+// start service listening on an address (e.g. localhost:8080), returning an error if it cannot listen
+func (trainer *Trainer) RunOn(addr string) error {
+	return trainer.router.Run(addr)
 }
 
 // train using a data set
@@ -43,8 +52,32 @@ func train(c *gin.Context) {
 		Gamma: 0.0,
 	}
 
+	// optional statistic of the latency distributions to fit (e.g. ?statistic=p90)
+	stat := config.Statistic(c.DefaultQuery("statistic", string(config.StatisticAverage)))
+	if !stat.IsValid() {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "unknown statistic: " + string(stat)})
+		return
+	}
+
+	// optional kind of model to fit (e.g. ?model=dispersion, accounting for the dispersion of token counts)
+	kind := config.ModelKind(c.DefaultQuery("model", string(config.ModelMean)))
+	if !kind.IsValid() {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": "unknown model: " + string(kind)})
+		return
+	}
+
 	optimizer := core.NewOptimizer(initParms)
+	optimizer.Statistic = stat
+	optimizer.Model = kind
+	// optional strict mode refusing invalid data (?strict=true)
+	optimizer.Strict = c.Query("strict") == "true"
 	optimizerResult, err := optimizer.Optimize(&dataSet, core.Model)
+	var validationErr *core.ValidationError
+	if errors.As(err, &validationErr) {
+		c.IndentedJSON(http.StatusBadRequest,
+			gin.H{"message": "validation failed", "issues": validationErr.Report.Issues})
+		return
+	}
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError,
 			gin.H{"message": "optimization failed: " + err.Error()})
@@ -0,0 +1,144 @@
+package synth
+
+import (
+	"fmt"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+// design of the experiments generating data points
+type Design string
+
+const (
+	// input variables drawn uniformly at random within their ranges
+	DesignRandom Design = "random"
+	// input variables on an evenly spaced grid within their ranges
+	DesignGrid Design = "grid"
+)
+
+// kind of noise added to generated performance metrics
+type NoiseKind string
+
+const (
+	NoiseNone NoiseKind = "none"
+	// Gaussian noise with standard deviation proportional to the value (level is a fraction)
+	NoiseMultiplicative NoiseKind = "multiplicative"
+	// Gaussian noise with a fixed standard deviation (level in msec)
+	NoiseAdditive NoiseKind = "additive"
+)
+
+// noise model of a performance metric
+type NoiseModel struct {
+	Kind  NoiseKind `json:"kind"`
+	Level float64   `json:"level"`
+}
+
+// range of values of an input variable
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// configuration of the synthetic data set generator
+type Config struct {
+	Name      string              `json:"name"`      // name of the generated data set
+	Parms     *config.ModelParams `json:"parms"`     // true model parameters
+	Design    Design              `json:"design"`    // experiment design
+	NumPoints int                 `json:"numPoints"` // number of data points (random design)
+	NumLevels int                 `json:"numLevels"` // number of levels per variable range (grid design)
+	Seed      int64               `json:"seed"`      // seed of the random number generator
+
+	RequestRate   Range `json:"requestRate"`   // range of request arrival rates (requests/sec)
+	InputTokens   Range `json:"inputTokens"`   // range of average numbers of input tokens
+	OutputTokens  Range `json:"outputTokens"`  // range of average numbers of output tokens
+	MaxBatchSizes []int `json:"maxBatchSizes"` // candidate maximum batch sizes
+	MaxNumTokens  []int `json:"maxNumTokens"`  // candidate maximum numbers of tokens in a batch
+
+	TTFTNoise NoiseModel `json:"ttftNoise"` // noise added to TTFT
+	ITLNoise  NoiseModel `json:"itlNoise"`  // noise added to ITL
+
+	// coefficient of variation of the token counts of requests, giving the standard deviations
+	// of token counts of data points (none if zero)
+	TokensCV float64 `json:"tokensCV,omitempty"`
+	// model generating the performance metrics: mean (default), or dispersion accounting for TokensCV
+	Model config.ModelKind `json:"model,omitempty"`
+}
+
+// get a configuration with default values
+func DefaultConfig() *Config {
+	return &Config{
+		Name: "random synthetic data",
+		Parms: &config.ModelParams{
+			Alpha: DefaultAlpha,
+			Beta:  DefaultBeta,
+			Gamma: DefaultGamma,
+		},
+		Design:        DesignRandom,
+		NumPoints:     DefaultNumPoints,
+		NumLevels:     DefaultNumLevels,
+		RequestRate:   Range{Min: DefaultMinRPS, Max: DefaultMaxRPS},
+		InputTokens:   Range{Min: DefaultMinTokens, Max: DefaultMaxTokens},
+		OutputTokens:  Range{Min: DefaultMinTokens, Max: DefaultMaxTokens},
+		MaxBatchSizes: []int{config.DefaultMaxBatchSize},
+		MaxNumTokens:  []int{config.DefaultMaxNumTokens},
+		TTFTNoise:     NoiseModel{Kind: NoiseMultiplicative, Level: DefaultNoiseFraction},
+		ITLNoise:      NoiseModel{Kind: NoiseMultiplicative, Level: DefaultNoiseFraction},
+	}
+}
+
+// check validity of the configuration
+func (cfg *Config) Check() error {
+	if cfg.Parms == nil {
+		return fmt.Errorf("missing true parameters")
+	}
+	switch cfg.Design {
+	case DesignRandom:
+		if cfg.NumPoints <= 0 {
+			return fmt.Errorf("invalid number of points %d", cfg.NumPoints)
+		}
+	case DesignGrid:
+		if cfg.NumLevels <= 0 {
+			return fmt.Errorf("invalid number of levels %d", cfg.NumLevels)
+		}
+	default:
+		return fmt.Errorf("unknown design %q", cfg.Design)
+	}
+	for name, r := range map[string]Range{
+		"requestRate":  cfg.RequestRate,
+		"inputTokens":  cfg.InputTokens,
+		"outputTokens": cfg.OutputTokens,
+	} {
+		if r.Min <= 0 || r.Max < r.Min {
+			return fmt.Errorf("invalid %s range [%v, %v]", name, r.Min, r.Max)
+		}
+	}
+	if len(cfg.MaxBatchSizes) == 0 || len(cfg.MaxNumTokens) == 0 {
+		return fmt.Errorf("missing batch settings")
+	}
+	if cfg.TokensCV < 0 {
+		return fmt.Errorf("invalid coefficient of variation of token counts %v", cfg.TokensCV)
+	}
+	if !cfg.Model.IsValid() {
+		return fmt.Errorf("unknown model %q", cfg.Model)
+	}
+	if err := cfg.TTFTNoise.check(); err != nil {
+		return fmt.Errorf("TTFT noise: %w", err)
+	}
+	if err := cfg.ITLNoise.check(); err != nil {
+		return fmt.Errorf("ITL noise: %w", err)
+	}
+	return nil
+}
+
+func (n NoiseModel) check() error {
+	switch n.Kind {
+	case NoiseNone, "":
+		return nil
+	case NoiseMultiplicative, NoiseAdditive:
+		if n.Level < 0 {
+			return fmt.Errorf("invalid noise level %v", n.Level)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown noise kind %q", n.Kind)
+}
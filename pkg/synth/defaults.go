@@ -0,0 +1,38 @@
+package synth
+
+const (
+	// default true parameters used to generate synthetic data
+	DefaultAlpha = 6.0
+	DefaultBeta  = 0.02
+	DefaultGamma = 0.00005
+
+	// default number of data points of a random design
+	DefaultNumPoints = 20
+
+	// default number of levels per variable of a grid design
+	DefaultNumLevels = 3
+
+	// default ranges of input variables
+	DefaultMinTokens = 200.0
+	DefaultMaxTokens = 1000.0
+	DefaultMinRPS    = 1.0
+	DefaultMaxRPS    = 6.0
+
+	// default standard deviation of multiplicative noise, as a fraction of the value
+	DefaultNoiseFraction = 0.02
+
+	// minimum noisy value, as a fraction of the noise-free value
+	NoiseFloorFraction = 0.01
+
+	// maximum number of attempts per data point of a random design
+	// (attempts fail when the queue is unstable at the drawn rate)
+	MaxAttemptsPerPoint = 100
+)
+
+const (
+	// default number of seeds per combination of a recovery benchmark
+	DefaultNumSeeds = 5
+
+	// default maximum relative error of any parameter for a fit to count as a success
+	DefaultMaxRelError = 0.1
+)
@@ -0,0 +1,182 @@
+package synth
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// ground truth of a generated data set, to check parameter recovery
+type GroundTruth struct {
+	Parms      *config.ModelParams  `json:"parms"`      // true model parameters
+	Seed       int64                `json:"seed"`       // seed of the random number generator
+	Design     Design               `json:"design"`     // experiment design
+	Clean      []*config.OutputVars `json:"clean"`      // noise-free performance metrics, per data point
+	NumSkipped int                  `json:"numSkipped"` // number of experiments skipped (e.g. unstable queue)
+}
+
+// generator of synthetic data sets from a model with known parameters
+type Generator struct {
+	Config *Config
+	Model  core.ModelFunction
+}
+
+func NewGenerator(cfg *Config) *Generator {
+	return &Generator{
+		Config: cfg,
+		Model:  core.Model,
+	}
+}
+
+// generate a synthetic data set and its ground truth
+func (g *Generator) Generate() (*core.DataSet, *GroundTruth, error) {
+	cfg := g.Config
+	if err := cfg.Check(); err != nil {
+		return nil, nil, err
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	dataSet := core.NewDataSet(cfg.Name)
+	dataSet.Metadata = &core.Labels{
+		Source: "synthetic",
+		Tags: map[string]string{
+			"design": string(cfg.Design),
+			"seed":   strconv.FormatInt(cfg.Seed, 10),
+		},
+	}
+	truth := &GroundTruth{
+		Parms:  cfg.Parms,
+		Seed:   cfg.Seed,
+		Design: cfg.Design,
+		Clean:  []*config.OutputVars{},
+	}
+
+	// add a data point for an experiment, false if the model cannot evaluate it
+	model := core.ModelOfKind(g.Model, cfg.Model)
+	add := func(x *config.InputVars) bool {
+		x.InputTokensStdDev, x.OutputTokensStdDev = cfg.TokensCV*x.InputTokens, cfg.TokensCV*x.OutputTokens
+		y, err := model(x, cfg.Parms)
+		if err != nil {
+			truth.NumSkipped++
+			return false
+		}
+		dataSet.AppendDataPoint(&core.DataPoint{
+			RequestRate:        x.RequestRate,
+			InputTokens:        x.InputTokens,
+			OutputTokens:       x.OutputTokens,
+			InputTokensStdDev:  x.InputTokensStdDev,
+			OutputTokensStdDev: x.OutputTokensStdDev,
+			AvgTTFTTime:        cfg.TTFTNoise.apply(y.AvgTTFTTime, rng),
+			AvgITLTime:         cfg.ITLNoise.apply(y.AvgITLTime, rng),
+			MaxBatchSize:       x.MaxBatchSize,
+			MaxNumTokens:       x.MaxNumTokens,
+		})
+		truth.Clean = append(truth.Clean, &config.OutputVars{
+			AvgTTFTTime: y.AvgTTFTTime,
+			AvgITLTime:  y.AvgITLTime,
+		})
+		return true
+	}
+
+	switch cfg.Design {
+	case DesignRandom:
+		for attempts := 0; dataSet.Size() < cfg.NumPoints; attempts++ {
+			if attempts >= MaxAttemptsPerPoint*cfg.NumPoints {
+				return nil, nil, fmt.Errorf("generated only %d of %d data points", dataSet.Size(), cfg.NumPoints)
+			}
+			// token counts are drawn before the rate, and candidates only if several, as in
+			// earlier versions, so that a seed keeps generating the same data set
+			x := &config.InputVars{
+				InputTokens:  cfg.InputTokens.random(rng),
+				OutputTokens: cfg.OutputTokens.random(rng),
+			}
+			x.RequestRate = cfg.RequestRate.random(rng)
+			x.MaxBatchSize = choose(cfg.MaxBatchSizes, rng)
+			x.MaxNumTokens = choose(cfg.MaxNumTokens, rng)
+			add(x)
+		}
+	case DesignGrid:
+		for _, maxBatchSize := range cfg.MaxBatchSizes {
+			for _, maxNumTokens := range cfg.MaxNumTokens {
+				for _, in := range cfg.InputTokens.levels(cfg.NumLevels) {
+					for _, out := range cfg.OutputTokens.levels(cfg.NumLevels) {
+						for _, rate := range cfg.RequestRate.levels(cfg.NumLevels) {
+							add(&config.InputVars{
+								RequestRate:  rate,
+								InputTokens:  in,
+								OutputTokens: out,
+								MaxBatchSize: maxBatchSize,
+								MaxNumTokens: maxNumTokens,
+							})
+						}
+					}
+				}
+			}
+		}
+		if dataSet.Size() == 0 {
+			return nil, nil, fmt.Errorf("no valid data point in grid")
+		}
+	}
+	return dataSet, truth, nil
+}
+
+// get the relative errors of estimated parameters with respect to the true ones
+func (truth *GroundTruth) RelativeErrors(estimated *config.ModelParams) *config.ModelParams {
+	relErr := func(est, tru float64) float64 {
+		if tru == 0 {
+			return math.Abs(est)
+		}
+		return math.Abs(est-tru) / math.Abs(tru)
+	}
+	return &config.ModelParams{
+		Alpha: relErr(estimated.Alpha, truth.Parms.Alpha),
+		Beta:  relErr(estimated.Beta, truth.Parms.Beta),
+		Gamma: relErr(estimated.Gamma, truth.Parms.Gamma),
+	}
+}
+
+// draw a value uniformly at random in the range
+func (r Range) random(rng *rand.Rand) float64 {
+	return r.Min + rng.Float64()*(r.Max-r.Min)
+}
+
+// draw a value uniformly at random among candidates, without drawing if there is one only
+func choose(candidates []int, rng *rand.Rand) int {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// get evenly spaced values in the range, including both ends
+func (r Range) levels(n int) []float64 {
+	if n <= 1 || r.Max == r.Min {
+		return []float64{(r.Min + r.Max) / 2}
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = r.Min + float64(i)*(r.Max-r.Min)/float64(n-1)
+	}
+	return values
+}
+
+// add noise to a value, clamped to a small positive floor; nothing is drawn without noise
+func (n NoiseModel) apply(value float64, rng *rand.Rand) float64 {
+	if n.Level == 0 {
+		return value
+	}
+	var noisy float64
+	switch n.Kind {
+	case NoiseMultiplicative:
+		noisy = value * (1.0 + n.Level*rng.NormFloat64())
+	case NoiseAdditive:
+		noisy = value + n.Level*rng.NormFloat64()
+	default:
+		return value
+	}
+	return math.Max(noisy, value*NoiseFloorFraction)
+}
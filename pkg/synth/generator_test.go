@@ -0,0 +1,247 @@
+package synth
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// mockModel: linear in parameters, unstable above 5 requests/sec
+func mockModel(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+	if x.RequestRate > 5 {
+		return nil, fmt.Errorf("unstable")
+	}
+	return &config.OutputVars{
+		AvgTTFTTime: params.Alpha + params.Beta*x.InputTokens,
+		AvgITLTime:  params.Alpha + params.Gamma*x.OutputTokens,
+	}, nil
+}
+
+func newTestGenerator(modify func(cfg *Config)) *Generator {
+	cfg := DefaultConfig()
+	modify(cfg)
+	return &Generator{Config: cfg, Model: mockModel}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectError bool
+		validateFn  func(t *testing.T, ds *core.DataSet, truth *GroundTruth)
+	}{
+		{
+			name: "random design without noise",
+			modify: func(cfg *Config) {
+				cfg.TTFTNoise.Kind = NoiseNone
+				cfg.ITLNoise.Kind = NoiseNone
+			},
+			validateFn: func(t *testing.T, ds *core.DataSet, truth *GroundTruth) {
+				if ds.Size() != DefaultNumPoints || len(truth.Clean) != DefaultNumPoints {
+					t.Fatalf("size = %d, clean = %d, want %d", ds.Size(), len(truth.Clean), DefaultNumPoints)
+				}
+				for i, dp := range ds.Data {
+					if dp.RequestRate < DefaultMinRPS || dp.RequestRate > 5 {
+						t.Errorf("point %d: rate %v out of range", i, dp.RequestRate)
+					}
+					if dp.AvgTTFTTime != truth.Clean[i].AvgTTFTTime || dp.AvgITLTime != truth.Clean[i].AvgITLTime {
+						t.Errorf("point %d: noisy values without noise", i)
+					}
+				}
+				if truth.NumSkipped == 0 {
+					t.Error("expected unstable experiments to be skipped")
+				}
+			},
+		},
+		{
+			name: "grid design",
+			modify: func(cfg *Config) {
+				cfg.Design = DesignGrid
+				cfg.NumLevels = 3
+				cfg.RequestRate = Range{Min: 1, Max: 4}
+				cfg.MaxBatchSizes = []int{64, 128}
+			},
+			validateFn: func(t *testing.T, ds *core.DataSet, truth *GroundTruth) {
+				if want := 2 * 3 * 3 * 3; ds.Size() != want {
+					t.Errorf("size = %d, want %d", ds.Size(), want)
+				}
+				if ds.Data[0].RequestRate != 1 || ds.Data[2].RequestRate != 4 || ds.Data[1].RequestRate != 2.5 {
+					t.Errorf("unexpected grid rates %v, %v, %v", ds.Data[0].RequestRate, ds.Data[1].RequestRate, ds.Data[2].RequestRate)
+				}
+			},
+		},
+		{
+			name: "additive noise",
+			modify: func(cfg *Config) {
+				cfg.TTFTNoise = NoiseModel{Kind: NoiseAdditive, Level: 1.0}
+				cfg.ITLNoise = NoiseModel{Kind: NoiseNone}
+			},
+			validateFn: func(t *testing.T, ds *core.DataSet, truth *GroundTruth) {
+				sumSq := 0.0
+				for i, dp := range ds.Data {
+					d := dp.AvgTTFTTime - truth.Clean[i].AvgTTFTTime
+					sumSq += d * d
+					if dp.AvgITLTime != truth.Clean[i].AvgITLTime {
+						t.Errorf("point %d: ITL noisy without noise", i)
+					}
+				}
+				if std := math.Sqrt(sumSq / float64(ds.Size())); std < 0.3 || std > 3 {
+					t.Errorf("TTFT noise std = %v, want about 1", std)
+				}
+			},
+		},
+		{
+			name:        "unreachable number of points",
+			modify:      func(cfg *Config) { cfg.RequestRate = Range{Min: 6, Max: 10} },
+			expectError: true,
+		},
+		{
+			name:        "invalid range",
+			modify:      func(cfg *Config) { cfg.InputTokens = Range{Min: 100, Max: 10} },
+			expectError: true,
+		},
+		{
+			name:        "unknown noise kind",
+			modify:      func(cfg *Config) { cfg.ITLNoise.Kind = "poisson" },
+			expectError: true,
+		},
+		{
+			name:        "unknown model",
+			modify:      func(cfg *Config) { cfg.Model = "median" },
+			expectError: true,
+		},
+		{
+			name:        "negative dispersion",
+			modify:      func(cfg *Config) { cfg.TokensCV = -0.5 },
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, truth, err := newTestGenerator(tt.modify).Generate()
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, ds, truth)
+			}
+		})
+	}
+}
+
+func TestGenerator_Seeded(t *testing.T) {
+	gen := func(seed int64) *core.DataSet {
+		ds, _, err := newTestGenerator(func(cfg *Config) { cfg.Seed = seed }).Generate()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return ds
+	}
+	a, b, c := gen(1), gen(1), gen(2)
+	for i := range a.Data {
+		if a.Data[i].RequestRate != b.Data[i].RequestRate || a.Data[i].AvgTTFTTime != b.Data[i].AvgTTFTTime {
+			t.Fatalf("point %d differs with the same seed", i)
+		}
+	}
+	if a.Data[0].RequestRate == c.Data[0].RequestRate {
+		t.Error("different seeds should give different data")
+	}
+}
+
+// token counts dispersed around their averages raise the latencies generated by the dispersion model,
+// for a model convex in token counts
+func TestGenerator_Dispersion(t *testing.T) {
+	convexModel := func(x *config.InputVars, params *config.ModelParams) (*config.OutputVars, error) {
+		return &config.OutputVars{
+			AvgTTFTTime: params.Alpha + params.Beta*x.InputTokens*x.InputTokens/100,
+			AvgITLTime:  params.Alpha + params.Gamma*x.OutputTokens,
+		}, nil
+	}
+	gen := func(model config.ModelKind) *core.DataSet {
+		cfg := DefaultConfig()
+		cfg.Seed, cfg.NumPoints, cfg.TokensCV, cfg.Model = 3, 10, 0.5, model
+		cfg.TTFTNoise.Level, cfg.ITLNoise.Level = 0, 0
+		ds, _, err := (&Generator{Config: cfg, Model: convexModel}).Generate()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return ds
+	}
+	mean, dispersion := gen(config.ModelMean), gen(config.ModelDispersion)
+	for i, dp := range dispersion.Data {
+		if math.Abs(dp.InputTokensStdDev-0.5*dp.InputTokens) > 1e-9 || math.Abs(dp.OutputTokensStdDev-0.5*dp.OutputTokens) > 1e-9 {
+			t.Errorf("point %d: unexpected standard deviations of token counts %+v", i, dp)
+		}
+		if dp.InputTokens != mean.Data[i].InputTokens || dp.AvgTTFTTime <= mean.Data[i].AvgTTFTTime {
+			t.Errorf("point %d: TTFT %v of the dispersion model not above %v of the mean model", i, dp.AvgTTFTTime, mean.Data[i].AvgTTFTTime)
+		}
+	}
+}
+
+// a seed generates the data sets of earlier versions: token counts then rate per attempt,
+// TTFT then ITL noise per data point, and no draws for single candidates or without noise
+func TestGenerator_DrawOrder(t *testing.T) {
+	for _, level := range []float64{0, 0.05} {
+		ds, _, err := newTestGenerator(func(cfg *Config) {
+			cfg.Seed = 7
+			cfg.TTFTNoise.Level, cfg.ITLNoise.Level = level, level
+		}).Generate()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rng := rand.New(rand.NewSource(7))
+		uniform := func(min, max float64) float64 { return min + rng.Float64()*(max-min) }
+		for i := 0; i < ds.Size(); {
+			in, out := uniform(DefaultMinTokens, DefaultMaxTokens), uniform(DefaultMinTokens, DefaultMaxTokens)
+			rate := uniform(DefaultMinRPS, DefaultMaxRPS)
+			if rate > 5 {
+				continue
+			}
+			ttft := DefaultAlpha + DefaultBeta*in
+			if level > 0 {
+				ttft = math.Max(ttft*(1+level*rng.NormFloat64()), ttft*NoiseFloorFraction)
+				rng.NormFloat64()
+			}
+			if dp := ds.Data[i]; dp.RequestRate != rate || dp.InputTokens != in || dp.OutputTokens != out || dp.AvgTTFTTime != ttft {
+				t.Fatalf("noise %v, point %d: got %+v, want rate %v, tokens %v, %v, TTFT %v", level, i, dp, rate, in, out, ttft)
+			}
+			i++
+		}
+	}
+}
+
+// parameters are recovered from noise-free data across seeds
+func TestGenerator_ParameterRecovery(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			ds, truth, err := newTestGenerator(func(cfg *Config) {
+				cfg.Seed = seed
+				cfg.TTFTNoise.Kind = NoiseNone
+				cfg.ITLNoise.Kind = NoiseNone
+			}).Generate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			optimizer := core.NewOptimizer(&config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.0001})
+			result, err := optimizer.Optimize(ds, mockModel)
+			if err != nil {
+				t.Fatalf("Optimize() failed: %v", err)
+			}
+			relErr := truth.RelativeErrors(result.OptimizedParms)
+			if relErr.Alpha > 0.01 || relErr.Beta > 0.01 || relErr.Gamma > 0.01 {
+				t.Errorf("relative errors %+v exceed 1%%", *relErr)
+			}
+		})
+	}
+}
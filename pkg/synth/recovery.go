@@ -0,0 +1,207 @@
+package synth
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+	"github.com/llm-inferno/model-trainer/pkg/core"
+)
+
+// configuration of the optimizer in a recovery benchmark
+type OptimizerConfig struct {
+	Name           string              `json:"name"`           // name of the configuration in reports
+	InitParms      *config.ModelParams `json:"initParms"`      // initial values of parameters
+	DisableScaling bool                `json:"disableScaling"` // do not scale parameters by their initial values
+	MaxIterations  int                 `json:"maxIterations"`  // maximum number of iterations (default if zero)
+	Model          config.ModelKind    `json:"model"`          // model fitted: mean (default), or dispersion of token counts
+
+	// statistic of the latency distributions to fit: average only, as the generator has no
+	// latency distributions, hence synthetic data sets no percentiles
+	Statistic config.Statistic `json:"statistic"`
+}
+
+// specification of a parameter-recovery benchmark: every optimizer configuration fits
+// synthetic data sets of every size and noise level, generated with several seeds
+type RecoverySpec struct {
+	Base        *Config           `json:"base"`        // base configuration of the generator
+	NoiseLevels []float64         `json:"noiseLevels"` // multiplicative noise levels (fractions) of TTFT and ITL
+	NumPoints   []int             `json:"numPoints"`   // data set sizes
+	Optimizers  []OptimizerConfig `json:"optimizers"`  // optimizer configurations
+	NumSeeds    int               `json:"numSeeds"`    // number of seeds per combination
+	FirstSeed   int64             `json:"firstSeed"`   // first seed, incremented per run
+
+	// maximum relative error of any parameter for a fit to count as a success
+	MaxRelError float64 `json:"maxRelError"`
+}
+
+// aggregate results of the runs of one combination of a recovery benchmark
+type RecoveryResult struct {
+	Optimizer   string              `json:"optimizer"`   // name of the optimizer configuration
+	NoiseLevel  float64             `json:"noiseLevel"`  // noise level (fraction)
+	NumPoints   int                 `json:"numPoints"`   // data set size
+	NumRuns     int                 `json:"numRuns"`     // number of runs
+	NumFailures int                 `json:"numFailures"` // runs with an error, or a parameter beyond the maximum relative error
+	FailureRate float64             `json:"failureRate"` // fraction of failed runs
+	MedianError *config.ModelParams `json:"medianError"` // median relative error per parameter (over runs without error)
+	MeanError   *config.ModelParams `json:"meanError"`   // mean relative error per parameter (over runs without error)
+	AvgRuntime  float64             `json:"avgRuntime"`  // average runtime of a fit (msec)
+}
+
+// get a recovery specification with default values
+func DefaultRecoverySpec() *RecoverySpec {
+	base := DefaultConfig()
+	initParms := &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}
+	return &RecoverySpec{
+		Base:        base,
+		NoiseLevels: []float64{0, DefaultNoiseFraction, 2.5 * DefaultNoiseFraction},
+		NumPoints:   []int{DefaultNumPoints / 2, DefaultNumPoints},
+		Optimizers: []OptimizerConfig{
+			{Name: "scaled", InitParms: initParms},
+			{Name: "unscaled", InitParms: initParms, DisableScaling: true},
+		},
+		NumSeeds:    DefaultNumSeeds,
+		FirstSeed:   1,
+		MaxRelError: DefaultMaxRelError,
+	}
+}
+
+// harness running a parameter-recovery benchmark with a model function
+type RecoveryHarness struct {
+	Spec  *RecoverySpec
+	Model core.ModelFunction
+}
+
+func NewRecoveryHarness(spec *RecoverySpec) *RecoveryHarness {
+	return &RecoveryHarness{
+		Spec:  spec,
+		Model: core.Model,
+	}
+}
+
+// run the benchmark, returning results per optimizer configuration, noise level and data set size
+func (h *RecoveryHarness) Run() ([]*RecoveryResult, error) {
+	spec := h.Spec
+	if spec.Base == nil || len(spec.Optimizers) == 0 || len(spec.NoiseLevels) == 0 ||
+		len(spec.NumPoints) == 0 || spec.NumSeeds <= 0 {
+		return nil, fmt.Errorf("incomplete recovery specification")
+	}
+	results := []*RecoveryResult{}
+	for _, opt := range spec.Optimizers {
+		if opt.InitParms == nil {
+			return nil, fmt.Errorf("optimizer %s: missing initial parameters", opt.Name)
+		}
+		if !opt.Model.IsValid() {
+			return nil, fmt.Errorf("optimizer %s: unknown model %q", opt.Name, opt.Model)
+		}
+		if _, isPercentile := opt.Statistic.Quantile(); isPercentile || !opt.Statistic.IsValid() {
+			return nil, fmt.Errorf("optimizer %s: statistic %q not available, synthetic data sets have averages only",
+				opt.Name, opt.Statistic)
+		}
+		for _, noise := range spec.NoiseLevels {
+			for _, numPoints := range spec.NumPoints {
+				result, err := h.runCombination(opt, noise, numPoints)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// run all seeds of a combination; the same seeds (hence data sets) are used for all optimizers
+func (h *RecoveryHarness) runCombination(opt OptimizerConfig, noise float64, numPoints int) (*RecoveryResult, error) {
+	spec := h.Spec
+	result := &RecoveryResult{
+		Optimizer:  opt.Name,
+		NoiseLevel: noise,
+		NumPoints:  numPoints,
+	}
+	errors := [3][]float64{}
+	var runtime time.Duration
+	for k := 0; k < spec.NumSeeds; k++ {
+		cfg := *spec.Base
+		cfg.Design = DesignRandom
+		cfg.NumPoints = numPoints
+		cfg.Seed = spec.FirstSeed + int64(k)
+		cfg.TTFTNoise = NoiseModel{Kind: NoiseMultiplicative, Level: noise}
+		cfg.ITLNoise = NoiseModel{Kind: NoiseMultiplicative, Level: noise}
+		dataSet, truth, err := (&Generator{Config: &cfg, Model: h.Model}).Generate()
+		if err != nil {
+			return nil, fmt.Errorf("generation failed (seed %d): %w", cfg.Seed, err)
+		}
+
+		optimizer := core.NewOptimizer(opt.InitParms)
+		optimizer.DisableScaling = opt.DisableScaling
+		optimizer.MaxIterations = opt.MaxIterations
+		optimizer.Statistic = opt.Statistic
+		optimizer.Model = opt.Model
+		optimizer.Quiet = true
+
+		start := time.Now()
+		fit, err := optimizer.Optimize(dataSet, h.Model)
+		runtime += time.Since(start)
+		result.NumRuns++
+		if err != nil {
+			result.NumFailures++
+			continue
+		}
+		relErr := truth.RelativeErrors(fit.OptimizedParms)
+		errors[0] = append(errors[0], relErr.Alpha)
+		errors[1] = append(errors[1], relErr.Beta)
+		errors[2] = append(errors[2], relErr.Gamma)
+		if math.Max(relErr.Alpha, math.Max(relErr.Beta, relErr.Gamma)) > spec.MaxRelError ||
+			math.IsNaN(relErr.Alpha+relErr.Beta+relErr.Gamma) {
+			result.NumFailures++
+		}
+	}
+	result.FailureRate = float64(result.NumFailures) / float64(result.NumRuns)
+	result.AvgRuntime = float64(runtime.Microseconds()) / 1000 / float64(result.NumRuns)
+	result.MedianError = &config.ModelParams{Alpha: median(errors[0]), Beta: median(errors[1]), Gamma: median(errors[2])}
+	result.MeanError = &config.ModelParams{Alpha: mean(errors[0]), Beta: mean(errors[1]), Gamma: mean(errors[2])}
+	return result, nil
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// pretty print a table of recovery results
+func RecoveryPrettyPrint(results []*RecoveryResult) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, " optimizer \t noise \t points \t runs \t failRate \t errAlpha \t errBeta \t errGamma \t time(msec)\n")
+	fmt.Fprintln(&b)
+	for _, r := range results {
+		fmt.Fprintf(&b, " %-9s \t %5.3f \t %6d \t %4d \t %8.2f \t %8.4f \t %7.4f \t %8.4f \t %10.2f\n",
+			r.Optimizer, r.NoiseLevel, r.NumPoints, r.NumRuns, r.FailureRate,
+			r.MedianError.Alpha, r.MedianError.Beta, r.MedianError.Gamma, r.AvgRuntime)
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, " (median relative errors of parameters)\n")
+	return b.String()
+}
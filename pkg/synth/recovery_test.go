@@ -0,0 +1,145 @@
+package synth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llm-inferno/model-trainer/pkg/config"
+)
+
+func newTestHarness(modify func(spec *RecoverySpec)) *RecoveryHarness {
+	spec := DefaultRecoverySpec()
+	spec.Base.RequestRate = Range{Min: DefaultMinRPS, Max: 5}
+	spec.NumSeeds = 2
+	modify(spec)
+	return &RecoveryHarness{Spec: spec, Model: mockModel}
+}
+
+func TestRecoveryHarness_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(spec *RecoverySpec)
+		expectError bool
+		validateFn  func(t *testing.T, results []*RecoveryResult)
+	}{
+		{
+			name: "one result per combination",
+			modify: func(spec *RecoverySpec) {
+				spec.NoiseLevels = []float64{0, 0.05}
+				spec.NumPoints = []int{10, 20}
+			},
+			validateFn: func(t *testing.T, results []*RecoveryResult) {
+				if len(results) != 2*2*2 {
+					t.Fatalf("got %d results, want 8", len(results))
+				}
+				for _, r := range results {
+					if r.NumRuns != 2 || r.MedianError == nil || r.MeanError == nil {
+						t.Errorf("incomplete result %+v", *r)
+					}
+				}
+			},
+		},
+		{
+			name: "noise-free data is recovered",
+			modify: func(spec *RecoverySpec) {
+				spec.NoiseLevels = []float64{0}
+				spec.NumPoints = []int{20}
+				spec.Optimizers = spec.Optimizers[:1]
+			},
+			validateFn: func(t *testing.T, results []*RecoveryResult) {
+				r := results[0]
+				if r.FailureRate != 0 || r.MedianError.Alpha > 0.01 || r.MedianError.Beta > 0.01 || r.MedianError.Gamma > 0.01 {
+					t.Errorf("unexpected recovery %+v, errors %+v", *r, *r.MedianError)
+				}
+			},
+		},
+		{
+			name: "too few iterations fail",
+			modify: func(spec *RecoverySpec) {
+				spec.NoiseLevels = []float64{0}
+				spec.NumPoints = []int{20}
+				spec.Optimizers = []OptimizerConfig{{Name: "short", MaxIterations: 1,
+					InitParms: &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}}}
+			},
+			validateFn: func(t *testing.T, results []*RecoveryResult) {
+				if results[0].FailureRate != 1 {
+					t.Errorf("failure rate = %v, want 1", results[0].FailureRate)
+				}
+			},
+		},
+		{
+			name: "dispersion model",
+			modify: func(spec *RecoverySpec) {
+				spec.Base.TokensCV, spec.Base.Model = 0.5, config.ModelDispersion
+				spec.NoiseLevels = []float64{0}
+				spec.NumPoints = []int{20}
+				spec.Optimizers = []OptimizerConfig{{Name: "dispersion", Model: config.ModelDispersion,
+					InitParms: &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}}}
+			},
+			validateFn: func(t *testing.T, results []*RecoveryResult) {
+				r := results[0]
+				if r.FailureRate != 0 || r.MedianError.Alpha > 0.01 || r.MedianError.Beta > 0.01 || r.MedianError.Gamma > 0.01 {
+					t.Errorf("unexpected recovery %+v, errors %+v", *r, *r.MedianError)
+				}
+			},
+		},
+		{
+			name: "unknown model",
+			modify: func(spec *RecoverySpec) {
+				spec.Optimizers = []OptimizerConfig{{Name: "median", Model: "median",
+					InitParms: &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}}}
+			},
+			expectError: true,
+		},
+		{
+			name:        "missing initial parameters",
+			modify:      func(spec *RecoverySpec) { spec.Optimizers = []OptimizerConfig{{Name: "none"}} },
+			expectError: true,
+		},
+		{
+			name: "percentile statistic",
+			modify: func(spec *RecoverySpec) {
+				spec.Optimizers = []OptimizerConfig{{Name: "p90", Statistic: config.StatisticP90,
+					InitParms: &config.ModelParams{Alpha: 1.0, Beta: 0.01, Gamma: 0.00001}}}
+			},
+			expectError: true,
+		},
+		{
+			name:        "no seeds",
+			modify:      func(spec *RecoverySpec) { spec.NumSeeds = 0 },
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := newTestHarness(tt.modify).Run()
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validateFn != nil {
+				tt.validateFn(t, results)
+			}
+		})
+	}
+}
+
+func TestRecoveryPrettyPrint(t *testing.T) {
+	results, err := newTestHarness(func(spec *RecoverySpec) {
+		spec.NoiseLevels = []float64{0}
+		spec.NumPoints = []int{10}
+	}).Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := RecoveryPrettyPrint(results)
+	if !strings.Contains(out, "scaled") || !strings.Contains(out, "unscaled") {
+		t.Errorf("missing optimizers in table:\n%s", out)
+	}
+}
@@ -3,7 +3,7 @@
 
 For each experiment under experiments/<expN>/data, runs the joint
 Nelder-Mead fit by invoking demos/guidellm-multiple with all sweep files
-concatenated by the FileNameSeparator '$'. Parses the optimizer's
+as separate arguments. Parses the optimizer's
 predicted/measured table from stdout and overlays both models in a
 two-panel scatter, saved as PDF (vector text).
 
@@ -29,7 +29,6 @@ import numpy as np
 
 REPO_ROOT = Path(__file__).resolve().parents[1]
 DEMO_PKG = "./demos/guidellm-multiple"
-FILE_SEP = "$"
 
 ROW_RE = re.compile(
     r"^\s*([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+"
@@ -57,10 +56,9 @@ def joint_fit(data_dir: Path, label: str, color: str, marker: str) -> FitResult:
     files = sorted(data_dir.glob("sweep-i*-o*.*"))
     if not files:
         raise FileNotFoundError(f"no sweep files under {data_dir}")
-    arg = FILE_SEP.join(str(f) for f in files)
 
     proc = subprocess.run(
-        ["go", "run", DEMO_PKG, arg],
+        ["go", "run", DEMO_PKG, *(str(f) for f in files)],
         cwd=REPO_ROOT,
         check=True,
         capture_output=True,